@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/config"
+)
+
+// configCmd groups the commands inspecting the configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect mohua's configuration",
+}
+
+// configShowCmd prints every setting after flags, environment variables, the view and the
+// configuration file have been applied
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the resolved configuration and where each setting comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rejectHTMLOutput(cmd); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printConfigurationJSON(os.Stdout)
+		}
+		printConfiguration(os.Stdout)
+		return nil
+	},
+}
+
+// secretSettings hold credentials, such as webhook URLs, that config show masks since its
+// output is meant to be shared
+var secretSettings = map[string]bool{
+	"notify-slack":   true,
+	"notify-teams":   true,
+	"notify-webhook": true,
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// printConfiguration writes the resolved settings as a table
+func printConfiguration(w io.Writer) {
+	path := configFile.Path
+	if path == "" {
+		path = "(none)"
+	}
+	fmt.Fprintf(w, "Config file: %s\n", path)
+	if viewName != "" {
+		fmt.Fprintf(w, "View: %s\n", viewName)
+	}
+	if views := configFile.ViewNames(); len(views) > 0 {
+		fmt.Fprintf(w, "Available views: %s\n", strings.Join(views, ", "))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%-20s %-40s %s\n", "Setting", "Value", "Source")
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+	for _, setting := range redactedSettings() {
+		fmt.Fprintf(w, "%-20s %-40s %s\n", setting.Name, setting.Value, setting.Source)
+	}
+
+	if len(configFile.Pricing) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%-20s %s\n", "Instance Type", "Hourly Price")
+		fmt.Fprintln(w, strings.Repeat("-", 70))
+		instanceTypes := make([]string, 0, len(configFile.Pricing))
+		for instanceType := range configFile.Pricing {
+			instanceTypes = append(instanceTypes, instanceType)
+		}
+		sort.Strings(instanceTypes)
+		for _, instanceType := range instanceTypes {
+			fmt.Fprintf(w, "%-20s $%.4f\n", instanceType, configFile.Pricing[instanceType])
+		}
+	}
+}
+
+// printConfigurationJSON writes the resolved settings as a single JSON document
+func printConfigurationJSON(w io.Writer) error {
+	resolved := struct {
+		ConfigFile string             `json:"configFile,omitempty"`
+		View       string             `json:"view,omitempty"`
+		Views      []string           `json:"views,omitempty"`
+		Settings   []config.Setting   `json:"settings"`
+		Pricing    map[string]float64 `json:"pricing,omitempty"`
+	}{
+		ConfigFile: configFile.Path,
+		View:       viewName,
+		Views:      configFile.ViewNames(),
+		Settings:   redactedSettings(),
+		Pricing:    configFile.Pricing,
+	}
+	if len(resolved.Views) == 0 {
+		resolved.Views = nil
+	}
+
+	data, err := json.MarshalIndent(resolved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// redactedSettings returns configSettings with the values of secretSettings masked, keeping
+// their source
+func redactedSettings() []config.Setting {
+	settings := make([]config.Setting, len(configSettings))
+	for i, setting := range configSettings {
+		if secretSettings[setting.Name] && setting.Source != config.SourceDefault {
+			setting.Value = "***"
+		}
+		settings[i] = setting
+	}
+	return settings
+}
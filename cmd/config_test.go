@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mohua/internal/config"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testConfigFile = `
+region: eu-west-1
+group-by: type
+columns: [name, status, hourly-cost]
+pricing:
+  ml.g5.xlarge: 2.5
+views:
+  gpu-waste:
+    instance-type: "ml.g*"
+    older-than: 3d
+`
+
+func writeTestConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0o600))
+	return path
+}
+
+func TestConfigShow_Unit(t *testing.T) {
+	path := writeTestConfig(t)
+	t.Setenv("MOHUA_GROUP_BY", "region")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"config", "show", "-j", "--config", path, "--view", "gpu-waste", "--region", "us-west-2"}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+
+	var resolved struct {
+		ConfigFile string             `json:"configFile"`
+		View       string             `json:"view"`
+		Views      []string           `json:"views"`
+		Settings   []config.Setting   `json:"settings"`
+		Pricing    map[string]float64 `json:"pricing"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &resolved))
+	assert.Equal(t, path, resolved.ConfigFile)
+	assert.Equal(t, "gpu-waste", resolved.View)
+	assert.Equal(t, []string{"gpu-waste"}, resolved.Views)
+	assert.Equal(t, map[string]float64{"ml.g5.xlarge": 2.5}, resolved.Pricing)
+
+	// Flag > env > view > config
+	assert.Contains(t, resolved.Settings, config.Setting{Name: "region", Value: "us-west-2", Source: config.SourceFlag})
+	assert.Contains(t, resolved.Settings, config.Setting{Name: "group-by", Value: "region", Source: config.SourceEnv})
+	assert.Contains(t, resolved.Settings, config.Setting{Name: "instance-type", Value: "ml.g*", Source: config.SourceView})
+	assert.Contains(t, resolved.Settings, config.Setting{Name: "columns", Value: "[name,status,hourly-cost]", Source: config.SourceConfig})
+	assert.Contains(t, resolved.Settings, config.Setting{Name: "time-format", Value: "relative", Source: config.SourceDefault})
+
+	// The table form lists the same settings
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"config", "show", "--config", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Config file: "+path)
+	assert.Contains(t, out, "Available views: gpu-waste")
+	assert.Regexp(t, `region\s+eu-west-1\s+config`, out)
+	assert.Regexp(t, `ml\.g5\.xlarge\s+\$2\.5000`, out)
+}
+
+func TestConfigShowRedactsSecrets_Unit(t *testing.T) {
+	t.Setenv("MOHUA_NOTIFY_TEAMS", "https://example.webhook.office.com/secret-token")
+	args := []string{"config", "show", "--config", writeTestConfig(t), "--notify-slack", "https://hooks.slack.com/services/T0/B0/secret"}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "table",
+			args: args,
+			want: []string{"notify-slack         ***                                      flag\n", "notify-teams         ***                                      env\n", "notify-webhook       []                                       default\n"},
+		},
+		{
+			name: "json",
+			args: append(args, "-j"),
+			want: []string{"\"name\": \"notify-slack\",\n      \"value\": \"***\",\n      \"source\": \"flag\"", "\"name\": \"notify-teams\",\n      \"value\": \"***\",\n      \"source\": \"env\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() {
+				err = mockExecute(t, tt.args, new(MockSageMakerClient))
+			})
+			assert.NoError(t, err)
+			assert.NotContains(t, out, "secret")
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestExecuteWithConfig_Unit(t *testing.T) {
+	path := writeTestConfig(t)
+	t.Setenv(config.EnvConfig, path)
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("eu-west-1")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	// The view's filters reach the client
+	viewFilter := mock.MatchedBy(func(filter sagemaker.Filter) bool {
+		return filter.InstanceType == "ml.g*" && !filter.CreatedBefore.IsZero()
+	})
+	mockClient.On("ListEndpoints", mock.Anything, viewFilter).Return([]sagemaker.ResourceInfo{{
+		Name:          "gpu-endpoint",
+		Status:        "InService",
+		InstanceType:  "ml.g5.xlarge",
+		InstanceCount: 1,
+		CreationTime:  time.Now().Add(-96 * time.Hour),
+	}}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, viewFilter).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, viewFilter).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"--view", "gpu-waste", "-j"}, mockClient)
+	})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	var envelope struct {
+		Resources []struct {
+			HourlyCost float64 `json:"estimatedHourlyCost"`
+		} `json:"resources"`
+		Summary struct {
+			GroupBy string `json:"groupBy"`
+		} `json:"summary"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope))
+	// The configured price and grouping are used
+	assert.Equal(t, 2.5, envelope.Resources[0].HourlyCost)
+	assert.Equal(t, "type", envelope.Summary.GroupBy)
+
+	// The configured columns apply to the table
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"--view", "gpu-waste", "--color", "never"}, mockClient)
+	})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Name\s+Status\s+Hourly Cost`, out)
+	assert.Contains(t, out, "$2.50")
+}
+
+func TestExecuteWithConfigErrors_Unit(t *testing.T) {
+	path := writeTestConfig(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "missing config file", args: []string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}, want: "failed to read config file"},
+		{name: "unknown view", args: []string{"--config", path, "--view", "cpu"}, want: `unknown view "cpu"`},
+		{name: "invalid column", args: []string{"--config", path, "--columns", "name,cost"}, want: `invalid column "cost"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
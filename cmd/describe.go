@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+)
+
+// describeCmd prints everything known about a single resource
+var describeCmd = &cobra.Command{
+	Use:   "describe <endpoint|notebook|studio> <name>",
+	Short: "Show the configuration, tags, cost and timeline of a single resource",
+	Long: `Describe a single resource with its Describe API and print its configuration, variants,
+network settings, KMS key, lifecycle configuration, execution role, tags, estimated cost and
+the timeline of its status changes where SageMaker reports them.
+
+Studio apps are named like in the list, <user profile>/<app type>, followed by /<app name>
+when the user profile has several apps of that type; --domain narrows the search. Their role,
+KMS key and network settings are the defaults of their domain.
+
+--output selects table (default), json or yaml.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rejectHTMLOutput(cmd); err != nil {
+			return err
+		}
+		client, _, err := newRunClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := runContext(timeout)
+		defer cancel()
+		detail, err := describeResource(ctx, client, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		newPrinter().PrintDetail(detail)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+}
+
+// describeResource describes the resource of the given kind (endpoint, notebook or studio)
+// and lists its tags; failing to list the tags is reported as a warning
+func describeResource(ctx context.Context, client sagemaker.Client, kind, name string) (display.Detail, error) {
+	var (
+		resourceType = "Studio"
+		description  sagemaker.Description
+		err          error
+	)
+	switch strings.ToLower(kind) {
+	case "endpoint":
+		resourceType = "Endpoint"
+		description, err = client.DescribeEndpoint(ctx, name)
+	case "notebook":
+		resourceType = "Notebook"
+		description, err = client.DescribeNotebook(ctx, name)
+	case "studio":
+		var app sagemaker.ResourceInfo
+		if app, err = findStudioApp(ctx, client, name); err != nil {
+			return display.Detail{}, err
+		}
+		description, err = client.DescribeStudioApp(ctx, app)
+		name = fmt.Sprintf("%s/%s", app.UserProfile, app.AppType)
+	default:
+		return display.Detail{}, fmt.Errorf("unknown resource type %q: must be endpoint, notebook or studio", kind)
+	}
+	if err != nil {
+		return display.Detail{}, fmt.Errorf("failed to describe %s %s: %w", strings.ToLower(kind), name, err)
+	}
+
+	if description.Arn != "" {
+		tags, err := client.ListTags(ctx, description.Arn)
+		if err != nil {
+			description.Warnings = append(description.Warnings, fmt.Sprintf("failed to list tags: %v", err))
+		} else {
+			description.Tags = tags
+		}
+	}
+	return toDetail(resourceType, name, description, client.GetRegion()), nil
+}
+
+// findStudioApp finds the Studio app named <user profile>/<app type>[/<app name>] among the
+// apps in every status, preferring apps that are not deleted
+func findStudioApp(ctx context.Context, client sagemaker.Client, name string) (sagemaker.ResourceInfo, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return sagemaker.ResourceInfo{}, fmt.Errorf("invalid Studio app %q: expected <user profile>/<app type>[/<app name>], e.g. alice/JupyterLab", name)
+	}
+
+	// The user profile is matched here, on the field the list names apps after, rather than
+	// with the ListApps filter
+	apps, err := client.ListStudioApps(ctx, sagemaker.Filter{AllStatuses: true, DomainID: domainID})
+	if err != nil {
+		return sagemaker.ResourceInfo{}, fmt.Errorf("failed to list studio apps: %w", err)
+	}
+	var live, deleted []sagemaker.ResourceInfo
+	for _, app := range apps {
+		if app.UserProfile != parts[0] || !strings.EqualFold(app.AppType, parts[1]) || (len(parts) == 3 && app.Name != parts[2]) {
+			continue
+		}
+		if app.Status == "Deleted" {
+			deleted = append(deleted, app)
+		} else {
+			live = append(live, app)
+		}
+	}
+	matches := live
+	if len(matches) == 0 {
+		matches = deleted
+	}
+
+	switch len(matches) {
+	case 0:
+		return sagemaker.ResourceInfo{}, fmt.Errorf("no Studio app %s found", name)
+	case 1:
+		return matches[0], nil
+	}
+	var candidates []string
+	for _, app := range matches {
+		candidate := fmt.Sprintf("%s/%s/%s", app.UserProfile, app.AppType, app.Name)
+		if app.SpaceName != "" {
+			candidate += " (space " + app.SpaceName + ")"
+		}
+		candidates = append(candidates, candidate)
+	}
+	return sagemaker.ResourceInfo{}, fmt.Errorf("%s matches %d Studio apps, add the app name or --domain: %s", name, len(matches), strings.Join(candidates, ", "))
+}
+
+// toDetail converts a SageMaker description into its display form, with the estimated cost of
+// every variant
+func toDetail(resourceType, name string, d sagemaker.Description, region string) display.Detail {
+	info := toDisplayResource(resourceType, name, d.ResourceInfo, region)
+	detail := display.Detail{
+		ResourceType:          resourceType,
+		Name:                  name,
+		Arn:                   d.Arn,
+		Region:                region,
+		Status:                d.Status,
+		FailureReason:         d.FailureReason,
+		InstanceType:          d.InstanceType,
+		InstanceCount:         d.InstanceCount,
+		VolumeSizeGB:          d.VolumeSize,
+		CreationTime:          info.CreationTime,
+		RunningSeconds:        info.RunningSeconds,
+		LastModifiedTime:      optionalTime(d.LastModifiedTime),
+		RoleArn:               d.RoleArn,
+		KmsKeyID:              d.KmsKeyID,
+		LifecycleConfig:       d.LifecycleConfig,
+		Network:               display.Network(d.Network),
+		EndpointConfigName:    d.EndpointConfigName,
+		DataCapture:           d.DataCapture,
+		URL:                   d.URL,
+		RootAccess:            d.RootAccess,
+		PlatformIdentifier:    d.PlatformIdentifier,
+		DefaultCodeRepository: d.DefaultCodeRepository,
+		UserProfile:           d.UserProfile,
+		SpaceName:             d.SpaceName,
+		DomainID:              d.DomainID,
+		Image:                 d.Image,
+		LastUserActivity:      optionalTime(d.LastUserActivity),
+		Tags:                  d.Tags,
+		Warnings:              d.Warnings,
+	}
+	if resourceType == "Studio" {
+		detail.AppName = d.Name
+		detail.AppType = d.AppType
+		detail.StudioType = d.StudioType
+	}
+	for _, event := range d.Timeline {
+		detail.Timeline = append(detail.Timeline, display.Event{Time: event.Time.UTC(), Description: event.Description})
+	}
+
+	hourly := info.HourlyCost
+	if len(d.Variants) > 0 {
+		// Variants may use different instance types; serverless ones are billed per request
+		hourly = 0
+		for _, v := range d.Variants {
+			variant := display.Variant{
+				Name:                 v.Name,
+				ModelName:            v.ModelName,
+				InstanceType:         v.InstanceType,
+				CurrentInstanceCount: v.CurrentInstanceCount,
+				DesiredInstanceCount: v.DesiredInstanceCount,
+				Weight:               v.Weight,
+				Serverless:           v.Serverless,
+			}
+			if v.Serverless == "" && v.CurrentInstanceCount > 0 {
+				variant.HourlyCost = prices.HourlyCost(v.InstanceType, v.CurrentInstanceCount)
+			}
+			hourly += variant.HourlyCost
+			detail.Variants = append(detail.Variants, variant)
+		}
+	}
+	detail.Cost = display.NewCost(d.Status, hourly, info.RunningSeconds)
+	return detail
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
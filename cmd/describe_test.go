@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v3"
+)
+
+// newDescribeClient describes an endpoint with two variants, a notebook and alice's Studio apps
+func newDescribeClient() *MockSageMakerClient {
+	created := time.Now().Add(-48 * time.Hour)
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("DescribeEndpoint", mock.Anything, "serving").Return(sagemaker.Description{
+		ResourceInfo:       sagemaker.ResourceInfo{Name: "serving", Arn: "arn:serving", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 3, CreationTime: created},
+		RoleArn:            "arn:aws:iam::123456789012:role/inference",
+		KmsKeyID:           "arn:aws:kms:us-west-2:123456789012:key/1",
+		EndpointConfigName: "serving-config",
+		Network:            sagemaker.Network{SubnetIDs: []string{"subnet-1", "subnet-2"}, SecurityGroupIDs: []string{"sg-1"}},
+		Variants: []sagemaker.Variant{
+			{Name: "blue", ModelName: "model-v1", InstanceType: "ml.m5.large", CurrentInstanceCount: 2, DesiredInstanceCount: 2, Weight: 0.9},
+			{Name: "green", ModelName: "model-v2", InstanceType: "ml.g5.xlarge", CurrentInstanceCount: 1, DesiredInstanceCount: 2, Weight: 0.1},
+			{Name: "batch", ModelName: "model-v1", CurrentInstanceCount: 0, Serverless: "2048 MB, max concurrency 5"},
+		},
+		Timeline: []sagemaker.Event{
+			{Time: created, Description: "Created"},
+			{Time: created.Add(time.Hour), Description: "Last modified, status InService"},
+		},
+		Warnings: []string{"failed to describe model model-v2: Could not find model"},
+	}, nil)
+	mockClient.On("DescribeNotebook", mock.Anything, "stopped").Return(sagemaker.Description{
+		ResourceInfo:    sagemaker.ResourceInfo{Name: "stopped", Arn: "arn:stopped", Status: "Stopped", InstanceType: "ml.t3.medium", InstanceCount: 1, VolumeSize: 20, CreationTime: created},
+		LifecycleConfig: "install-extensions",
+		Network:         sagemaker.Network{DirectInternetAccess: "Disabled"},
+	}, nil)
+	mockClient.On("DescribeNotebook", mock.Anything, "missing").Return(sagemaker.Description{}, errors.New("RecordNotFound"))
+	mockClient.On("ListTags", mock.Anything, "arn:serving").Return(map[string]string{"team": "ml"}, nil)
+	mockClient.On("ListTags", mock.Anything, "arn:stopped").Return(map[string]string(nil), errors.New("AccessDenied"))
+	mockClient.On("ListTags", mock.Anything, "arn:lab").Return(map[string]string{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, sagemaker.Filter{AllStatuses: true}).Return([]sagemaker.ResourceInfo{
+		{Name: "default", AppType: "JupyterServer", UserProfile: "alice", DomainID: "d-1", Status: "InService"},
+		{Name: "lab", AppType: "JupyterLab", UserProfile: "alice", SpaceName: "alice-space", DomainID: "d-1", Status: "InService"},
+		{Name: "old", AppType: "JupyterLab", UserProfile: "alice", DomainID: "d-1", Status: "Deleted"},
+		{Name: "other", AppType: "JupyterServer", UserProfile: "alice", DomainID: "d-1", Status: "InService"},
+	}, nil)
+	mockClient.On("DescribeStudioApp", mock.Anything, mock.MatchedBy(func(app sagemaker.ResourceInfo) bool { return app.Name == "lab" })).Return(sagemaker.Description{
+		ResourceInfo: sagemaker.ResourceInfo{Name: "lab", Arn: "arn:lab", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, CreationTime: created, UserProfile: "alice", AppType: "JupyterLab", SpaceName: "alice-space", StudioType: "New Studio (JupyterLab)", DomainID: "d-1"},
+		Network:      sagemaker.Network{VpcID: "vpc-1", AppNetworkAccess: "VpcOnly"},
+	}, nil)
+	return mockClient
+}
+
+func TestExecuteDescribe_Unit(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+		wantErr string
+	}{
+		{
+			name: "endpoint table",
+			args: []string{"describe", "endpoint", "serving"},
+			want: []string{
+				"Endpoint serving (us-west-2)\n",
+				"Status                 InService\n",
+				"Instance               ml.m5.large x 3\n",
+				"Role                   arn:aws:iam::123456789012:role/inference\n",
+				"KMS key                arn:aws:kms:us-west-2:123456789012:key/1\n",
+				"Endpoint config        serving-config\n",
+				"green                model-v2                       ml.g5.xlarge      1->2      0.10    $1.41\n",
+				"batch                model-v1                       serverless        0         0.00    $0.00\n",
+				"                     2048 MB, max concurrency 5\n",
+				"Subnets                subnet-1, subnet-2\n",
+				"Security groups        sg-1\n",
+				"team                   ml\n",
+				"Hourly                 $1.64\n",
+				"Created\n",
+				"Warnings\nfailed to describe model model-v2: Could not find model\n",
+			},
+		},
+		{
+			name:    "stopped notebook",
+			args:    []string{"describe", "Notebook", "stopped"},
+			want:    []string{"Volume                 20 GB\n", "Lifecycle config       install-extensions\n", "Direct internet access Disabled\n", "Not billed while Stopped\n", "failed to list tags: AccessDenied\n"},
+			notWant: []string{"Tags\n", "Variants\n"},
+		},
+		{
+			name: "Studio app",
+			args: []string{"describe", "studio", "alice/JupyterLab"},
+			want: []string{"Studio alice/JupyterLab (us-west-2)\n", "App                    lab (New Studio (JupyterLab))\n", "Space                  alice-space\n", "VPC                    vpc-1\n", "App network access     VpcOnly\n"},
+		},
+		{name: "ambiguous Studio app", args: []string{"describe", "studio", "alice/jupyterserver"}, wantErr: "alice/jupyterserver matches 2 Studio apps, add the app name or --domain: alice/JupyterServer/default, alice/JupyterServer/other"},
+		{name: "unknown Studio app", args: []string{"describe", "studio", "bob/JupyterLab"}, wantErr: "no Studio app bob/JupyterLab found"},
+		{name: "invalid Studio app name", args: []string{"describe", "studio", "alice"}, wantErr: `invalid Studio app "alice"`},
+		{name: "unknown type", args: []string{"describe", "job", "train"}, wantErr: `unknown resource type "job": must be endpoint, notebook or studio`},
+		{name: "describe failure", args: []string{"describe", "notebook", "missing"}, wantErr: "failed to describe notebook missing: RecordNotFound"},
+		{name: "html output", args: []string{"describe", "-o", "html", "endpoint", "serving"}, wantErr: "mohua describe does not support --output html"},
+		{name: "yaml output elsewhere", args: []string{"-o", "yaml"}, wantErr: "mohua does not support --output yaml"},
+		{name: "json and yaml", args: []string{"describe", "--json", "-o", "yaml", "endpoint", "serving"}, wantErr: "--json and --output yaml cannot be used together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() {
+				err = mockExecute(t, append(tt.args, "--color", "never"), newDescribeClient())
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, out, notWant)
+			}
+		})
+	}
+}
+
+func TestExecuteDescribeStructured_Unit(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() {
+				err = mockExecute(t, []string{"describe", "endpoint", "serving", "-o", format}, newDescribeClient())
+			})
+			assert.NoError(t, err)
+
+			var detail display.Detail
+			if format == "json" {
+				assert.NoError(t, json.Unmarshal([]byte(out), &detail))
+			} else {
+				assert.NoError(t, yaml.Unmarshal([]byte(out), &detail))
+			}
+			assert.Equal(t, "Endpoint", detail.ResourceType)
+			assert.Equal(t, "serving", detail.Name)
+			assert.Equal(t, "us-west-2", detail.Region)
+			assert.Equal(t, map[string]string{"team": "ml"}, detail.Tags)
+			assert.Equal(t, []string{"subnet-1", "subnet-2"}, detail.Network.SubnetIDs)
+			assert.Len(t, detail.Variants, 3)
+			assert.Len(t, detail.Timeline, 2)
+			assert.True(t, detail.Cost.Billed)
+			assert.InDelta(t, 1.638, detail.Cost.Hourly, 0.001)
+			assert.InDelta(t, 1.638*24, detail.Cost.Daily, 0.001)
+			assert.InDelta(t, 1.638*48, detail.Cost.SinceCreated, 0.01)
+		})
+	}
+}
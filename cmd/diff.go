@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/display"
+	"mohua/internal/history"
+)
+
+// Special inventory arguments of diff
+const (
+	inventoryLive          = "live"
+	inventoryHistoryPrefix = "history:"
+)
+
+// diffCmd compares two inventories and fails when they differ, so it can gate CI
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> [<new>|live]",
+	Short: "Show which resources were added, removed or changed between two inventories",
+	Long: `Compare two inventories and show which resources were added, removed or changed,
+with the change in estimated cost.
+
+An inventory is a file written by "mohua --json" or "mohua history show --json",
+history:<time> for the saved snapshot at that time, or live for the current resources,
+listed with the usual filter flags. The new inventory defaults to live.
+
+Resources are matched by type, name and region; a change is a different status, instance
+type or instance count. The exit code is 2 when anything changed.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateDisplayFlags(); err != nil {
+			return err
+		}
+		if err := rejectHTMLOutput(cmd); err != nil {
+			return err
+		}
+		newArg := inventoryLive
+		if len(args) == 2 {
+			newArg = args[1]
+		}
+
+		oldLabel, oldResources, err := loadInventory(args[0])
+		if err != nil {
+			return err
+		}
+		newLabel, newResources, err := loadInventory(newArg)
+		if err != nil {
+			return err
+		}
+
+		d := display.Compare(oldResources, newResources)
+		d.Old, d.New = oldLabel, newLabel
+		newPrinter().PrintDiff(d)
+		if d.HasChanges() {
+			return &ExitError{
+				Code: ExitCodeChanges,
+				Err:  fmt.Errorf("inventories differ: %d added, %d removed, %d changed", d.Added, d.Removed, d.Changed),
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
+
+// loadInventory returns a description of the inventory named by arg and its resources
+func loadInventory(arg string) (string, []display.ResourceInfo, error) {
+	switch {
+	case arg == inventoryLive:
+		return loadLiveInventory()
+	case strings.HasPrefix(arg, inventoryHistoryPrefix):
+		return loadHistoryInventory(strings.TrimPrefix(arg, inventoryHistoryPrefix))
+	}
+
+	data, err := os.ReadFile(arg)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+	var inventory struct {
+		Resources *[]display.ResourceInfo `json:"resources"`
+		Errors    []display.ErrorInfo     `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return "", nil, fmt.Errorf("invalid inventory %s: %w", arg, err)
+	}
+	if inventory.Resources == nil {
+		return "", nil, fmt.Errorf("invalid inventory %s: no resources list, expected the output of mohua --json", arg)
+	}
+	if err := incompleteError("inventory "+arg+" is incomplete", inventory.Errors); err != nil {
+		return "", nil, err
+	}
+	return arg, *inventory.Resources, nil
+}
+
+// loadHistoryInventory returns the snapshot saved at the given time for --region and --profile
+func loadHistoryInventory(at string) (string, []display.ResourceInfo, error) {
+	t, err := parseHistoryTime(at, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+	store, err := history.Open(historyPath())
+	if err != nil {
+		return "", nil, err
+	}
+	defer store.Close()
+
+	snapshot, err := store.At(t, historyScope())
+	if err != nil {
+		return "", nil, err
+	}
+	name := "snapshot " + snapshot.Timestamp.Local().Format(time.RFC3339)
+	if err := incompleteError(name+" is incomplete", snapshot.Errors); err != nil {
+		return "", nil, err
+	}
+	return name, snapshot.Resources, nil
+}
+
+// loadLiveInventory lists the current resources without printing them
+func loadLiveInventory() (string, []display.ResourceInfo, error) {
+	region, resources, err := listLiveResources(display.TagKey(groupBy) != "")
+	if err != nil {
+		return "", nil, err
+	}
+	return "live " + region, resources, nil
+}
+
+// listLiveResources lists the current resources with the filter flags without printing them,
+// and returns them with the region. It fails if any resource type could not be listed.
+func listLiveResources(fetchTags bool) (string, []display.ResourceInfo, error) {
+	client, filter, err := newRunClient()
+	if err != nil {
+		return "", nil, err
+	}
+	ctx, cancel := runContext(timeout)
+	defer cancel()
+
+	printer := display.NewPrinter(true)
+	printer.SetOutput(io.Discard)
+	if err := runMonitor(ctx, client, filter, printer, fetchTags); err != nil {
+		return "", nil, fmt.Errorf("failed to list live resources: %w", err)
+	}
+	if err := incompleteError("failed to list live resources", printer.Errors()); err != nil {
+		return "", nil, err
+	}
+	return client.GetRegion(), printer.Resources(), nil
+}
+
+// incompleteError fails an inventory where a resource type could not be listed, as its
+// missing resources would look removed; it returns nil for a complete one
+func incompleteError(prefix string, failed []display.ErrorInfo) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	messages := make([]string, len(failed))
+	for i, info := range failed {
+		messages[i] = info.Message
+	}
+	return fmt.Errorf("%s: %s", prefix, strings.Join(messages, "; "))
+}
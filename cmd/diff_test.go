@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/history"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// writeInventory writes resources in the form of mohua --json output
+func writeInventory(t *testing.T, resources []display.ResourceInfo) string {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	data, err := json.Marshal(map[string]any{"resources": resources})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestDiff_Unit(t *testing.T) {
+	endpoint := display.ResourceInfo{ResourceType: "Endpoint", Name: "prod", Region: "us-west-2", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1, HourlyCost: 0.115}
+	notebook := display.ResourceInfo{ResourceType: "Notebook", Name: "dev", Region: "us-west-2", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, HourlyCost: 0.05}
+	oldPath := writeInventory(t, []display.ResourceInfo{endpoint, notebook})
+	scaled := endpoint
+	scaled.InstanceCount = 2
+	scaled.HourlyCost = 0.23
+	newPath := writeInventory(t, []display.ResourceInfo{scaled})
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"diff", oldPath, newPath, "-j"}, new(MockSageMakerClient))
+	})
+	assert.ErrorContains(t, err, "inventories differ: 0 added, 1 removed, 1 changed")
+	assert.Equal(t, ExitCodeChanges, ExitCode(err))
+
+	var d display.Diff
+	assert.NoError(t, json.Unmarshal([]byte(out), &d))
+	assert.Equal(t, oldPath, d.Old)
+	assert.Equal(t, newPath, d.New)
+	assert.Equal(t, 1, d.Changed)
+	assert.Equal(t, 1, d.Removed)
+	assert.InDelta(t, 0.065, d.HourlyCostDelta, 1e-9)
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"diff", oldPath, oldPath, "--color", "never"}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Added: 0  Removed: 0  Changed: 0  Unchanged: 2")
+}
+
+func TestDiffLive_Unit(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(historyFile)
+	assert.NoError(t, err)
+	// Yesterday's snapshot had the endpoint on a smaller instance type
+	assert.NoError(t, store.Append(history.NewSnapshot(time.Now().Add(-24*time.Hour), "us-west-2", "", []display.ResourceInfo{
+		{ResourceType: "Endpoint", Name: "prod", Region: "us-west-2", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1},
+	}, nil)))
+	assert.NoError(t, store.Close())
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "prod", Status: "InService", InstanceType: "ml.m5.xlarge", InstanceCount: 1, CreationTime: time.Now().Add(-48 * time.Hour)},
+	}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"diff", "history:12h", "--history-file", historyFile, "--color", "never"}, mockClient)
+	})
+	assert.Equal(t, ExitCodeChanges, ExitCode(err))
+	// Only the diff is printed, not the live listing
+	assert.Regexp(t, `Comparing snapshot \S+ with live us-west-2`, out)
+	assert.Regexp(t, `changed\s+Endpoint\s+prod\s+us-west-2\s+instanceType ml\.m5\.large -> ml\.m5\.xlarge`, out)
+	assert.NotContains(t, out, "Running Time")
+	mockClient.AssertExpectations(t)
+}
+
+func TestDiffLivePartial_Unit(t *testing.T) {
+	path := writeInventory(t, []display.ResourceInfo{
+		{ResourceType: "Notebook", Name: "dev", Region: "us-west-2", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1},
+	})
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo(nil), errors.New("ThrottlingException"))
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"diff", path}, mockClient)
+	})
+	// The notebook that couldn't be listed is not reported as removed
+	assert.ErrorContains(t, err, "failed to list live resources: failed to list notebooks: ThrottlingException")
+	assert.Equal(t, 1, ExitCode(err))
+	assert.NotContains(t, out, "removed")
+}
+
+func TestDiffErrors_Unit(t *testing.T) {
+	dir := t.TempDir()
+	notInventory := filepath.Join(dir, "config.json")
+	assert.NoError(t, os.WriteFile(notInventory, []byte(`{"region": "us-east-1"}`), 0o600))
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`not json`), 0o600))
+	partial := filepath.Join(dir, "partial.json")
+	assert.NoError(t, os.WriteFile(partial, []byte(`{"resources": [], "errors": [{"resourceType": "Notebook", "message": "failed to list notebooks: AccessDenied"}]}`), 0o600))
+	historyFile := filepath.Join(dir, "history.db")
+	store, err := history.Open(historyFile)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Append(history.NewSnapshot(time.Now().Add(-time.Hour), "us-west-2", "", nil, []display.ErrorInfo{
+		{ResourceType: "Endpoint", Message: "context deadline exceeded", TimedOut: true},
+	})))
+	assert.NoError(t, store.Close())
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "missing file", args: []string{"diff", filepath.Join(dir, "missing.json"), invalid}, want: "failed to read inventory"},
+		{name: "invalid JSON", args: []string{"diff", invalid, invalid}, want: "invalid inventory"},
+		{name: "not an inventory", args: []string{"diff", notInventory, notInventory}, want: "no resources list"},
+		{name: "no snapshot", args: []string{"diff", "history:2020-01-01", invalid, "--history-file", filepath.Join(dir, "history.db")}, want: "no snapshot found"},
+		{name: "no arguments", args: []string{"diff"}, want: "accepts between 1 and 2 arg(s)"},
+		// Resources of the types that failed would look removed
+		{name: "partial inventory", args: []string{"diff", partial, partial}, want: "inventory " + partial + " is incomplete: failed to list notebooks: AccessDenied"},
+		{name: "incomplete snapshot", args: []string{"diff", "history:0s", partial, "--history-file", historyFile}, want: "is incomplete: context deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+			assert.Equal(t, 1, ExitCode(err))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("failed")))
+	assert.Equal(t, ExitCodeChanges, ExitCode(&ExitError{Code: ExitCodeChanges, Err: errors.New("differ")}))
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/digest"
+)
+
+var (
+	smtpHost       string
+	smtpPort       int
+	smtpUsername   string
+	smtpPassword   string
+	smtpStartTLS   bool
+	digestFrom     string
+	digestTo       []string
+	digestRoutes   []string
+	digestOwnerTag string
+	digestIdle     string
+	digestDryRun   bool
+)
+
+// digestCmd emails a per-owner summary of the running resources
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Email a digest of the running resources grouped by owner",
+	Long: `List the resources with the usual filter flags and email a digest: one table per
+owner with its totals, flagging notebooks and Studio apps running longer than --idle-after
+as possibly idle. The email has an HTML and a plain-text version.
+
+The owner is the user profile of Studio apps and the --owner-tag tag of other resources.
+Every --to address receives the whole digest. An owner's resources are also sent to the
+addresses routed to it with --route owner=address, or to the owner itself when it is an
+email address, e.g. a tag owner=alice@example.com.
+
+Schedule it from cron, e.g. every weekday morning. Keep the SMTP password in
+MOHUA_SMTP_PASSWORD or the configuration file rather than on the command line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		routes, err := digest.ParseRoutes(digestRoutes)
+		if err != nil {
+			return err
+		}
+		for _, address := range digestTo {
+			if err := digest.ValidateAddress(address); err != nil {
+				return fmt.Errorf("invalid --to: %w", err)
+			}
+		}
+		idleAfter, err := parseIdleAfter(digestIdle)
+		if err != nil {
+			return err
+		}
+		if !digestDryRun {
+			if smtpHost == "" {
+				return fmt.Errorf("--smtp-host is required, or use --dry-run to print the digest")
+			}
+			if err := digest.ValidateAddress(digestFrom); err != nil {
+				return fmt.Errorf("invalid --from: %w", err)
+			}
+		}
+
+		// Tags are fetched for the owner tag
+		region, resources, err := listLiveResources(true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		d := digest.Build(resources, digest.Options{
+			OwnerTag:  digestOwnerTag,
+			IdleAfter: idleAfter,
+			Region:    region,
+			Now:       now,
+		})
+		deliveries := digest.Plan(d, digestTo, routes)
+		if len(deliveries) == 0 {
+			return fmt.Errorf("no recipients: set --to, or --route owners to addresses")
+		}
+		if digestDryRun {
+			printDeliveries(os.Stdout, deliveries)
+			return nil
+		}
+		return sendDigests(deliveries, now)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+}
+
+// addDigestFlags registers the SMTP, recipient and owner flags of digest
+func addDigestFlags() {
+	digestCmd.Flags().StringVar(&smtpHost, "smtp-host", "", "SMTP server host name")
+	digestCmd.Flags().IntVar(&smtpPort, "smtp-port", 587, "SMTP server port")
+	digestCmd.Flags().StringVar(&smtpUsername, "smtp-username", "", "SMTP user name; authenticates with PLAIN when set")
+	digestCmd.Flags().StringVar(&smtpPassword, "smtp-password", "", "SMTP password (prefer the MOHUA_SMTP_PASSWORD environment variable)")
+	digestCmd.Flags().BoolVar(&smtpStartTLS, "smtp-starttls", true, "Require upgrading the connection with STARTTLS; disable only for trusted local relays")
+	digestCmd.Flags().StringVar(&digestFrom, "from", "", "Sender address, e.g. mohua@example.com")
+	digestCmd.Flags().StringSliceVar(&digestTo, "to", nil, "Send the whole digest to this address (repeatable)")
+	digestCmd.Flags().StringSliceVar(&digestRoutes, "route", nil, "Send an owner's resources to an address, e.g. ml-team=ml-leads@example.com (repeatable)")
+	digestCmd.Flags().StringVar(&digestOwnerTag, "owner-tag", "owner", "Tag naming the owner of endpoints and notebooks, e.g. owner or team")
+	digestCmd.Flags().StringVar(&digestIdle, "idle-after", "24h", "Flag notebooks and Studio apps running longer than this as possibly idle (0 disables)")
+	digestCmd.Flags().BoolVar(&digestDryRun, "dry-run", false, "Print the plain-text emails instead of sending them")
+}
+
+// parseIdleAfter parses --idle-after, where 0 disables the idle flag
+func parseIdleAfter(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --idle-after: %w", err)
+	}
+	return d, nil
+}
+
+// printDeliveries writes every email as it would be sent, in plain text
+func printDeliveries(w io.Writer, deliveries []digest.Delivery) {
+	for i, delivery := range deliveries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "To: %s\nSubject: %s\n\n", strings.Join(delivery.To, ", "), delivery.Digest.Subject())
+		fmt.Fprint(w, digest.Text(delivery.Digest))
+	}
+}
+
+// sendDigests sends every email, continuing after failures so that one bad address doesn't
+// stop the others
+func sendDigests(deliveries []digest.Delivery, now time.Time) error {
+	config := digest.SMTPConfig{
+		Host:     smtpHost,
+		Port:     smtpPort,
+		Username: smtpUsername,
+		Password: smtpPassword,
+		StartTLS: smtpStartTLS,
+	}
+	var errs []error
+	for _, delivery := range deliveries {
+		html, err := digest.HTML(delivery.Digest)
+		if err != nil {
+			return err
+		}
+		err = digest.Send(config, digest.Message{
+			From:    digestFrom,
+			To:      delivery.To,
+			Subject: delivery.Digest.Subject(),
+			Text:    digest.Text(delivery.Digest),
+			HTML:    html,
+			Date:    now,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send digest to %s: %w", strings.Join(delivery.To, ", "), err))
+			continue
+		}
+		slog.Info("sent digest", "to", delivery.To, "resources", delivery.Digest.Resources)
+	}
+	return errors.Join(errs...)
+}
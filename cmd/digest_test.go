@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"mohua/internal/fakesmtp"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newDigestClient lists an endpoint tagged team=ml, a notebook tagged with its owner's address
+// running for 3 days and a Studio app
+func newDigestClient() *MockSageMakerClient {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "serving", Arn: "arn:serving", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 1, CreationTime: time.Now().Add(-240 * time.Hour)},
+	}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "exp", Arn: "arn:exp", Status: "InService", InstanceType: "ml.t3.medium", CreationTime: time.Now().Add(-72 * time.Hour)},
+	}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "default", Status: "InService", InstanceType: "ml.t3.medium", UserProfile: "bob", CreationTime: time.Now().Add(-time.Hour)},
+	}, nil)
+	mockClient.On("ListTags", mock.Anything, "arn:serving").Return(map[string]string{"team": "ml"}, nil)
+	mockClient.On("ListTags", mock.Anything, "arn:exp").Return(map[string]string{"team": "alice@example.com"}, nil)
+	return mockClient
+}
+
+func TestExecuteDigest_Unit(t *testing.T) {
+	server, err := fakesmtp.New(fakesmtp.Options{Username: "mohua", Password: "secret"})
+	assert.NoError(t, err)
+	defer server.Close()
+	t.Setenv("MOHUA_SMTP_PASSWORD", "secret")
+
+	err = mockExecute(t, []string{"digest",
+		"--smtp-host", server.Host(), "--smtp-port", strconv.Itoa(server.Port()), "--smtp-starttls=false", "--smtp-username", "mohua",
+		"--from", "mohua@example.com", "--to", "manager@example.com", "--owner-tag", "team", "--route", "ml=ml-leads@example.com",
+	}, newDigestClient())
+	assert.NoError(t, err)
+
+	messages := server.Messages()
+	if !assert.Len(t, messages, 3) {
+		return
+	}
+	assert.Equal(t, []string{"manager@example.com"}, messages[0].To)
+	assert.Equal(t, "mohua@example.com", messages[0].From)
+	assert.Regexp(t, `^mohua digest: 3 resource\(s\), \$[0-9.]+/hour, 1 possibly idle in us-west-2$`, messages[0].Header("Subject"))
+	parts, err := messages[0].Parts()
+	assert.NoError(t, err)
+	assert.Contains(t, parts["text/plain"], "\nalice@example.com: 1 resource(s)")
+	assert.Contains(t, parts["text/plain"], "\nbob: 1 resource(s)")
+	assert.Contains(t, parts["text/plain"], "\nml: 1 resource(s)")
+	assert.Contains(t, parts["text/html"], "<td>exp</td>")
+
+	// The notebook's owner is an address and the ml team is routed
+	assert.Equal(t, []string{"alice@example.com"}, messages[1].To)
+	parts, err = messages[1].Parts()
+	assert.NoError(t, err)
+	assert.Regexp(t, `exp +ml\.t3\.medium +InService +3d 0h 0m +\$[0-9.]+ +idle\?`, parts["text/plain"])
+	assert.NotContains(t, parts["text/plain"], "serving")
+
+	assert.Equal(t, []string{"ml-leads@example.com"}, messages[2].To)
+	assert.Contains(t, messages[2].Header("Subject"), "mohua digest: 1 resource(s)")
+}
+
+func TestExecuteDigestDryRun_Unit(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"digest", "--dry-run", "--owner-tag", "team", "--idle-after", "0"}, newDigestClient())
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "To: alice@example.com\nSubject: mohua digest: 1 resource(s), $")
+	assert.NotContains(t, out, "idle?")
+	// The owner tag is fetched without changing --group-by
+	assert.Empty(t, groupBy)
+}
+
+func TestExecuteDigestPartial_Unit(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo(nil), errors.New("circuit breaker us-west-2 is open"))
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"digest", "--dry-run", "--to", "manager@example.com"}, mockClient)
+	})
+	// An incomplete digest is not sent
+	assert.ErrorContains(t, err, "failed to list live resources: failed to list endpoints: circuit breaker us-west-2 is open")
+	assert.NotContains(t, out, "Subject:")
+}
+
+func TestExecuteDigestErrors_Unit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no SMTP host", args: []string{"--from", "mohua@example.com", "--to", "a@example.com"}, want: "--smtp-host is required"},
+		{name: "invalid from", args: []string{"--smtp-host", "localhost", "--from", "mohua", "--to", "a@example.com"}, want: "invalid --from"},
+		{name: "invalid to", args: []string{"--dry-run", "--to", "manager"}, want: "invalid --to"},
+		{name: "invalid route", args: []string{"--dry-run", "--route", "ml"}, want: `invalid route "ml"`},
+		{name: "invalid idle", args: []string{"--dry-run", "--idle-after", "soon"}, want: "invalid --idle-after"},
+		{name: "no recipients", args: []string{"--dry-run", "--owner-tag", "missing"}, want: "no recipients"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, append([]string{"digest"}, tt.args...), newDigestClient())
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{input: "90m", expected: 90 * time.Minute},
+		{input: "3d", expected: 72 * time.Hour},
+		{input: "1w", expected: 168 * time.Hour},
+		{input: "1w2d12h", expected: 228 * time.Hour},
+		{input: "2d30m", expected: 48*time.Hour + 30*time.Minute},
+		{input: "", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "3x", wantErr: true},
+		{input: "-1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := parseDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
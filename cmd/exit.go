@@ -0,0 +1,37 @@
+package cmd
+
+import "errors"
+
+// Exit codes besides 0 for success and 1 for errors
+const (
+	// ExitCodeChanges is returned by diff when the inventories differ
+	ExitCodeChanges = 2
+	// ExitCodeThresholds is returned when the listed resources breach a threshold
+	ExitCodeThresholds = 3
+)
+
+// ExitError is returned by commands that report their result through a specific exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/display"
+	"mohua/internal/history"
+)
+
+// historyTimeLayouts are the accepted absolute timestamps, from most to least precise;
+// layouts without a zone are read in local time
+var historyTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// historyCmd groups the commands browsing the snapshots saved with --save-history
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the snapshots saved with --save-history",
+}
+
+// historyListCmd prints one line per saved snapshot
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snapshots, oldest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rejectHTMLOutput(cmd); err != nil {
+			return err
+		}
+		store, err := history.Open(historyPath())
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		summaries, err := store.List()
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			if summaries == nil {
+				summaries = []history.Summary{}
+			}
+			return printJSON(os.Stdout, summaries)
+		}
+		printHistoryList(os.Stdout, summaries)
+		return nil
+	},
+}
+
+// historyShowCmd prints the snapshot describing what was running at a given time
+var historyShowCmd = &cobra.Command{
+	Use:   "show <timestamp>",
+	Short: "Show what was running at a time, e.g. 2024-05-01T15:00 or 1d (ago)",
+	Long: `Show the latest snapshot taken at or before the given time.
+
+The time is an RFC 3339 timestamp as printed by "history list", a local date and time
+such as "2024-05-01 15:00" or 2024-05-01, or an age such as 12h or 1d. With --region or
+--profile, only the snapshots of that region or profile are considered.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := display.ValidateColumns(tableColumns); err != nil {
+			return err
+		}
+		if groupBy != "" {
+			if err := display.ValidateGroupBy(groupBy); err != nil {
+				return err
+			}
+		}
+		at, err := parseHistoryTime(args[0], time.Now())
+		if err != nil {
+			return err
+		}
+
+		store, err := history.Open(historyPath())
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		snapshot, err := store.At(at, historyScope())
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(os.Stdout, snapshot)
+		}
+		printSnapshot(snapshot)
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+// historyScope selects the snapshots of the --region and --profile flags, or of any region
+// or profile when they are not set
+func historyScope() history.Scope {
+	return history.Scope{Region: region, Profile: profile}
+}
+
+// parseHistoryTime parses an absolute timestamp in one of historyTimeLayouts, or an age
+// relative to now
+func parseHistoryTime(s string, now time.Time) (time.Time, error) {
+	for _, layout := range historyTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q: use e.g. 2024-05-01T15:00:00Z, \"2024-05-01 15:00\", 2024-05-01 or 1d", s)
+}
+
+// printHistoryList writes the snapshot summaries as a table
+func printHistoryList(w io.Writer, summaries []history.Summary) {
+	if len(summaries) == 0 {
+		fmt.Fprintf(w, "No snapshots in %s; save one with --save-history\n", historyPath())
+		return
+	}
+	fmt.Fprintf(w, "%-26s %-15s %-13s %-12s %10s %12s %14s %s\n", "Timestamp", "Region", "Account", "Profile", "Resources", "Hourly Cost", "Monthly Cost", "Complete")
+	fmt.Fprintln(w, strings.Repeat("-", 123))
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%-26s %-15s %-13s %-12s %10d %12s %14s %t\n",
+			s.Timestamp.Local().Format(time.RFC3339),
+			s.Region,
+			s.Account,
+			s.Profile,
+			s.Resources,
+			fmt.Sprintf("$%.2f", s.HourlyCost),
+			fmt.Sprintf("$%.2f", s.MonthlyCost),
+			s.Complete,
+		)
+	}
+}
+
+// printSnapshot writes a snapshot as the usual resource table, or HTML page with --output html
+func printSnapshot(snapshot history.Snapshot) {
+	heading := fmt.Sprintf("Snapshot: %s, region %s", snapshot.Timestamp.Local().Format(time.RFC3339), snapshot.Region)
+	if snapshot.Account != "" {
+		heading += ", account " + snapshot.Account
+	}
+	if snapshot.Profile != "" {
+		heading += ", profile " + snapshot.Profile
+	}
+	if outputFormat != display.OutputHTML {
+		fmt.Println(heading)
+		fmt.Println()
+	}
+
+	printer := display.NewPrinter(false)
+	if outputFormat == display.OutputHTML {
+		printer.SetFormat(display.OutputHTML)
+		printer.SetTitle(heading)
+	}
+	printer.SetGroupBy(groupBy)
+	printer.SetColumns(tableColumns)
+	printer.SetColorMode(colorMode)
+	for _, info := range snapshot.Errors {
+		printer.PrintError(info)
+	}
+	if len(snapshot.Resources) == 0 {
+		printer.PrintNoResources(snapshot.Region)
+		return
+	}
+	printer.PrintHeader()
+	for _, resource := range snapshot.Resources {
+		printer.PrintResource(resource)
+	}
+	printer.PrintFooter()
+}
+
+// printJSON writes v as indented JSON
+func printJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
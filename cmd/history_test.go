@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/history"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecuteWithSaveHistory_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{{
+		Name:          "prod",
+		Arn:           "arn:aws:sagemaker:us-west-2:123456789012:endpoint/prod",
+		Status:        "InService",
+		InstanceType:  "ml.m5.large",
+		InstanceCount: 2,
+		CreationTime:  time.Now().Add(-time.Hour),
+	}}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	captureStdout(t, func() {
+		err = mockExecute(t, []string{"--save-history", "--history-file", path}, mockClient)
+	})
+	assert.NoError(t, err)
+
+	// history list reads the snapshot back
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"history", "list", "-j", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	var summaries []history.Summary
+	assert.NoError(t, json.Unmarshal([]byte(out), &summaries))
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, "us-west-2", summaries[0].Region)
+	assert.Equal(t, "123456789012", summaries[0].Account)
+	assert.Equal(t, 1, summaries[0].Resources)
+	assert.True(t, summaries[0].Complete)
+	assert.Greater(t, summaries[0].HourlyCost, 0.0)
+
+	// history show prints the resources in the snapshot
+	timestamp := summaries[0].Timestamp.Format(time.RFC3339)
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"history", "show", timestamp, "--history-file", path, "--color", "never"}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Snapshot: ")
+	assert.Contains(t, out, "account 123456789012")
+	assert.Regexp(t, `Endpoint\s+prod\s+InService\s+ml\.m5\.large`, out)
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"history", "show", "0s", "-j", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	var snapshot history.Snapshot
+	assert.NoError(t, json.Unmarshal([]byte(out), &snapshot))
+	assert.Equal(t, "prod", snapshot.Resources[0].Name)
+}
+
+func TestHistoryShowScope_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(path)
+	assert.NoError(t, err)
+	now := time.Now()
+	for _, region := range []string{"us-west-2", "eu-west-1"} {
+		resources := []display.ResourceInfo{{ResourceType: "Endpoint", Name: "prod-" + region, Region: region}}
+		assert.NoError(t, store.Append(history.NewSnapshot(now, region, "ops", resources, nil)))
+	}
+	assert.NoError(t, store.Close())
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "region", args: []string{"--region", "us-west-2"}, want: "prod-us-west-2"},
+		{name: "other region", args: []string{"--region", "eu-west-1", "--profile", "ops"}, want: "prod-eu-west-1"},
+		{name: "other profile", args: []string{"--region", "eu-west-1", "--profile", "dev"}, wantErr: "in region eu-west-1, profile dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			out := captureStdout(t, func() {
+				err = mockExecute(t, append([]string{"history", "show", "0s", "-j", "--history-file", path}, tt.args...), new(MockSageMakerClient))
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			var snapshot history.Snapshot
+			assert.NoError(t, json.Unmarshal([]byte(out), &snapshot))
+			assert.Equal(t, tt.want, snapshot.Resources[0].Name)
+		})
+	}
+}
+
+func TestHistoryRetention_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Append(history.NewSnapshot(time.Now().Add(-48*time.Hour), "us-west-2", "", nil, nil)))
+	assert.NoError(t, store.Close())
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(false, nil)
+
+	captureStdout(t, func() {
+		err = mockExecute(t, []string{"--save-history", "--history-file", path, "--history-retention", "1d"}, mockClient)
+	})
+	assert.NoError(t, err)
+
+	store, err = history.Open(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	summaries, err := store.List()
+	assert.NoError(t, err)
+	// The old snapshot was pruned and the empty run was recorded
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, 0, summaries[0].Resources)
+}
+
+func TestHistoryErrors_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "invalid retention", args: []string{"--history-retention", "forever"}, want: "invalid --history-retention"},
+		{name: "invalid timestamp", args: []string{"history", "show", "yesterday", "--history-file", path}, want: `invalid timestamp "yesterday"`},
+		{name: "no snapshot", args: []string{"history", "show", "2024-05-01", "--history-file", path}, want: "no snapshot found"},
+		{name: "missing timestamp", args: []string{"history", "show", "--history-file", path}, want: "accepts 1 arg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{input: "2024-05-01T15:00:00Z", want: time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)},
+		{input: "2024-05-01 15:00", want: time.Date(2024, 5, 1, 15, 0, 0, 0, time.Local)},
+		{input: "2024-05-01T15:00:30", want: time.Date(2024, 5, 1, 15, 0, 30, 0, time.Local)},
+		{input: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{input: "1d", want: now.Add(-24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseHistoryTime(tt.input, now)
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
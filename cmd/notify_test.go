@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteWithNotifications_Unit(t *testing.T) {
+	var mu sync.Mutex
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			Text string `json:"text"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &body))
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	statePath := filepath.Join(t.TempDir(), "notify-state.json")
+
+	var err error
+	args := []string{"--notify-slack", server.URL, "--notify-state", statePath, "--max-age", "3d"}
+	captureStdout(t, func() {
+		err = mockExecute(t, args, newThresholdClient())
+	})
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+	if assert.Len(t, texts, 1) {
+		assert.Contains(t, texts[0], "2 mohua alert(s) in us-west-2:\n")
+		assert.Contains(t, texts[0], "- max-age: Notebook old has been running for 96h, more than 72h\n")
+		assert.Contains(t, texts[0], "- new GPU Endpoint gpu on 2 x ml.p4d.24xlarge")
+	}
+	_, statErr := os.Stat(statePath)
+	assert.NoError(t, statErr)
+
+	// The next run finds the same conditions and stays quiet
+	captureStdout(t, func() {
+		err = mockExecute(t, args, newThresholdClient())
+	})
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+	assert.Len(t, texts, 1)
+}
+
+func TestExecuteWithInvalidNotifications_Unit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "invalid URL", args: []string{"--notify-teams", "not-a-url"}, want: "invalid teams webhook URL"},
+		{name: "invalid kind", args: []string{"--notify-on", "cost"}, want: `invalid alert kind "cost"`},
+		{name: "invalid repeat", args: []string{"--notify-repeat", "often"}, want: "invalid --notify-repeat"},
+		{name: "invalid template", args: []string{"--notify-webhook", "http://localhost", "--notify-template", "{{.Alerts"}, want: "invalid notification template"},
+		{name: "missing template file", args: []string{"--notify-webhook", "http://localhost", "--notify-template", "@missing.tmpl"}, want: "failed to read --notify-template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteWithHTMLOutput_Unit(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"-o", "html", "--max-age", "72h"}, newThresholdClient())
+	})
+	// Thresholds still set the exit code
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"), out)
+	assert.Contains(t, out, "<h2>Endpoint (1)</h2>")
+	assert.Contains(t, out, "<h2>Notebook (1)</h2>")
+	assert.Contains(t, out, `<tr class="highlight"><td data-sort="old">old</td>`)
+	assert.Contains(t, out, "<li>max-age: Notebook old has been running for 96h, more than 72h</li>")
+	assert.Contains(t, out, "<h2>Hourly cost by instance type</h2>")
+	assert.NotContains(t, out, "Total resources:")
+
+	captureStdout(t, func() {
+		err = mockExecute(t, []string{"--json", "-o", "html"}, newThresholdClient())
+	})
+	assert.ErrorContains(t, err, "--json and --output html cannot be used together")
+
+	captureStdout(t, func() {
+		err = mockExecute(t, []string{"-o", "xml"}, newThresholdClient())
+	})
+	assert.ErrorContains(t, err, `invalid output "xml"`)
+}
+
+func TestHTMLOutputCommands_Unit(t *testing.T) {
+	path := writeHistory(t)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"report", "-o", "html", "--since", "1d", "--max-gap", "90m", "--owner-tag", "team", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"), out)
+	assert.Contains(t, out, "<h2>Cost by owner</h2>")
+	assert.Contains(t, out, `title="ml">ml</div>`)
+	assert.Contains(t, out, "<h2>Gaps (2)</h2>")
+	assert.Contains(t, out, `<td class="num" data-sort="5400">1h30m0s</td>`)
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"history", "show", "0s", "-o", "html", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"), out)
+	assert.Contains(t, out, "<title>Snapshot: ")
+	assert.Contains(t, out, `<td data-sort="prod">prod</td>`)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "report csv", args: []string{"report", "--csv", "-o", "html", "--history-file", path}, want: "--csv and --output html cannot be used together"},
+		{name: "history list", args: []string{"history", "list", "-o", "html", "--history-file", path}, want: "mohua history list does not support --output html"},
+		{name: "config show", args: []string{"config", "show", "-o", "html"}, want: "mohua config show does not support --output html"},
+		{name: "diff", args: []string{"diff", filepath.Join(t.TempDir(), "old.json"), "-o", "html"}, want: "mohua diff does not support --output html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			captureStdout(t, func() {
+				err = mockExecute(t, tt.args, new(MockSageMakerClient))
+			})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
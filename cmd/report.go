@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/display"
+	"mohua/internal/history"
+)
+
+var (
+	reportSince    string
+	reportBucket   string
+	reportMaxGap   string
+	reportOwnerTag string
+	reportCSV      bool
+)
+
+// reportCmd integrates the saved snapshots into instance-hours and estimated cost over time
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report instance-hours and estimated cost over time from the saved snapshots",
+	Long: `Report instance-hours and estimated cost per time bucket, resource type, instance type
+and owner, integrated from the snapshots saved with --save-history.
+
+Each snapshot is assumed to describe what was running until the next one, for at most
+--max-gap. Longer periods without a snapshot are listed as gaps and contribute nothing, so
+take snapshots at least that often, e.g. hourly from cron.
+
+The owner is the user profile of Studio apps and the --owner-tag tag of other resources;
+tags are only saved when the snapshot was taken with --group-by tag:<key>.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportCSV && jsonOutput {
+			return fmt.Errorf("--csv and --json cannot be used together")
+		}
+		if reportCSV && outputFormat == display.OutputHTML {
+			return fmt.Errorf("--csv and --output html cannot be used together")
+		}
+		if err := history.ValidateBucket(reportBucket); err != nil {
+			return err
+		}
+		since, err := parseDuration(reportSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		maxGap, err := parseDuration(reportMaxGap)
+		if err != nil || maxGap <= 0 {
+			return fmt.Errorf("invalid --max-gap %q: must be a positive duration", reportMaxGap)
+		}
+
+		now := time.Now()
+		opts := history.ReportOptions{
+			From:     history.BucketStart(now.Add(-since), reportBucket),
+			To:       now,
+			Bucket:   reportBucket,
+			MaxGap:   maxGap,
+			OwnerTag: reportOwnerTag,
+		}
+		store, err := history.Open(historyPath())
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		// Snapshots up to --max-gap before the range describe its start
+		snapshots, err := store.Range(opts.From.Add(-maxGap), opts.To)
+		if err != nil {
+			return err
+		}
+
+		report := history.BuildReport(snapshots, opts)
+		switch {
+		case jsonOutput:
+			return printJSON(os.Stdout, report)
+		case reportCSV:
+			return printReportCSV(os.Stdout, report)
+		case outputFormat == display.OutputHTML:
+			return display.WriteHTML(os.Stdout, reportPage(report))
+		}
+		printReport(os.Stdout, report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+}
+
+// addReportFlags registers the period, bucket, owner and CSV flags of report
+func addReportFlags() {
+	reportCmd.Flags().StringVar(&reportSince, "since", "7d", "Report on this long before now, e.g. 24h, 7d or 4w")
+	reportCmd.Flags().StringVar(&reportBucket, "bucket", history.BucketDay, "Time bucket: hour, day or week")
+	reportCmd.Flags().StringVar(&reportMaxGap, "max-gap", "2h", "Longest time a snapshot is assumed to hold; longer periods without one are reported as gaps")
+	reportCmd.Flags().StringVar(&reportOwnerTag, "owner-tag", "owner", "Tag naming the owner of endpoints and notebooks")
+	reportCmd.Flags().BoolVar(&reportCSV, "csv", false, "Output in CSV format")
+}
+
+// bucketLabel formats a bucket start for the table, in local time
+func bucketLabel(t time.Time, bucket string) string {
+	if bucket == history.BucketHour {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// printReport writes the report as a table of rows, per-bucket totals and gaps
+func printReport(w io.Writer, report history.Report) {
+	fmt.Fprintf(w, "Report from %s to %s by %s, %d snapshots\n\n",
+		report.From.Local().Format(time.RFC3339), report.To.Local().Format(time.RFC3339), report.Bucket, report.Snapshots)
+
+	fmt.Fprintf(w, "%-17s %-15s %-15s %-20s %16s %14s\n", "Bucket", "Type", "Instance Type", "Owner", "Instance Hours", "Cost")
+	fmt.Fprintln(w, strings.Repeat("-", 102))
+	for _, row := range report.Rows {
+		fmt.Fprintf(w, "%-17s %-15s %-15s %-20s %16.1f %14s\n",
+			bucketLabel(row.Bucket, report.Bucket),
+			row.ResourceType,
+			row.InstanceType,
+			display.TruncateString(row.Owner, 20),
+			row.InstanceHours,
+			fmt.Sprintf("$%.2f", row.Cost),
+		)
+	}
+	if len(report.Rows) == 0 {
+		fmt.Fprintln(w, "No billed resources in the saved snapshots")
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-17s %10s %16s %14s\n", "Bucket", "Coverage", "Instance Hours", "Cost")
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	for _, bucket := range report.Buckets {
+		fmt.Fprintf(w, "%-17s %10s %16.1f %14s\n",
+			bucketLabel(bucket.Start, report.Bucket),
+			formatCoverage(bucket.Coverage),
+			bucket.InstanceHours,
+			fmt.Sprintf("$%.2f", bucket.Cost),
+		)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "%-17s %10s %16.1f %14s\n", "Total", "", report.InstanceHours, fmt.Sprintf("$%.2f", report.Cost))
+
+	if len(report.Gaps) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Gaps without snapshots, not included in the totals:")
+		for _, gap := range report.Gaps {
+			region := gap.Region
+			if region == "" {
+				region = "all regions"
+			}
+			if gap.Profile != "" {
+				region += " (" + gap.Profile + ")"
+			}
+			fmt.Fprintf(w, "  %s: %s to %s (%s)\n",
+				region,
+				gap.Start.Local().Format("2006-01-02 15:04"),
+				gap.End.Local().Format("2006-01-02 15:04"),
+				gap.End.Sub(gap.Start).Round(time.Minute),
+			)
+		}
+	}
+}
+
+// printReportCSV writes one record per report row; the bucket coverage flags rows from
+// buckets with gaps
+func printReportCSV(w io.Writer, report history.Report) error {
+	coverage := make(map[int64]float64, len(report.Buckets))
+	for _, bucket := range report.Buckets {
+		coverage[bucket.Start.Unix()] = bucket.Coverage
+	}
+
+	out := csv.NewWriter(w)
+	records := [][]string{{"bucket", "resource_type", "instance_type", "owner", "instance_hours", "estimated_cost", "bucket_coverage"}}
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.Bucket.Format(time.RFC3339),
+			row.ResourceType,
+			row.InstanceType,
+			row.Owner,
+			strconv.FormatFloat(row.InstanceHours, 'f', 3, 64),
+			strconv.FormatFloat(row.Cost, 'f', 4, 64),
+			strconv.FormatFloat(coverage[row.Bucket.Unix()], 'f', 3, 64),
+		})
+	}
+	if err := out.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// reportPage builds the HTML page of a report: cost per bucket, owner and instance type
+// charts, and sortable tables of the rows, bucket totals and gaps
+func reportPage(report history.Report) display.HTMLPage {
+	page := display.HTMLPage{
+		Title: "Cost report",
+		Subtitle: fmt.Sprintf("From %s to %s by %s, %d snapshots",
+			report.From.Local().Format("2006-01-02 15:04"), report.To.Local().Format("2006-01-02 15:04"), report.Bucket, report.Snapshots),
+		Cards: []display.HTMLCard{
+			{Label: "Estimated cost", Value: fmt.Sprintf("$%.2f", report.Cost)},
+			{Label: "Instance hours", Value: fmt.Sprintf("%.1f", report.InstanceHours)},
+			{Label: "Snapshots", Value: strconv.Itoa(report.Snapshots)},
+			{Label: "Gaps", Value: strconv.Itoa(len(report.Gaps)), Alert: len(report.Gaps) > 0},
+		},
+		Notes: []string{
+			"Each snapshot is assumed to hold until the next one, for at most --max-gap. Highlighted buckets are not fully covered by snapshots, so their cost is understated.",
+			"Costs are estimates from on-demand instance prices.",
+		},
+	}
+
+	buckets := display.HTMLChart{Title: "Cost by " + report.Bucket}
+	totals := display.HTMLTable{
+		Title: "Buckets",
+		Columns: []display.HTMLColumn{
+			{Header: "Bucket"}, {Header: "Coverage", Numeric: true}, {Header: "Instance Hours", Numeric: true}, {Header: "Cost", Numeric: true},
+		},
+	}
+	for _, bucket := range report.Buckets {
+		label := bucketLabel(bucket.Start, report.Bucket)
+		buckets.Bars = append(buckets.Bars, display.HTMLBar{Label: label, Value: bucket.Cost, Text: fmt.Sprintf("$%.2f", bucket.Cost)})
+		totals.Rows = append(totals.Rows, display.HTMLRow{
+			Highlight: bucket.Coverage < 1,
+			Cells: []display.HTMLCell{
+				{Text: label, Sort: bucket.Start.Format(time.RFC3339)},
+				{Text: formatCoverage(bucket.Coverage), Sort: strconv.FormatFloat(bucket.Coverage, 'f', -1, 64)},
+				{Text: fmt.Sprintf("%.1f", bucket.InstanceHours)},
+				{Text: fmt.Sprintf("$%.2f", bucket.Cost), Sort: strconv.FormatFloat(bucket.Cost, 'f', -1, 64)},
+			},
+		})
+	}
+
+	usage := display.HTMLTable{
+		Title: "Usage",
+		Columns: []display.HTMLColumn{
+			{Header: "Bucket"}, {Header: "Type"}, {Header: "Instance Type"}, {Header: "Owner"}, {Header: "Instance Hours", Numeric: true}, {Header: "Cost", Numeric: true},
+		},
+	}
+	byOwner := make(map[string]float64)
+	byInstanceType := make(map[string]float64)
+	for _, row := range report.Rows {
+		byOwner[row.Owner] += row.Cost
+		byInstanceType[row.InstanceType] += row.Cost
+		usage.Rows = append(usage.Rows, display.HTMLRow{Cells: []display.HTMLCell{
+			{Text: bucketLabel(row.Bucket, report.Bucket), Sort: row.Bucket.Format(time.RFC3339)},
+			{Text: row.ResourceType},
+			{Text: row.InstanceType},
+			{Text: row.Owner},
+			{Text: fmt.Sprintf("%.1f", row.InstanceHours)},
+			{Text: fmt.Sprintf("$%.2f", row.Cost), Sort: strconv.FormatFloat(row.Cost, 'f', -1, 64)},
+		}})
+	}
+
+	page.Charts = []display.HTMLChart{buckets, costByKey("Cost by owner", byOwner), costByKey("Cost by instance type", byInstanceType)}
+	page.Tables = []display.HTMLTable{usage, totals}
+
+	if len(report.Gaps) > 0 {
+		gaps := display.HTMLTable{
+			Title:   "Gaps",
+			Columns: []display.HTMLColumn{{Header: "Region"}, {Header: "Profile"}, {Header: "Account"}, {Header: "Start"}, {Header: "End"}, {Header: "Duration", Numeric: true}},
+		}
+		for _, gap := range report.Gaps {
+			gaps.Rows = append(gaps.Rows, display.HTMLRow{Cells: []display.HTMLCell{
+				{Text: gap.Region},
+				{Text: gap.Profile},
+				{Text: gap.Account},
+				{Text: gap.Start.Local().Format("2006-01-02 15:04"), Sort: gap.Start.Format(time.RFC3339)},
+				{Text: gap.End.Local().Format("2006-01-02 15:04"), Sort: gap.End.Format(time.RFC3339)},
+				{Text: gap.End.Sub(gap.Start).Round(time.Minute).String(), Sort: strconv.FormatFloat(gap.End.Sub(gap.Start).Seconds(), 'f', 0, 64)},
+			}})
+		}
+		page.Tables = append(page.Tables, gaps)
+		page.Warnings = []string{fmt.Sprintf("%d gap(s) without snapshots are not included in the totals.", len(report.Gaps))}
+	}
+	return page
+}
+
+// costByKey charts costs keyed by e.g. owner, largest first
+func costByKey(title string, costs map[string]float64) display.HTMLChart {
+	chart := display.HTMLChart{Title: title}
+	for key, cost := range costs {
+		chart.Bars = append(chart.Bars, display.HTMLBar{Label: key, Value: cost, Text: fmt.Sprintf("$%.2f", cost)})
+	}
+	sort.Slice(chart.Bars, func(i, j int) bool {
+		if chart.Bars[i].Value != chart.Bars[j].Value {
+			return chart.Bars[i].Value > chart.Bars[j].Value
+		}
+		return chart.Bars[i].Label < chart.Bars[j].Label
+	})
+	return chart
+}
+
+// formatCoverage formats a 0 to 1 fraction as a percentage, marking incomplete buckets
+func formatCoverage(coverage float64) string {
+	if coverage < 1 {
+		return fmt.Sprintf("%.0f%% !", math.Floor(coverage*100))
+	}
+	return "100%"
+}
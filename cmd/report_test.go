@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/history"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeHistory saves an hourly snapshot of one endpoint for the last 6 hours, skipping 2 of them
+func writeHistory(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(path)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	endpoint := display.ResourceInfo{ResourceType: "Endpoint", Name: "prod", Region: "us-west-2", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 2, HourlyCost: 0.23, Tags: map[string]string{"team": "ml"}}
+	now := time.Now()
+	for _, hoursAgo := range []int{6, 5, 2, 1} {
+		snapshot := history.NewSnapshot(now.Add(-time.Duration(hoursAgo)*time.Hour), "us-west-2", "", []display.ResourceInfo{endpoint}, nil)
+		assert.NoError(t, store.Append(snapshot))
+	}
+	return path
+}
+
+func TestReport_Unit(t *testing.T) {
+	path := writeHistory(t)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"report", "--since", "1d", "--bucket", "hour", "--max-gap", "90m", "--owner-tag", "team", "-j", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+
+	var report history.Report
+	assert.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, history.BucketHour, report.Bucket)
+	assert.Equal(t, 4, report.Snapshots)
+	// Each snapshot holds until the next one or for 90 minutes: 4.5 hours of 2 instances
+	assert.InDelta(t, 9, report.InstanceHours, 0.01)
+	assert.InDelta(t, 4.5*0.23, report.Cost, 0.01)
+	for _, row := range report.Rows {
+		assert.Equal(t, "ml", row.Owner)
+	}
+	// Before the first snapshot and between 3.5 and 2 hours ago
+	if assert.Len(t, report.Gaps, 2) {
+		assert.InDelta(t, 1.5, report.Gaps[1].End.Sub(report.Gaps[1].Start).Hours(), 0.01)
+	}
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"report", "--history-file", path, "--max-gap", "90m", "--bucket", "week"}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "by week, 4 snapshots")
+	assert.Regexp(t, `Endpoint\s+ml\.m5\.large\s+\(none\)`, out)
+	assert.Regexp(t, `Total\s+9\.0\s+\$1\.0[34]`, out)
+	assert.Contains(t, out, "Gaps without snapshots, not included in the totals:")
+	assert.Contains(t, out, "us-west-2: ")
+	assert.Regexp(t, `\d+% !`, out)
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"report", "--csv", "--since", "12h", "--bucket", "week", "--history-file", path}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bucket", "resource_type", "instance_type", "owner", "instance_hours", "estimated_cost", "bucket_coverage"}, records[0])
+	assert.NotEmpty(t, records[1:])
+}
+
+func TestReportErrors_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "invalid bucket", args: []string{"report", "--bucket", "month"}, want: `invalid bucket "month"`},
+		{name: "invalid since", args: []string{"report", "--since", "last week"}, want: "invalid --since"},
+		{name: "invalid max gap", args: []string{"report", "--max-gap", "0s"}, want: "invalid --max-gap"},
+		{name: "csv and json", args: []string{"report", "--csv", "--json"}, want: "--csv and --json cannot be used together"},
+		{name: "arguments", args: []string{"report", "7d"}, want: "unknown command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, append(tt.args, "--history-file", path), new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestReportConfig_Unit(t *testing.T) {
+	// Report settings in the configuration file don't break other commands
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("bucket: week\n"), 0o600))
+	historyFile := writeHistory(t)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"report", "-j", "--config", configPath, "--history-file", historyFile}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	var report history.Report
+	assert.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, history.BucketWeek, report.Bucket)
+
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"history", "list", "--config", configPath, "--history-file", historyFile}, new(MockSageMakerClient))
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "us-west-2")
+}
@@ -3,12 +3,26 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"mohua/internal/awsconfig"
+	"mohua/internal/config"
 	"mohua/internal/display"
+	"mohua/internal/history"
+	"mohua/internal/logging"
+	"mohua/internal/notify"
+	"mohua/internal/pricing"
+	"mohua/internal/ratelimit"
+	"mohua/internal/retry"
 	"mohua/internal/sagemaker"
 )
 
@@ -21,6 +35,56 @@ type ResourceResult struct {
 var (
 	region    string
 	jsonOutput bool
+	groupBy    string
+	timeFormat string
+	colorMode  string
+	statusColors string
+	statuses    []string
+	allStatuses bool
+	namePattern         string
+	instanceTypePattern string
+	olderThan           string
+	newerThan           string
+	userProfile         string
+	domainID            string
+	rateLimit           float64
+	rateBurst           int
+	verbose             bool
+	debug               bool
+	debugSDK            bool
+	logFormat           string
+	endpointURL         string
+	recordDir           string
+	replayDir           string
+	anonymize           bool
+	timeout             time.Duration
+	callTimeout         time.Duration
+	configPath          string
+	viewName            string
+	profile             string
+	tableColumns        []string
+	saveHistory         bool
+	historyFile         string
+	historyRetention    string
+	maxHourlyCost       float64
+	maxAge              string
+	maxCount            []string
+	notifySlack         []string
+	notifyTeams         []string
+	notifyWebhook       []string
+	notifyTemplate      string
+	notifyOn            []string
+	notifyRepeat        string
+	notifyState         string
+	outputFormat        string
+)
+
+// Configuration resolved by loadConfiguration before any command runs
+var (
+	configFile     = &config.File{}
+	configSettings []config.Setting
+	// prices is the pricing table with the configuration file's overrides applied
+	prices = pricing.Default
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -30,37 +94,364 @@ var rootCmd = &cobra.Command{
 	Long: `A monitoring tool for AWS SageMaker that helps track running compute resources
 and their associated costs.`,
 	SilenceUsage:                    true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfiguration(cmd); err != nil {
+			return err
+		}
+		return resolveOutputFormat(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-
-		// Create SageMaker client
-		client, err := sagemaker.NewClient(region)
+		if err := validateDisplayFlags(); err != nil {
+			return err
+		}
+		thresholds, err := buildThresholds()
+		if err != nil {
+			return err
+		}
+		notifier, err := newNotifier()
 		if err != nil {
-			return fmt.Errorf("failed to create SageMaker client: %w", err)
+			return err
+		}
+		client, filter, err := newRunClient()
+		if err != nil {
+			return err
 		}
 
-		return runMonitor(client)
+		printer := newPrinter()
+		printer.SetThresholds(thresholds)
+		ctx, cancel := runContext(timeout)
+		defer cancel()
+		err = runMonitor(ctx, client, filter, printer, display.TagKey(groupBy) != "")
+		// Breached thresholds are what notifications are for; other errors mean nothing was listed
+		if notifier != nil && (err == nil || ExitCode(err) == ExitCodeThresholds) {
+			sendNotifications(notifier, client.GetRegion(), printer)
+		}
+		return err
 	},
 }
 
+// validateDisplayFlags checks the flags controlling the table and JSON output
+func validateDisplayFlags() error {
+	if groupBy != "" {
+		if err := display.ValidateGroupBy(groupBy); err != nil {
+			return err
+		}
+	}
+	if err := display.ValidateTimeFormat(timeFormat); err != nil {
+		return err
+	}
+	if err := display.ValidateColorMode(colorMode); err != nil {
+		return err
+	}
+	if _, err := display.ParsePalette(statusColors); err != nil {
+		return err
+	}
+	if err := display.ValidateColumns(tableColumns); err != nil {
+		return err
+	}
+	return nil
+}
+
+// buildThresholds validates the threshold flags and converts them into display.Thresholds
+func buildThresholds() (display.Thresholds, error) {
+	if maxHourlyCost < 0 {
+		return display.Thresholds{}, fmt.Errorf("--max-hourly-cost must not be negative")
+	}
+	thresholds := display.Thresholds{MaxHourlyCost: maxHourlyCost}
+	if maxAge != "" {
+		d, err := parseDuration(maxAge)
+		if err != nil {
+			return display.Thresholds{}, fmt.Errorf("invalid --max-age: %w", err)
+		}
+		thresholds.MaxAge = d
+	}
+	limits, err := display.ParseMaxCount(maxCount)
+	if err != nil {
+		return display.Thresholds{}, err
+	}
+	thresholds.MaxCount = limits
+	return thresholds, nil
+}
+
+// violationsError returns an ExitError when any threshold was breached
+func violationsError(violations []display.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ExitError{
+		Code: ExitCodeThresholds,
+		Err:  fmt.Errorf("%d threshold violation(s)", len(violations)),
+	}
+}
+
+// newRunClient validates the filter, client and logging flags, sets up logging and creates
+// the SageMaker client for a run
+func newRunClient() (sagemaker.Client, sagemaker.Filter, error) {
+	return newRegionClient(region)
+}
+
+// newRegionClient is newRunClient for the given region rather than --region
+func newRegionClient(clientRegion string) (sagemaker.Client, sagemaker.Filter, error) {
+	filter, err := buildFilter(time.Now())
+	if err != nil {
+		return nil, sagemaker.Filter{}, err
+	}
+	if recordDir != "" && replayDir != "" {
+		return nil, sagemaker.Filter{}, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	if anonymize && recordDir == "" {
+		return nil, sagemaker.Filter{}, fmt.Errorf("--anonymize requires --record")
+	}
+	if _, err := parseRetention(historyRetention); err != nil {
+		return nil, sagemaker.Filter{}, err
+	}
+	if timeout < 0 || callTimeout < 0 {
+		return nil, sagemaker.Filter{}, fmt.Errorf("--timeout and --call-timeout must not be negative")
+	}
+	if err := logging.ValidateFormat(logFormat); err != nil {
+		return nil, sagemaker.Filter{}, err
+	}
+	slog.SetDefault(logging.New(os.Stderr, logging.Level(verbose, debug || debugSDK), logFormat))
+	endpoint := resolveEndpointURL()
+	if endpoint != "" {
+		if err := awsconfig.ValidateEndpointURL(endpoint); err != nil {
+			return nil, sagemaker.Filter{}, err
+		}
+	}
+
+	// Create SageMaker client
+	options := []sagemaker.Option{
+		sagemaker.WithRateLimit(ratelimit.Config{
+			RequestsPerSecond: rateLimit,
+			Burst:             rateBurst,
+		}),
+		sagemaker.WithCallTimeout(callTimeout),
+		sagemaker.WithRetryHook(logRetry),
+		sagemaker.WithSDKLog(debugSDK),
+		sagemaker.WithEndpointURL(endpoint),
+		sagemaker.WithProfile(profile),
+		sagemaker.WithStaticCredentials(awsconfig.StaticCredentialsFromEnv()),
+	}
+	if recordDir != "" {
+		options = append(options, sagemaker.WithRecording(recordDir, anonymize))
+	}
+	if replayDir != "" {
+		options = append(options, sagemaker.WithReplay(replayDir))
+	}
+	client, err := sagemaker.NewClient(clientRegion, options...)
+	if err != nil {
+		return nil, sagemaker.Filter{}, fmt.Errorf("failed to create SageMaker client: %w", err)
+	}
+	return client, filter, nil
+}
+
+// newPrinter creates the printer configured by the display flags, which must have been
+// checked with validateDisplayFlags
+func newPrinter() *display.Printer {
+	printer := display.NewPrinter(jsonOutput)
+	printer.SetFormat(outputFormat)
+	printer.SetGroupBy(groupBy)
+	printer.SetColumns(tableColumns)
+	printer.SetColorMode(colorMode)
+	palette, _ := display.ParsePalette(statusColors)
+	printer.SetPalette(palette)
+	return printer
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Configuration file (env "+config.EnvConfig+", default "+config.DefaultPath()+")")
+	rootCmd.PersistentFlags().StringVar(&viewName, config.ViewFlag, "", "Apply a named view from the configuration file, e.g. gpu-waste")
 	rootCmd.PersistentFlags().StringVarP(&region, "region", "r", "", "AWS region (optional, defaults to AWS CLI configuration)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "AWS shared config profile (optional, defaults to AWS_PROFILE)")
 	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", display.OutputTable, "Output format: table, json, html (a self-contained page, e.g. -o html > report.html) or yaml (describe only)")
+	rootCmd.PersistentFlags().StringSliceVar(&tableColumns, "columns", display.DefaultColumns, "Table columns, e.g. name,status,instance,hourly-cost")
+	rootCmd.PersistentFlags().StringVar(&groupBy, "group-by", "", "Summarize by type, instance-type, user-profile, region or tag:<key>")
+	rootCmd.PersistentFlags().StringVar(&timeFormat, "time-format", display.TimeFormatRelative, "Running time format: relative, iso (ISO 8601 duration) or seconds")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", display.ColorAuto, "Colorize table output: auto, always or never (auto honors NO_COLOR and TTY detection)")
+	rootCmd.PersistentFlags().StringVar(&statusColors, "status-colors", "", "Override status colors, e.g. Pending=cyan,Failed=magenta")
+	rootCmd.PersistentFlags().StringSliceVar(&statuses, "status", nil, "Only list resources with this status (repeatable, defaults to InService)")
+	rootCmd.PersistentFlags().BoolVar(&allStatuses, "all-statuses", false, "List resources in every status")
+	rootCmd.PersistentFlags().StringVar(&namePattern, "name", "", "Only list resources whose name matches a glob, or a regular expression prefixed with re:")
+	rootCmd.PersistentFlags().StringVar(&instanceTypePattern, "instance-type", "", "Only list resources whose instance type matches a glob, e.g. ml.p*")
+	rootCmd.PersistentFlags().StringVar(&olderThan, "older-than", "", "Only list resources created more than this long ago, e.g. 3d or 12h")
+	rootCmd.PersistentFlags().StringVar(&newerThan, "newer-than", "", "Only list resources created less than this long ago, e.g. 3d or 12h")
+	rootCmd.PersistentFlags().StringVar(&userProfile, "user-profile", "", "Only list Studio apps owned by this user profile")
+	rootCmd.PersistentFlags().StringVar(&domainID, "domain", "", "Only list Studio apps in this domain ID")
+	rootCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", ratelimit.DefaultConfig.RequestsPerSecond, "Maximum SageMaker API requests per second per profile and region (0 disables)")
+	rootCmd.PersistentFlags().IntVar(&rateBurst, "rate-burst", ratelimit.DefaultConfig.Burst, "Maximum burst of SageMaker API requests above --rate-limit")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 5*time.Minute, "Stop the whole run after this long and print partial results (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&callTimeout, "call-timeout", 30*time.Second, "Abort and retry a single SageMaker API request after this long (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", "Send AWS requests to this URL instead of AWS, e.g. a local stand-in (env "+awsconfig.EnvEndpointURL+")")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Save every SageMaker request and response to this directory, e.g. to attach to a bug report")
+	rootCmd.PersistentFlags().BoolVar(&anonymize, "anonymize", false, "Replace account IDs, resource names, URLs and tag values in the recording with fake ones (requires --record)")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Serve SageMaker responses from a directory written by --record instead of calling AWS")
+	rootCmd.PersistentFlags().Float64Var(&maxHourlyCost, "max-hourly-cost", 0, "Fail with exit code 3 when the estimated hourly cost of the listed resources exceeds this many USD (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&maxAge, "max-age", "", "Fail with exit code 3 when a billed resource has been running longer than this, e.g. 72h or 3d")
+	rootCmd.PersistentFlags().StringSliceVar(&maxCount, "max-count", nil, "Fail with exit code 3 when more resources of a type are listed, e.g. notebook=5 (repeatable; endpoint, notebook or studio)")
+	rootCmd.PersistentFlags().BoolVar(&saveHistory, "save-history", false, "Append a snapshot of the listed resources to the local history")
+	rootCmd.PersistentFlags().StringVar(&historyFile, "history-file", "", "History database (default "+history.DefaultPath()+")")
+	rootCmd.PersistentFlags().StringVar(&historyRetention, "history-retention", "30d", "Delete history snapshots older than this when saving, e.g. 90d (0 keeps everything)")
+	rootCmd.PersistentFlags().StringSliceVar(&notifySlack, "notify-slack", nil, "Post alerts to this Slack-compatible incoming webhook URL (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&notifyTeams, "notify-teams", nil, "Post alerts to this Microsoft Teams incoming webhook URL (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&notifyWebhook, "notify-webhook", nil, "Post alerts as JSON to this URL (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&notifyOn, "notify-on", []string{notify.KindViolation, notify.KindNewGPU}, "Alert on threshold violations, new-gpu resources or both")
+	rootCmd.PersistentFlags().StringVar(&notifyTemplate, "notify-template", "", "Go template of the alert message text, or @file to read it from a file")
+	rootCmd.PersistentFlags().StringVar(&notifyRepeat, "notify-repeat", "24h", "Alert again about a condition still present after this long (0 alerts only once)")
+	rootCmd.PersistentFlags().StringVar(&notifyState, "notify-state", "", "File remembering sent alerts (default "+notify.DefaultStatePath()+")")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log configuration, API calls, retries and collector timing on stderr")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Log everything --verbose does plus rate limiter waits")
+	rootCmd.PersistentFlags().BoolVar(&debugSDK, "debug-sdk", false, "Implies --debug and also logs raw AWS SDK requests and responses, with signed headers redacted")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Log format: text or json")
+	// Subcommand flags are registered here too, so that every Execute, including each test's,
+	// starts from their defaults
+	addReportFlags()
+	addDigestFlags()
+	addTUIFlags()
 	
 	return rootCmd.Execute()
 }
 
-func runMonitor(client sagemaker.Client) error {
-	ctx := context.Background()
+// loadConfiguration fills every flag not given on the command line from its MOHUA_* environment
+// variable, the selected view or the configuration file, in that order
+func loadConfiguration(cmd *cobra.Command) error {
+	path, explicit := config.Path(configPath)
+	file, err := config.Load(path, explicit)
+	if err != nil {
+		return err
+	}
+	// Settings for another command's flags, e.g. report's --since, are not errors
+	settings, err := config.Apply(cmd.Flags(), file.Without(otherCommandFlags(cmd)), "config")
+	if err != nil {
+		return err
+	}
+
+	configFile, configSettings = file, settings
+	prices = pricing.Default.WithOverrides(file.Pricing)
+	return nil
+}
+
+// resolveOutputFormat reconciles --output with its --json shorthand, so that commands can keep
+// checking jsonOutput; YAML is only available to describe
+func resolveOutputFormat(cmd *cobra.Command) error {
+	if err := display.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if outputFormat == display.OutputYAML && cmd != describeCmd {
+		return fmt.Errorf("%s does not support --output yaml", cmd.CommandPath())
+	}
+	if jsonOutput {
+		if outputFormat == display.OutputHTML || outputFormat == display.OutputYAML {
+			return fmt.Errorf("--json and --output %s cannot be used together", outputFormat)
+		}
+		outputFormat = display.OutputJSON
+	}
+	jsonOutput = outputFormat == display.OutputJSON
+	return nil
+}
+
+// rejectHTMLOutput fails commands that have no HTML output
+func rejectHTMLOutput(cmd *cobra.Command) error {
+	if outputFormat == display.OutputHTML {
+		return fmt.Errorf("%s does not support --output html", cmd.CommandPath())
+	}
+	return nil
+}
+
+// otherCommandFlags returns the names of the flags that only other commands than cmd have
+func otherCommandFlags(cmd *cobra.Command) map[string]bool {
+	names := make(map[string]bool)
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+			if cmd.Flags().Lookup(f.Name) == nil {
+				names[f.Name] = true
+			}
+		})
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(cmd.Root())
+	return names
+}
+
+// resolveEndpointURL returns the --endpoint-url flag, falling back to MOHUA_ENDPOINT_URL
+func resolveEndpointURL() string {
+	if endpointURL != "" {
+		return endpointURL
+	}
+	return os.Getenv(awsconfig.EnvEndpointURL)
+}
+
+// buildFilter validates the filter flags and converts them into a sagemaker.Filter
+func buildFilter(now time.Time) (sagemaker.Filter, error) {
+	if allStatuses && len(statuses) > 0 {
+		return sagemaker.Filter{}, fmt.Errorf("--status and --all-statuses cannot be used together")
+	}
+
+	filter := sagemaker.Filter{
+		Statuses:     statuses,
+		AllStatuses:  allStatuses,
+		Name:         namePattern,
+		InstanceType: instanceTypePattern,
+		UserProfile:  userProfile,
+		DomainID:     domainID,
+	}
+	if olderThan != "" {
+		d, err := parseDuration(olderThan)
+		if err != nil {
+			return sagemaker.Filter{}, fmt.Errorf("invalid --older-than: %w", err)
+		}
+		filter.CreatedBefore = now.Add(-d)
+	}
+	if newerThan != "" {
+		d, err := parseDuration(newerThan)
+		if err != nil {
+			return sagemaker.Filter{}, fmt.Errorf("invalid --newer-than: %w", err)
+		}
+		filter.CreatedAfter = now.Add(-d)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return sagemaker.Filter{}, err
+	}
+	return filter, nil
+}
+
+// runContext returns the context bounding the whole run: it ends after the timeout, if any,
+// or on the first Ctrl-C; a second Ctrl-C terminates the process immediately
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	return tctx, func() {
+		cancel()
+		stop()
+	}
+}
 
+// runMonitor lists every resource type concurrently and prints the results with printer,
+// fetching the tags of every resource when fetchTags is set
+func runMonitor(ctx context.Context, client sagemaker.Client, filter sagemaker.Filter, printer *display.Printer, fetchTags bool) error {
 	// Validate AWS configuration
 	hasConfiguredResources, err := client.ValidateConfiguration(ctx)
 	if err != nil {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Create printer for output
-	printer := display.NewPrinter(jsonOutput)
+	if saveHistory {
+		// Saved on every return, so partial and empty runs are recorded too
+		defer saveSnapshot(client.GetRegion(), printer)
+	}
 
 	// If no resources are configured, print message and return
 	if !hasConfiguredResources {
@@ -79,19 +470,34 @@ func runMonitor(client sagemaker.Client) error {
 
 	go func() {
 		defer wg.Done()
-		endpoints, err := client.ListEndpoints(ctx)
+		start := time.Now()
+		endpoints, err := client.ListEndpoints(ctx, filter)
+		if err == nil && fetchTags {
+			attachTags(ctx, client, endpoints)
+		}
+		logCollector("endpoints", start, len(endpoints), err)
 		endpointsChan <- ResourceResult{Resources: endpoints, Error: err}
 	}()
 
 	go func() {
 		defer wg.Done()
-		notebooks, err := client.ListNotebooks(ctx)
+		start := time.Now()
+		notebooks, err := client.ListNotebooks(ctx, filter)
+		if err == nil && fetchTags {
+			attachTags(ctx, client, notebooks)
+		}
+		logCollector("notebooks", start, len(notebooks), err)
 		notebooksChan <- ResourceResult{Resources: notebooks, Error: err}
 	}()
 
 	go func() {
 		defer wg.Done()
-		apps, err := client.ListStudioApps(ctx)
+		start := time.Now()
+		apps, err := client.ListStudioApps(ctx, filter)
+		if err == nil && fetchTags {
+			attachTags(ctx, client, apps)
+		}
+		logCollector("studio apps", start, len(apps), err)
 		appsChan <- ResourceResult{Resources: apps, Error: err}
 	}()
 
@@ -105,12 +511,26 @@ func runMonitor(client sagemaker.Client) error {
 
 	// Track if any resources were found and collect errors
 	resourceFound := false
+	// incomplete is set when a collector was cut short by the run's deadline or an interrupt
+	incomplete := false
 	var firstError error
 
 	// Process endpoints
-	if result := <-endpointsChan; result.Error != nil {
+	result := <-endpointsChan
+	if result.Error != nil {
+		reportCollectorError(ctx, printer, "Endpoint", result.Error)
+
 		// Check if the error is retryable
-		if retryableErr, ok := result.Error.(*sagemaker.RetryableError); ok {
+		var openErr *retry.CircuitOpenError
+		var retryableErr *sagemaker.RetryableError
+		if ctx.Err() != nil {
+			// The run timed out or was interrupted; keep whatever was collected
+			incomplete = true
+			fmt.Fprintf(os.Stderr, "Stopped listing endpoints: %v\n", ctx.Err())
+		} else if errors.As(result.Error, &openErr) {
+			// The region's circuit breaker is open; report it, but don't stop execution
+			fmt.Fprintf(os.Stderr, "Region %s degraded, skipped listing endpoints: %v\n", client.GetRegion(), openErr)
+		} else if errors.As(result.Error, &retryableErr) {
 			// Log the retryable error, but don't stop execution
 			fmt.Fprintf(os.Stderr, "Retryable error listing endpoints: %v\n", retryableErr)
 		} else {
@@ -118,26 +538,34 @@ func runMonitor(client sagemaker.Client) error {
 				firstError = fmt.Errorf("failed to list endpoints: %w", result.Error)
 			}
 		}
-	} else if len(result.Resources) > 0 {
+	}
+	// Resources collected before a failure, e.g. earlier pages, are still printed
+	if len(result.Resources) > 0 {
 		if !resourceFound {
 			printer.PrintHeader()
 			resourceFound = true
 		}
 		for _, endpoint := range result.Resources {
-			printer.PrintResource(display.ResourceInfo{
-				ResourceType:  "Endpoint",
-				Name:         endpoint.Name,
-				Status:       endpoint.Status,
-				InstanceType: endpoint.InstanceType,
-				RunningTime:  time.Since(endpoint.CreationTime).String(),
-			})
+			printer.PrintResource(toDisplayResource("Endpoint", endpoint.Name, endpoint, client.GetRegion()))
 		}
 	}
 
 	// Process notebooks
-	if result := <-notebooksChan; result.Error != nil {
+	result = <-notebooksChan
+	if result.Error != nil {
+		reportCollectorError(ctx, printer, "Notebook", result.Error)
+
 		// Check if the error is retryable
-		if retryableErr, ok := result.Error.(*sagemaker.RetryableError); ok {
+		var openErr *retry.CircuitOpenError
+		var retryableErr *sagemaker.RetryableError
+		if ctx.Err() != nil {
+			// The run timed out or was interrupted; keep whatever was collected
+			incomplete = true
+			fmt.Fprintf(os.Stderr, "Stopped listing notebooks: %v\n", ctx.Err())
+		} else if errors.As(result.Error, &openErr) {
+			// The region's circuit breaker is open; report it, but don't stop execution
+			fmt.Fprintf(os.Stderr, "Region %s degraded, skipped listing notebooks: %v\n", client.GetRegion(), openErr)
+		} else if errors.As(result.Error, &retryableErr) {
 			// Log the retryable error, but don't stop execution
 			fmt.Fprintf(os.Stderr, "Retryable error listing notebooks: %v\n", retryableErr)
 		} else {
@@ -145,26 +573,34 @@ func runMonitor(client sagemaker.Client) error {
 				firstError = fmt.Errorf("failed to list notebooks: %w", result.Error)
 			}
 		}
-	} else if len(result.Resources) > 0 {
+	}
+	// Resources collected before a failure, e.g. earlier pages, are still printed
+	if len(result.Resources) > 0 {
 		if !resourceFound {
 			printer.PrintHeader()
 			resourceFound = true
 		}
 		for _, notebook := range result.Resources {
-			printer.PrintResource(display.ResourceInfo{
-				ResourceType:  "Notebook",
-				Name:         notebook.Name,
-				Status:       notebook.Status,
-				InstanceType: notebook.InstanceType,
-				RunningTime:  time.Since(notebook.CreationTime).String(),
-			})
+			printer.PrintResource(toDisplayResource("Notebook", notebook.Name, notebook, client.GetRegion()))
 		}
 	}
 
 	// Process Studio apps
-	if result := <-appsChan; result.Error != nil {
+	result = <-appsChan
+	if result.Error != nil {
+		reportCollectorError(ctx, printer, "Studio", result.Error)
+
 		// Check if the error is retryable
-		if retryableErr, ok := result.Error.(*sagemaker.RetryableError); ok {
+		var openErr *retry.CircuitOpenError
+		var retryableErr *sagemaker.RetryableError
+		if ctx.Err() != nil {
+			// The run timed out or was interrupted; keep whatever was collected
+			incomplete = true
+			fmt.Fprintf(os.Stderr, "Stopped listing studio apps: %v\n", ctx.Err())
+		} else if errors.As(result.Error, &openErr) {
+			// The region's circuit breaker is open; report it, but don't stop execution
+			fmt.Fprintf(os.Stderr, "Region %s degraded, skipped listing studio apps: %v\n", client.GetRegion(), openErr)
+		} else if errors.As(result.Error, &retryableErr) {
 			// Log the retryable error, but don't stop execution
 			fmt.Fprintf(os.Stderr, "Retryable error listing studio apps: %v\n", retryableErr)
 		} else {
@@ -172,27 +608,39 @@ func runMonitor(client sagemaker.Client) error {
 				firstError = fmt.Errorf("failed to list studio apps: %w", result.Error)
 			}
 		}
-	} else if len(result.Resources) > 0 {
+	}
+	// Resources collected before a failure, e.g. earlier pages, are still printed
+	if len(result.Resources) > 0 {
 		if !resourceFound {
 			printer.PrintHeader()
 			resourceFound = true
 		}
 		for _, app := range result.Resources {
-			printer.PrintResource(display.ResourceInfo{
-				ResourceType:  "Studio",
-				Name:         fmt.Sprintf("%s/%s", app.UserProfile, app.AppType),
-				Status:       app.Status,
-				InstanceType: app.InstanceType,
-				RunningTime:  time.Since(app.CreationTime).String(),
-			})
+			printer.PrintResource(toDisplayResource("Studio", fmt.Sprintf("%s/%s", app.UserProfile, app.AppType), app, client.GetRegion()))
 		}
 	}
 
-	// Return first error encountered if any
+	// Return first error encountered if any, after emitting whatever was collected
 	if firstError != nil {
+		if resourceFound || outputFormat != display.OutputTable {
+			printer.PrintFooter()
+		}
 		return firstError
 	}
 
+	// A deadline or interrupt keeps the partial results, but still fails the run
+	if incomplete {
+		if resourceFound {
+			printer.PrintFooter()
+		} else {
+			printer.PrintNoResources(client.GetRegion())
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s, results are incomplete", timeout)
+		}
+		return fmt.Errorf("interrupted, results are incomplete")
+	}
+
 	// If no resources found, print no resources message
 	if !resourceFound {
 		printer.PrintNoResources(client.GetRegion())
@@ -201,5 +649,252 @@ func runMonitor(client sagemaker.Client) error {
 
 	// Print footer if resources were found
 	printer.PrintFooter()
+	return violationsError(printer.Violations())
+}
+
+// saveSnapshot appends the printed resources to the history and prunes snapshots past the
+// retention; failures are reported but don't fail the run
+func saveSnapshot(region string, printer *display.Printer) {
+	snapshot := history.NewSnapshot(time.Now(), region, profile, printer.Resources(), printer.Errors())
+	if err := appendSnapshot(snapshot); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save history: %v\n", err)
+	}
+}
+
+func appendSnapshot(snapshot history.Snapshot) error {
+	store, err := history.Open(historyPath())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Append(snapshot); err != nil {
+		return err
+	}
+	// The retention was validated before the run
+	retention, _ := parseRetention(historyRetention)
+	if retention > 0 {
+		deleted, err := store.Prune(snapshot.Timestamp.Add(-retention))
+		if err != nil {
+			return err
+		}
+		slog.Debug("pruned history", "snapshots", deleted, "retention", retention)
+	}
+	slog.Info("saved history snapshot", "timestamp", snapshot.Timestamp, "resources", len(snapshot.Resources))
 	return nil
 }
+
+// historyPath returns the --history-file flag, falling back to the default location
+func historyPath() string {
+	if historyFile != "" {
+		return historyFile
+	}
+	return history.DefaultPath()
+}
+
+// parseRetention parses --history-retention, where 0 keeps every snapshot
+func parseRetention(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --history-retention: %w", err)
+	}
+	return d, nil
+}
+
+// newNotifier validates the notification flags and creates the notifier, or returns nil when
+// no webhook is configured
+func newNotifier() (*notify.Notifier, error) {
+	var sinks []notify.Sink
+	for _, group := range []struct {
+		kind string
+		urls []string
+	}{
+		{notify.SinkSlack, notifySlack},
+		{notify.SinkTeams, notifyTeams},
+		{notify.SinkWebhook, notifyWebhook},
+	} {
+		for _, url := range group.urls {
+			sink, err := notify.NewSink(group.kind, url)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		}
+	}
+	if err := notify.ValidateKinds(notifyOn); err != nil {
+		return nil, err
+	}
+	repeat, err := parseRetention(notifyRepeat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --notify-repeat %q: must be a duration or 0", notifyRepeat)
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	text := notifyTemplate
+	if path, ok := strings.CutPrefix(notifyTemplate, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --notify-template: %w", err)
+		}
+		text = string(data)
+	}
+	statePath := notifyState
+	if statePath == "" {
+		statePath = notify.DefaultStatePath()
+	}
+	retryConfig := notify.DefaultRetry
+	retryConfig.OnRetry = func(attempt int, err error, nextBackoff time.Duration) {
+		slog.Info("retrying notification", "attempt", attempt, "backoff", nextBackoff, "error", err)
+	}
+	return notify.New(notify.Config{
+		Sinks:     sinks,
+		Template:  text,
+		StatePath: statePath,
+		Profile:   profile,
+		Repeat:    repeat,
+		Retry:     retryConfig,
+	})
+}
+
+// sendNotifications alerts about the run's violations and new GPU resources; failures are
+// reported but don't fail the run
+func sendNotifications(notifier *notify.Notifier, region string, printer *display.Printer) {
+	var alerts []notify.Alert
+	for _, kind := range notifyOn {
+		switch kind {
+		case notify.KindViolation:
+			alerts = append(alerts, notify.ViolationAlerts(printer.Violations(), region)...)
+		case notify.KindNewGPU:
+			alerts = append(alerts, notify.NewGPUAlerts(printer.Resources())...)
+		}
+	}
+
+	// The run is not over yet when the --timeout expired, so notifications get their own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	// Conditions missing from a partial run may still hold, so they are only forgotten after a complete one
+	sent, err := notifier.Notify(ctx, region, alerts, len(printer.Errors()) == 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send notifications: %v\n", err)
+		return
+	}
+	slog.Info("sent notifications", "alerts", sent, "active", len(alerts))
+}
+
+// reportCollectorError records a listing failure for the JSON output and logs how many
+// attempts were made before giving up
+func reportCollectorError(ctx context.Context, printer *display.Printer, resourceType string, err error) {
+	info := display.ErrorInfo{
+		ResourceType: resourceType,
+		Message:      err.Error(),
+		TimedOut:     errors.Is(ctx.Err(), context.DeadlineExceeded),
+		Interrupted:  errors.Is(ctx.Err(), context.Canceled),
+	}
+	var retryErr *retry.Error
+	if errors.As(err, &retryErr) {
+		info.Classification = string(retryErr.Classification)
+		info.Attempts = retryErr.Attempts
+		info.ElapsedSeconds = retryErr.Elapsed.Seconds()
+	}
+	printer.PrintError(info)
+
+	if retryErr != nil {
+		slog.Info("listing failed",
+			"resourceType", resourceType,
+			"attempts", retryErr.Attempts,
+			"elapsed", retryErr.Elapsed,
+			"classification", string(retryErr.Classification),
+		)
+	}
+}
+
+// logRetry logs a retried SageMaker API call
+func logRetry(operation string, attempt int, err error, nextBackoff time.Duration) {
+	slog.Info("retrying sagemaker request",
+		"operation", operation,
+		"attempt", attempt,
+		"backoff", nextBackoff,
+		"error", err,
+	)
+}
+
+// logCollector logs how long a collector took and how many resources it returned
+func logCollector(name string, start time.Time, count int, err error) {
+	attrs := []any{"collector", name, "duration", time.Since(start), "resources", count}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.Info("collector finished", attrs...)
+}
+
+// toDisplayResource converts a SageMaker resource into its display form, including cost estimates
+func toDisplayResource(resourceType, name string, resource sagemaker.ResourceInfo, region string) display.ResourceInfo {
+	instanceCount := resource.InstanceCount
+	if instanceCount < 1 {
+		instanceCount = 1
+	}
+	now := time.Now()
+	var runningTime time.Duration
+	if !resource.CreationTime.IsZero() {
+		runningTime = now.Sub(resource.CreationTime)
+	}
+
+	// Stopped or failed resources are listed but contribute nothing to the cost totals
+	var instanceHours, hourlyCost float64
+	if pricing.IsBilled(resource.Status) {
+		instanceHours = runningTime.Hours() * float64(instanceCount)
+		hourlyCost = prices.HourlyCost(resource.InstanceType, instanceCount)
+	}
+
+	info := display.ResourceInfo{
+		ResourceType:   resourceType,
+		Name:           name,
+		Arn:            resource.Arn,
+		Status:         resource.Status,
+		InstanceType:   resource.InstanceType,
+		RunningTime:    display.FormatRunningTime(resource.CreationTime, now, timeFormat),
+		CreationTime:   resource.CreationTime.UTC().Truncate(time.Second),
+		RunningSeconds: int64(runningTime / time.Second),
+		InstanceCount:  instanceCount,
+		UserProfile:    resource.UserProfile,
+		Region:         region,
+		Tags:           resource.Tags,
+		InstanceHours:  instanceHours,
+		HourlyCost:     hourlyCost,
+	}
+	if resource.AppType != "" {
+		info.DomainID = resource.DomainID
+		info.SpaceName = resource.SpaceName
+		info.AppName = resource.Name
+	}
+	return info
+}
+
+// attachTags fetches tags for resources, describing Studio apps first as they are listed
+// without an ARN; failures are reported but not fatal
+func attachTags(ctx context.Context, client sagemaker.Client, resources []sagemaker.ResourceInfo) {
+	for i := range resources {
+		if resources[i].Arn == "" && resources[i].AppType != "" {
+			arn, err := client.StudioAppArn(ctx, resources[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to describe %s for its tags: %v\n", resources[i].Name, err)
+				continue
+			}
+			resources[i].Arn = arn
+		}
+		if resources[i].Arn == "" {
+			continue
+		}
+		tags, err := client.ListTags(ctx, resources[i].Arn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list tags for %s: %v\n", resources[i].Name, err)
+			continue
+		}
+		resources[i].Tags = tags
+	}
+}
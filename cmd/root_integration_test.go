@@ -3,86 +3,225 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
 	"github.com/stretchr/testify/assert"
+	"mohua/internal/display"
+	"mohua/internal/fakesagemaker"
 )
 
-func TestExecute_Integration(t *testing.T) {
-	skipIfNoAWSCredentials(t)
+// startFakeSageMaker points mohua at an in-process fake SageMaker with static test credentials,
+// so the real SDK wiring runs without network access or an AWS account
+func startFakeSageMaker(t *testing.T) *fakesagemaker.Server {
+	fixtures, err := fakesagemaker.LoadFixtures(filepath.Join("..", "internal", "fakesagemaker", "testdata", "fixtures.json"))
+	assert.NoError(t, err)
+
+	server := fakesagemaker.New(fixtures)
+	t.Cleanup(server.Close)
+
+	// Keep the machine's AWS configuration out of the tests
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("MOHUA_ENDPOINT_URL", server.URL())
+	t.Setenv("MOHUA_ACCESS_KEY_ID", "test")
+	t.Setenv("MOHUA_SECRET_ACCESS_KEY", "test")
+	return server
+}
 
-	// Reset command before test
+// executeWithArgs runs the root command with the real SageMaker client, returning its stdout
+func executeWithArgs(t *testing.T, args ...string) (string, error) {
+	// Reset command before each run
 	resetCommand()
 
+	// Save original args
+	oldArgs := os.Args
+	// Set up new args for test
+	os.Args = append([]string{"mohua"}, args...)
+
+	// Reset args after test
+	defer func() {
+		os.Args = oldArgs
+	}()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = Execute()
+	})
+	return out, err
+}
+
+// jsonOutputEnvelope is the subset of the JSON output checked by the integration tests
+type jsonOutputEnvelope struct {
+	Resources []struct {
+		ResourceType string            `json:"resourceType"`
+		Name         string            `json:"name"`
+		Status       string            `json:"status"`
+		Region       string            `json:"region"`
+		Tags         map[string]string `json:"tags"`
+	} `json:"resources"`
+	Summary *struct {
+		Groups []struct {
+			Key   string `json:"key"`
+			Count int    `json:"count"`
+		} `json:"groups"`
+	} `json:"summary"`
+	Errors []struct {
+		ResourceType string `json:"resourceType"`
+		TimedOut     bool   `json:"timedOut"`
+	} `json:"errors"`
+}
+
+func parseJSONOutput(t *testing.T, out string) jsonOutputEnvelope {
+	var envelope jsonOutputEnvelope
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope), out)
+	return envelope
+}
+
+func resourceNames(envelope jsonOutputEnvelope) []string {
+	var names []string
+	for _, r := range envelope.Resources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestExecute_Integration(t *testing.T) {
+	startFakeSageMaker(t)
+
 	t.Run("basic execution", func(t *testing.T) {
-		err := Execute()
+		out, err := executeWithArgs(t)
 		assert.NoError(t, err)
+		assert.Contains(t, out, "prod-classifier")
+		assert.Contains(t, out, "dev-notebook")
 	})
 }
 
 func TestExecuteWithFlags_Integration(t *testing.T) {
-	skipIfNoAWSCredentials(t)
+	startFakeSageMaker(t)
 
 	tests := []struct {
-		name    string
-		args    []string
-		wantErr bool
+		name     string
+		args     []string
+		expected []string
 	}{
 		{
-			name:    "with region flag",
-			args:    []string{"-r", "us-west-2"},
-			wantErr: false,
+			name:     "with json flag",
+			args:     []string{"-j"},
+			expected: []string{"prod-classifier", "dev-notebook", "alice/JupyterServer", "bob/JupyterLab"},
 		},
 		{
-			name:    "with json flag",
-			args:    []string{"-j"},
-			wantErr: false,
+			name:     "with region and json flags",
+			args:     []string{"-r", "us-west-2", "-j"},
+			expected: []string{"prod-classifier", "dev-notebook", "alice/JupyterServer", "bob/JupyterLab"},
 		},
 		{
-			name:    "with both flags",
-			args:    []string{"-r", "us-west-2", "-j"},
-			wantErr: false,
+			name:     "with all statuses",
+			args:     []string{"-j", "--all-statuses"},
+			expected: []string{"prod-classifier", "exp-ranker", "dev-notebook", "old-notebook", "alice/JupyterServer", "bob/JupyterLab"},
+		},
+		{
+			name:     "with status and name filters",
+			args:     []string{"-j", "--status", "Failed", "--name", "exp-*"},
+			expected: []string{"exp-ranker"},
+		},
+		{
+			name:     "with user profile filter",
+			args:     []string{"-j", "--user-profile", "bob"},
+			expected: []string{"bob/JupyterLab"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset command before each test
-			resetCommand()
+			out, err := executeWithArgs(t, tt.args...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resourceNames(parseJSONOutput(t, out)))
+		})
+	}
+}
 
-			// Save original args
-			oldArgs := os.Args
-			// Set up new args for test
-			os.Args = append([]string{"mohua"}, tt.args...)
+func TestExecuteWithRegion_Integration(t *testing.T) {
+	startFakeSageMaker(t)
 
-			// Reset args after test
-			defer func() {
-				os.Args = oldArgs
-			}()
+	out, err := executeWithArgs(t, "-r", "eu-central-1", "-j")
+	assert.NoError(t, err)
+	for _, r := range parseJSONOutput(t, out).Resources {
+		assert.Equal(t, "eu-central-1", r.Region)
+	}
+}
 
-			err := Execute()
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
+func TestExecuteWithTagGrouping_Integration(t *testing.T) {
+	startFakeSageMaker(t)
+
+	out, err := executeWithArgs(t, "-j", "--group-by", "tag:team")
+	assert.NoError(t, err)
+
+	envelope := parseJSONOutput(t, out)
+	assert.Equal(t, map[string]string{"team": "ml"}, envelope.Resources[0].Tags)
+	if assert.NotNil(t, envelope.Summary) {
+		var keys []string
+		for _, group := range envelope.Summary.Groups {
+			keys = append(keys, group.Key)
+		}
+		assert.ElementsMatch(t, []string{"ml", "research", "(none)"}, keys)
+	}
+}
+
+func TestExecuteWithPagination_Integration(t *testing.T) {
+	server := startFakeSageMaker(t)
+	server.SetPageSize(1)
+
+	out, err := executeWithArgs(t, "-j", "--all-statuses")
+	assert.NoError(t, err)
+	assert.Len(t, parseJSONOutput(t, out).Resources, 6)
+	assert.Equal(t, 2, server.Calls("ListEndpoints"))
+	assert.Equal(t, 2, server.Calls("ListNotebookInstances"))
+	assert.Equal(t, 2, server.Calls("ListApps"))
+}
+
+func TestExecuteWithThrottling_Integration(t *testing.T) {
+	server := startFakeSageMaker(t)
+	server.Throttle("ListNotebookInstances", 1)
+
+	// Throttled requests are retried and the run still succeeds
+	out, err := executeWithArgs(t, "-j")
+	assert.NoError(t, err)
+	assert.Contains(t, resourceNames(parseJSONOutput(t, out)), "dev-notebook")
+	assert.Greater(t, server.Calls("ListNotebookInstances"), 1)
+}
+
+func TestExecuteWithTimeout_Integration(t *testing.T) {
+	server := startFakeSageMaker(t)
+	server.SetLatency("ListApps", 5*time.Second)
+
+	// The slow collector is cut short, the others are still printed. A region no other test
+	// uses keeps the shared rate limiter from eating into the deadline.
+	out, err := executeWithArgs(t, "-r", "ap-south-1", "-j", "--timeout", "300ms")
+	assert.ErrorContains(t, err, "timed out")
+
+	envelope := parseJSONOutput(t, out)
+	assert.Equal(t, []string{"prod-classifier", "dev-notebook"}, resourceNames(envelope))
+	if assert.Len(t, envelope.Errors, 1) {
+		assert.Equal(t, "Studio", envelope.Errors[0].ResourceType)
+		assert.True(t, envelope.Errors[0].TimedOut)
 	}
 }
 
 func TestExecuteWithInvalidFlags_Integration(t *testing.T) {
-	skipIfNoAWSCredentials(t)
+	startFakeSageMaker(t)
 
 	tests := []struct {
 		name    string
 		args    []string
 		wantErr bool
 	}{
-		{
-			name:    "with invalid region",
-			args:    []string{"-r", "invalid-region"},
-			wantErr: true,
-		},
 		{
 			name:    "with empty region",
 			args:    []string{"-r", ""},
@@ -93,24 +232,16 @@ func TestExecuteWithInvalidFlags_Integration(t *testing.T) {
 			args:    []string{"--unknown"},
 			wantErr: true,
 		},
+		{
+			name:    "with invalid endpoint URL",
+			args:    []string{"--endpoint-url", "not a url"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset command before each test
-			resetCommand()
-
-			// Save original args
-			oldArgs := os.Args
-			// Set up new args for test
-			os.Args = append([]string{"mohua"}, tt.args...)
-
-			// Reset args after test
-			defer func() {
-				os.Args = oldArgs
-			}()
-
-			err := Execute()
+			_, err := executeWithArgs(t, tt.args...)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -119,3 +250,52 @@ func TestExecuteWithInvalidFlags_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteRecordReplay_Integration(t *testing.T) {
+	server := startFakeSageMaker(t)
+	dir := filepath.Join(t.TempDir(), "recording")
+
+	recorded, err := executeWithArgs(t, "-j", "--all-statuses", "--group-by", "tag:team", "--record", dir, "--anonymize")
+	assert.NoError(t, err)
+
+	// Replaying needs neither the server nor credentials and prints the same resources and
+	// groups, under their fake names and tag values
+	server.Close()
+	replayed, err := executeWithArgs(t, "-j", "--all-statuses", "--group-by", "tag:team", "--replay", dir)
+	assert.NoError(t, err)
+	live, anonymized := parseJSONOutput(t, recorded), parseJSONOutput(t, replayed)
+	assert.Len(t, resourceNames(anonymized), len(resourceNames(live)))
+	for _, name := range resourceNames(live) {
+		assert.NotContains(t, replayed, name)
+	}
+	if assert.NotNil(t, anonymized.Summary) && assert.NotNil(t, live.Summary) {
+		var liveCounts, anonymizedCounts []int
+		for _, group := range live.Summary.Groups {
+			liveCounts = append(liveCounts, group.Count)
+		}
+		for _, group := range anonymized.Summary.Groups {
+			anonymizedCounts = append(anonymizedCounts, group.Count)
+		}
+		assert.ElementsMatch(t, liveCounts, anonymizedCounts)
+	}
+}
+
+func TestExecuteDescribe_Integration(t *testing.T) {
+	startFakeSageMaker(t)
+
+	out, err := executeWithArgs(t, "describe", "endpoint", "prod-classifier", "--color", "never")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Endpoint prod-classifier (us-east-1)")
+	assert.Contains(t, out, "prod-classifier-config")
+	assert.Contains(t, out, "prod-classifier-model")
+
+	out, err = executeWithArgs(t, "describe", "notebook", "dev-notebook", "-o", "json")
+	assert.NoError(t, err)
+	var detail display.Detail
+	assert.NoError(t, json.Unmarshal([]byte(out), &detail))
+	assert.Equal(t, "dev-notebook", detail.Name)
+	assert.Equal(t, 5, detail.VolumeSizeGB)
+
+	_, err = executeWithArgs(t, "describe", "endpoint", "missing")
+	assert.ErrorContains(t, err, "failed to describe endpoint missing")
+}
@@ -1,20 +1,119 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"mohua/internal/config"
+	"mohua/internal/display"
+	"mohua/internal/pricing"
+	"mohua/internal/retry"
 	"mohua/internal/sagemaker"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 // resetCommand resets the root command and its flags to their initial state
 func resetCommand() {
-	rootCmd.ResetFlags()
+	resetFlags(rootCmd)
 	region = ""
 	jsonOutput = false
+	outputFormat = ""
+	groupBy = ""
+	timeFormat = ""
+	colorMode = ""
+	statusColors = ""
+	statuses = nil
+	allStatuses = false
+	namePattern = ""
+	instanceTypePattern = ""
+	olderThan = ""
+	newerThan = ""
+	userProfile = ""
+	domainID = ""
+	rateLimit = 0
+	rateBurst = 0
+	verbose = false
+	timeout = 0
+	callTimeout = 0
+	debug = false
+	debugSDK = false
+	logFormat = ""
+	endpointURL = ""
+	recordDir = ""
+	replayDir = ""
+	anonymize = false
+	configPath = ""
+	viewName = ""
+	profile = ""
+	tableColumns = nil
+	saveHistory = false
+	historyFile = ""
+	historyRetention = ""
+	maxHourlyCost = 0
+	maxAge = ""
+	maxCount = nil
+	notifySlack = nil
+	notifyTeams = nil
+	notifyWebhook = nil
+	notifyTemplate = ""
+	notifyOn = nil
+	notifyRepeat = ""
+	notifyState = ""
+	reportSince = ""
+	reportBucket = ""
+	reportMaxGap = ""
+	reportOwnerTag = ""
+	reportCSV = false
+	smtpHost = ""
+	smtpPort = 0
+	smtpUsername = ""
+	smtpPassword = ""
+	smtpStartTLS = false
+	digestFrom = ""
+	digestTo = nil
+	digestRoutes = nil
+	digestOwnerTag = ""
+	digestIdle = ""
+	digestDryRun = false
+	tuiRegions = nil
+	tuiRefresh = 0
+	configFile = &config.File{}
+	configSettings = nil
+	prices = pricing.Default
+}
+
+// resetFlags resets the flags of cmd and its subcommands, which otherwise keep the parent's
+// persistent flags merged during a previous run, including whether they were set
+func resetFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestMain(m *testing.M) {
+	// Keep the tests independent from the user's configuration file
+	dir, err := os.MkdirTemp("", "mohua-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("XDG_DATA_HOME", dir)
+	os.Unsetenv(config.EnvConfig)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
 }
 
 // mockExecute is a helper function that executes the command with a mock client
@@ -35,7 +134,7 @@ func mockExecute(t *testing.T, args []string, client sagemaker.Client) error {
 	// Store the original NewClient function
 	origNewClient := sagemaker.NewClient
 	// Replace it with our mock
-	sagemaker.NewClient = func(region string) (sagemaker.Client, error) {
+	sagemaker.NewClient = func(region string, options ...sagemaker.Option) (sagemaker.Client, error) {
 		return client, nil
 	}
 	// Restore the original function after the test
@@ -52,9 +151,9 @@ func TestExecute_Unit(t *testing.T) {
 	// Setup mock expectations
 	mockClient.On("GetRegion").Return("us-west-2")
 	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
-	mockClient.On("ListEndpoints", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-	mockClient.On("ListNotebooks", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-	mockClient.On("ListStudioApps", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
 
 	err := mockExecute(t, []string{}, mockClient)
 	assert.NoError(t, err)
@@ -69,9 +168,9 @@ func TestExecuteWithFlags_Unit(t *testing.T) {
 	// Setup mock expectations
 	mockClient.On("GetRegion").Return("us-west-2")
 	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
-	mockClient.On("ListEndpoints", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-	mockClient.On("ListNotebooks", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-	mockClient.On("ListStudioApps", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
 
 	tests := []struct {
 		name    string
@@ -116,9 +215,9 @@ func TestExecuteWithFlags_Unit(t *testing.T) {
 // 	// Setup mock expectations
 // 	mockClient.On("GetRegion").Return("us-west-2")
 // 	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
-// 	mockClient.On("ListEndpoints", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-// 	mockClient.On("ListNotebooks", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
-// 	mockClient.On("ListStudioApps", mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+// 	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+// 	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+// 	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
 
 // 	tests := []struct {
 // 		name    string
@@ -146,3 +245,413 @@ func TestExecuteWithFlags_Unit(t *testing.T) {
 // 	// Assert that all mock expectations were met
 // 	mockClient.AssertExpectations(t)
 // }
+
+func TestExecuteWithGroupBy_Unit(t *testing.T) {
+	t.Run("invalid group-by", func(t *testing.T) {
+		mockClient := new(MockSageMakerClient)
+		err := mockExecute(t, []string{"--group-by", "owner"}, mockClient)
+		assert.Error(t, err)
+		mockClient.AssertNotCalled(t, "ValidateConfiguration", mock.Anything)
+	})
+
+	t.Run("tag group-by fetches tags", func(t *testing.T) {
+		mockClient := new(MockSageMakerClient)
+		mockClient.On("GetRegion").Return("us-west-2")
+		mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+		mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+			{Name: "ep-1", Arn: "arn:ep-1", Status: "InService", InstanceType: "ml.t3.medium"},
+		}, nil)
+		mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+		mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+			{Name: "app-1", Status: "InService", UserProfile: "alice", AppType: "JupyterLab", DomainID: "d-1"},
+			{Name: "app-2", Status: "InService", UserProfile: "bob", AppType: "JupyterLab", DomainID: "d-1"},
+		}, nil)
+		mockClient.On("ListTags", mock.Anything, "arn:ep-1").Return(map[string]string{"team": "ml"}, nil)
+		// Studio apps are listed without an ARN, so it is described before their tags are listed
+		appArn := "arn:aws:sagemaker:us-west-2:123456789012:app/d-1/alice/jupyterlab/app-1"
+		mockClient.On("StudioAppArn", mock.Anything, mock.MatchedBy(func(app sagemaker.ResourceInfo) bool { return app.Name == "app-1" })).Return(appArn, nil)
+		mockClient.On("ListTags", mock.Anything, appArn).Return(map[string]string{"team": "research"}, nil)
+		// An app that cannot be described is still listed, without tags
+		mockClient.On("StudioAppArn", mock.Anything, mock.MatchedBy(func(app sagemaker.ResourceInfo) bool { return app.Name == "app-2" })).
+			Return("", errors.New("RecordNotFound"))
+
+		var err error
+		output := captureStdout(t, func() {
+			err = mockExecute(t, []string{"--group-by", "tag:team", "-j"}, mockClient)
+		})
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"arn": "`+appArn+`"`)
+		assert.Contains(t, output, `"team": "research"`)
+		assert.Contains(t, output, `"name": "bob/JupyterLab"`)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestExecuteWithInvalidDisplayFlags_Unit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "invalid time format", args: []string{"--time-format", "unix"}},
+		{name: "invalid color mode", args: []string{"--color", "sometimes"}},
+		{name: "invalid status colors", args: []string{"--status-colors", "Pending=orange"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSageMakerClient)
+			err := mockExecute(t, tt.args, mockClient)
+			assert.Error(t, err)
+			mockClient.AssertNotCalled(t, "ValidateConfiguration", mock.Anything)
+		})
+	}
+}
+
+func TestExecuteWithStatusFlags_Unit(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected sagemaker.Filter
+	}{
+		{
+			name:     "default lists running resources",
+			args:     []string{},
+			expected: sagemaker.Filter{},
+		},
+		{
+			name:     "repeated status flags",
+			args:     []string{"--status", "Failed", "--status", "Stopped"},
+			expected: sagemaker.Filter{Statuses: []string{"Failed", "Stopped"}},
+		},
+		{
+			name:     "all statuses",
+			args:     []string{"--all-statuses"},
+			expected: sagemaker.Filter{AllStatuses: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSageMakerClient)
+			mockClient.On("GetRegion").Return("us-west-2")
+			mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+			mockClient.On("ListEndpoints", mock.Anything, tt.expected).Return([]sagemaker.ResourceInfo{}, nil)
+			mockClient.On("ListNotebooks", mock.Anything, tt.expected).Return([]sagemaker.ResourceInfo{}, nil)
+			mockClient.On("ListStudioApps", mock.Anything, tt.expected).Return([]sagemaker.ResourceInfo{}, nil)
+
+			err := mockExecute(t, tt.args, mockClient)
+			assert.NoError(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+
+	t.Run("status and all-statuses are exclusive", func(t *testing.T) {
+		mockClient := new(MockSageMakerClient)
+		err := mockExecute(t, []string{"--status", "Failed", "--all-statuses"}, mockClient)
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildFilter(t *testing.T) {
+	now := time.Date(2025, 2, 8, 12, 0, 0, 0, time.UTC)
+
+	t.Run("all filter flags", func(t *testing.T) {
+		resetCommand()
+		namePattern = "exp-*"
+		instanceTypePattern = "ml.p*"
+		olderThan = "3d"
+		newerThan = "1w"
+		userProfile = "alice"
+		domainID = "d-123"
+
+		filter, err := buildFilter(now)
+		assert.NoError(t, err)
+		assert.Equal(t, sagemaker.Filter{
+			Name:          "exp-*",
+			InstanceType:  "ml.p*",
+			CreatedBefore: now.Add(-72 * time.Hour),
+			CreatedAfter:  now.Add(-168 * time.Hour),
+			UserProfile:   "alice",
+			DomainID:      "d-123",
+		}, filter)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for name, set := range map[string]func(){
+			"older-than":    func() { olderThan = "soon" },
+			"newer-than":    func() { newerThan = "3x" },
+			"name regex":    func() { namePattern = "re:(" },
+			"instance type": func() { instanceTypePattern = "ml.[p" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				resetCommand()
+				set()
+				_, err := buildFilter(now)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestRunMonitor_RegionDegraded(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return(nil, &retry.CircuitOpenError{Name: "us-west-2"})
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	// An open circuit is reported as a degraded region rather than failing the run
+	err := mockExecute(t, []string{}, mockClient)
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// captureStdout returns everything written to os.Stdout while fn runs
+func captureStdout(t *testing.T, fn func()) string {
+	return captureFile(t, &os.Stdout, fn)
+}
+
+// captureFile returns everything written to *file while fn runs
+func captureFile(t *testing.T, file **os.File, fn func()) string {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	original := *file
+	*file = w
+	defer func() {
+		*file = original
+	}()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(out)
+}
+
+func TestRunMonitor_JSONErrors(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return(nil, &retry.Error{
+		Result: retry.Result{Attempts: 4, Elapsed: 1500 * time.Millisecond, Classification: retry.ClassNonRetryable},
+		Err:    errors.New("access denied"),
+	})
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "nb", Status: "InService", InstanceType: "ml.t3.medium"},
+	}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"--json"}, mockClient)
+	})
+
+	// The run still fails, but the partial results and the failure details are emitted
+	assert.ErrorContains(t, err, "failed to list endpoints")
+	var envelope struct {
+		Resources []map[string]interface{} `json:"resources"`
+		Errors    []map[string]interface{} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope))
+	assert.Len(t, envelope.Resources, 1)
+	assert.Equal(t, []map[string]interface{}{{
+		"resourceType":   "Endpoint",
+		"message":        "access denied",
+		"classification": "non-retryable",
+		"attempts":       float64(4),
+		"elapsedSeconds": 1.5,
+	}}, envelope.Errors)
+}
+
+func TestRunMonitor_Timeout(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	// Endpoints hang until the run's deadline, the other collectors finish in time
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, context.DeadlineExceeded)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "nb", Status: "InService", InstanceType: "ml.t3.medium"},
+	}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"--json", "--timeout", "50ms"}, mockClient)
+	})
+
+	assert.ErrorContains(t, err, "timed out after 50ms")
+	var envelope struct {
+		Resources []map[string]interface{} `json:"resources"`
+		Errors    []map[string]interface{} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope))
+	assert.Len(t, envelope.Resources, 1)
+	if assert.Len(t, envelope.Errors, 1) {
+		assert.Equal(t, "Endpoint", envelope.Errors[0]["resourceType"])
+		assert.Equal(t, true, envelope.Errors[0]["timedOut"])
+	}
+}
+
+func TestReportCollectorError_Incomplete(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now())
+	defer cancelExpired()
+	interrupted, interrupt := context.WithCancel(context.Background())
+	interrupt()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		timedOut    bool
+		interrupted bool
+	}{
+		{name: "failed", ctx: context.Background()},
+		{name: "deadline", ctx: expired, timedOut: true},
+		{name: "ctrl-c", ctx: interrupted, interrupted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer := display.NewPrinter(true)
+			reportCollectorError(tt.ctx, printer, "Endpoint", errors.New("failed"))
+			if assert.Len(t, printer.Errors(), 1) {
+				assert.Equal(t, tt.timedOut, printer.Errors()[0].TimedOut)
+				assert.Equal(t, tt.interrupted, printer.Errors()[0].Interrupted)
+			}
+		})
+	}
+}
+
+func TestExecuteWithNegativeTimeout_Unit(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	err := mockExecute(t, []string{"--timeout", "-1s"}, mockClient)
+	assert.ErrorContains(t, err, "must not be negative")
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := runContext(10 * time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	ctx, cancel = runContext(0)
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline)
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestExecuteWithLogFlags_Unit(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	var err error
+	stderr := captureFile(t, &os.Stderr, func() {
+		captureStdout(t, func() {
+			err = mockExecute(t, []string{"--verbose", "--log-format", "json"}, mockClient)
+		})
+	})
+	assert.NoError(t, err)
+
+	// Every collector reports its timing as a JSON record
+	var collectors []string
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == "collector finished" {
+			collectors = append(collectors, record["collector"].(string))
+			assert.Contains(t, record, "duration")
+		}
+	}
+	assert.ElementsMatch(t, []string{"endpoints", "notebooks", "studio apps"}, collectors)
+
+	err = mockExecute(t, []string{"--log-format", "xml"}, mockClient)
+	assert.ErrorContains(t, err, "invalid log format")
+}
+
+func TestResolveEndpointURL(t *testing.T) {
+	resetCommand()
+	t.Setenv("MOHUA_ENDPOINT_URL", "")
+	assert.Equal(t, "", resolveEndpointURL())
+
+	t.Setenv("MOHUA_ENDPOINT_URL", "http://localhost:5000")
+	assert.Equal(t, "http://localhost:5000", resolveEndpointURL())
+
+	// The flag takes precedence over the environment
+	endpointURL = "http://localhost:6000"
+	assert.Equal(t, "http://localhost:6000", resolveEndpointURL())
+	resetCommand()
+}
+
+func TestExecuteWithInvalidEndpointURL_Unit(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	err := mockExecute(t, []string{"--endpoint-url", "localhost:5000"}, mockClient)
+	assert.ErrorContains(t, err, "invalid endpoint URL")
+
+	t.Setenv("MOHUA_ENDPOINT_URL", "ftp://localhost")
+	err = mockExecute(t, []string{}, mockClient)
+	assert.ErrorContains(t, err, "invalid endpoint URL")
+}
+
+func TestExecuteWithRecordReplayFlags_Unit(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "record and replay", args: []string{"--record", "a", "--replay", "b"}, want: "cannot be used together"},
+		{name: "anonymize without record", args: []string{"--anonymize"}, want: "--anonymize requires --record"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, mockClient)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+// TestExecuteWithReplay_Unit replays testdata/replay/basic, recorded with
+// `mohua -r eu-west-1 --group-by tag:team --record testdata/replay/basic --anonymize`
+func TestExecuteWithReplay_Unit(t *testing.T) {
+	resetCommand()
+	oldArgs := os.Args
+	os.Args = []string{"mohua", "--replay", "testdata/replay/basic", "--group-by", "tag:team", "-j"}
+	defer func() {
+		os.Args = oldArgs
+	}()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = Execute()
+	})
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Resources []struct {
+			Name   string            `json:"name"`
+			Region string            `json:"region"`
+			Tags   map[string]string `json:"tags"`
+		} `json:"resources"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope))
+	if assert.Len(t, envelope.Resources, 4) {
+		// Names and tag values were anonymized when recording
+		assert.Equal(t, "endpoint-3", envelope.Resources[0].Name)
+		assert.Equal(t, "eu-west-1", envelope.Resources[0].Region)
+		assert.Equal(t, map[string]string{"team": "value-12"}, envelope.Resources[0].Tags)
+		assert.Equal(t, map[string]string{"team": "domain-2"}, envelope.Resources[1].Tags)
+	}
+}
@@ -16,30 +16,78 @@ func (m *MockSageMakerClient) ValidateConfiguration(ctx context.Context) (bool,
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockSageMakerClient) ListEndpoints(ctx context.Context) ([]sagemaker.ResourceInfo, error) {
-	args := m.Called(ctx)
+func (m *MockSageMakerClient) ListEndpoints(ctx context.Context, filter sagemaker.Filter) ([]sagemaker.ResourceInfo, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]sagemaker.ResourceInfo), args.Error(1)
 }
 
-func (m *MockSageMakerClient) ListNotebooks(ctx context.Context) ([]sagemaker.ResourceInfo, error) {
-	args := m.Called(ctx)
+func (m *MockSageMakerClient) ListNotebooks(ctx context.Context, filter sagemaker.Filter) ([]sagemaker.ResourceInfo, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]sagemaker.ResourceInfo), args.Error(1)
 }
 
-func (m *MockSageMakerClient) ListStudioApps(ctx context.Context) ([]sagemaker.ResourceInfo, error) {
-	args := m.Called(ctx)
+func (m *MockSageMakerClient) ListStudioApps(ctx context.Context, filter sagemaker.Filter) ([]sagemaker.ResourceInfo, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]sagemaker.ResourceInfo), args.Error(1)
 }
 
+func (m *MockSageMakerClient) ListTags(ctx context.Context, arn string) (map[string]string, error) {
+	args := m.Called(ctx, arn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockSageMakerClient) StopNotebook(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockSageMakerClient) DeleteNotebook(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockSageMakerClient) DeleteEndpoint(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockSageMakerClient) DeleteStudioApp(ctx context.Context, app sagemaker.ResourceInfo) error {
+	args := m.Called(ctx, app)
+	return args.Error(0)
+}
+
+func (m *MockSageMakerClient) DescribeEndpoint(ctx context.Context, name string) (sagemaker.Description, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(sagemaker.Description), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeNotebook(ctx context.Context, name string) (sagemaker.Description, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(sagemaker.Description), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeStudioApp(ctx context.Context, app sagemaker.ResourceInfo) (sagemaker.Description, error) {
+	args := m.Called(ctx, app)
+	return args.Get(0).(sagemaker.Description), args.Error(1)
+}
+
+func (m *MockSageMakerClient) StudioAppArn(ctx context.Context, app sagemaker.ResourceInfo) (string, error) {
+	args := m.Called(ctx, app)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockSageMakerClient) GetRegion() string {
 	args := m.Called()
 	return args.String(0)
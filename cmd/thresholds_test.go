@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newThresholdClient lists a notebook running for 4 days and a GPU endpoint
+func newThresholdClient() *MockSageMakerClient {
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("GetRegion").Return("us-west-2")
+	mockClient.On("ValidateConfiguration", mock.Anything).Return(true, nil)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "gpu", Status: "InService", InstanceType: "ml.p4d.24xlarge", InstanceCount: 2, CreationTime: time.Now().Add(-time.Hour)},
+	}, nil)
+	mockClient.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "old", Status: "InService", InstanceType: "ml.t3.medium", CreationTime: time.Now().Add(-96 * time.Hour)},
+	}, nil)
+	mockClient.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+	return mockClient
+}
+
+func TestExecuteWithThresholds_Unit(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"--max-hourly-cost", "50", "--max-age", "72h", "--max-count", "endpoint=1", "-j"}, newThresholdClient())
+	})
+	assert.ErrorContains(t, err, "2 threshold violation(s)")
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+
+	var envelope struct {
+		Resources  []display.ResourceInfo `json:"resources"`
+		Violations []display.Violation    `json:"violations"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &envelope))
+	assert.Len(t, envelope.Resources, 2)
+	if assert.Len(t, envelope.Violations, 2) {
+		assert.Equal(t, display.ThresholdMaxHourlyCost, envelope.Violations[0].Threshold)
+		assert.Equal(t, display.ThresholdMaxAge, envelope.Violations[1].Threshold)
+		assert.Equal(t, "old", envelope.Violations[1].Name)
+	}
+
+	// The table has a dedicated section
+	out = captureStdout(t, func() {
+		err = mockExecute(t, []string{"--max-age", "3d", "--color", "never"}, newThresholdClient())
+	})
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+	assert.Contains(t, out, "Threshold violations:\n  max-age: Notebook old has been running for 96h, more than 72h\n")
+
+	// Within the limits the run succeeds
+	captureStdout(t, func() {
+		err = mockExecute(t, []string{"--max-hourly-cost", "100", "--max-count", "notebook=1"}, newThresholdClient())
+	})
+	assert.NoError(t, err)
+}
+
+func TestExecuteWithThresholdConfig_Unit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("max-hourly-cost: 50\nmax-count: [notebook=0]\n"), 0o600))
+
+	var err error
+	out := captureStdout(t, func() {
+		err = mockExecute(t, []string{"--config", path, "--color", "never"}, newThresholdClient())
+	})
+	assert.Equal(t, ExitCodeThresholds, ExitCode(err))
+	assert.Contains(t, out, "max-hourly-cost: estimated hourly cost $")
+	assert.Contains(t, out, "max-count: 1 Notebook resources exceed the limit of 0")
+}
+
+func TestExecuteWithInvalidThresholds_Unit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "negative cost", args: []string{"--max-hourly-cost", "-1"}, want: "--max-hourly-cost must not be negative"},
+		{name: "invalid age", args: []string{"--max-age", "old"}, want: "invalid --max-age"},
+		{name: "invalid count", args: []string{"--max-count", "jobs=1"}, want: `invalid max-count "jobs=1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, new(MockSageMakerClient))
+			assert.ErrorContains(t, err, tt.want)
+			assert.Equal(t, 1, ExitCode(err))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+	"mohua/internal/tui"
+)
+
+var (
+	tuiRegions []string
+	tuiRefresh time.Duration
+)
+
+// tuiCmd browses the resources interactively
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse, stop and delete resources in an interactive full-screen view",
+	Long: `Show the resources in a full-screen view refreshed every --refresh, with a tab per
+resource type and region, filter-as-you-type and a detail pane with what mohua describe
+shows for the selected resource, including its tags and cost. The usual filter flags
+apply, e.g. --all-statuses to also see stopped notebooks.
+
+Keys: up/down or j/k select, left/right or tab switch the type tab, [ and ] switch the
+region tab, J and K scroll the detail pane, / filters, esc clears the filter, r refreshes,
+q quits.
+
+s stops the selected notebook and x deletes the selected endpoint, stopped notebook or
+Studio app, after a y/N confirmation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if outputFormat != display.OutputTable {
+			return fmt.Errorf("%s does not support --output %s", cmd.CommandPath(), outputFormat)
+		}
+		if tuiRefresh < 0 {
+			return fmt.Errorf("--refresh must not be negative")
+		}
+		regions := tuiRegions
+		if len(regions) == 0 {
+			regions = []string{region}
+		}
+
+		backend := &tuiBackend{clients: make(map[string]sagemaker.Client)}
+		var names []string
+		for _, r := range regions {
+			client, filter, err := newRegionClient(r)
+			if err != nil {
+				return err
+			}
+			backend.filter = filter
+			if _, ok := backend.clients[client.GetRegion()]; !ok {
+				names = append(names, client.GetRegion())
+			}
+			backend.clients[client.GetRegion()] = client
+		}
+		backend.regions = names
+
+		terminal, err := tui.OpenTerminal(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		defer terminal.Close()
+		// Logs would draw over the screen; errors are shown in the view instead
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return tui.Run(ctx, backend, terminal, terminal.Keys(), tui.Options{
+			Regions: names,
+			Refresh: tuiRefresh,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// addTUIFlags registers the region and refresh flags of tui
+func addTUIFlags() {
+	tuiCmd.Flags().StringSliceVar(&tuiRegions, "regions", nil, "Show these regions, e.g. us-east-1,eu-west-1 (defaults to --region)")
+	tuiCmd.Flags().DurationVar(&tuiRefresh, "refresh", 30*time.Second, "List the resources again after this long (0 disables)")
+}
+
+// tuiBackend lists and acts on resources with one client per region
+type tuiBackend struct {
+	clients map[string]sagemaker.Client
+	regions []string
+	filter  sagemaker.Filter
+}
+
+// List lists every resource type of every region concurrently, like runMonitor does for one
+func (b *tuiBackend) List(ctx context.Context) ([]tui.Resource, []error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type collector struct {
+		name         string
+		resourceType string
+		list         func(ctx context.Context, filter sagemaker.Filter) ([]sagemaker.ResourceInfo, error)
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		resources []tui.Resource
+		errs      []error
+	)
+	for _, r := range b.regions {
+		client := b.clients[r]
+		collectors := []collector{
+			{name: "endpoints", resourceType: "Endpoint", list: client.ListEndpoints},
+			{name: "notebooks", resourceType: "Notebook", list: client.ListNotebooks},
+			{name: "studio apps", resourceType: "Studio", list: client.ListStudioApps},
+		}
+		for _, c := range collectors {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				listed, err := c.list(ctx, b.filter)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: failed to list %s: %w", client.GetRegion(), c.name, err))
+				}
+				// Resources listed before a failure are still shown
+				for _, resource := range listed {
+					name := resource.Name
+					if c.resourceType == "Studio" {
+						name = fmt.Sprintf("%s/%s", resource.UserProfile, resource.AppType)
+					}
+					resources = append(resources, tui.Resource{
+						ResourceInfo: toDisplayResource(c.resourceType, name, resource, client.GetRegion()),
+						Source:       resource,
+					})
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	return resources, errs
+}
+
+// Describe describes a resource like mohua describe does
+func (b *tuiBackend) Describe(ctx context.Context, r tui.Resource) (display.Detail, error) {
+	client, err := b.client(r)
+	if err != nil {
+		return display.Detail{}, err
+	}
+	name := r.Source.Name
+	if r.ResourceType == "Studio" {
+		// With the app name, the listed app is the only match
+		name = fmt.Sprintf("%s/%s/%s", r.Source.UserProfile, r.Source.AppType, r.Source.Name)
+	}
+	return describeResource(ctx, client, r.ResourceType, name)
+}
+
+// Run stops or deletes a resource
+func (b *tuiBackend) Run(ctx context.Context, action tui.Action) error {
+	client, err := b.client(action.Resource)
+	if err != nil {
+		return err
+	}
+	r := action.Resource
+	switch {
+	case action.Kind == tui.ActionStop && r.ResourceType == "Notebook":
+		return client.StopNotebook(ctx, r.Source.Name)
+	case action.Kind == tui.ActionDelete && r.ResourceType == "Notebook":
+		return client.DeleteNotebook(ctx, r.Source.Name)
+	case action.Kind == tui.ActionDelete && r.ResourceType == "Endpoint":
+		return client.DeleteEndpoint(ctx, r.Source.Name)
+	case action.Kind == tui.ActionDelete && r.ResourceType == "Studio":
+		return client.DeleteStudioApp(ctx, r.Source)
+	}
+	return fmt.Errorf("cannot %s a %s", action.Kind, r.ResourceType)
+}
+
+func (b *tuiBackend) client(r tui.Resource) (sagemaker.Client, error) {
+	client, ok := b.clients[r.Region]
+	if !ok {
+		return nil, fmt.Errorf("no client for region %s", r.Region)
+	}
+	return client, nil
+}
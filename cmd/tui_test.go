@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"mohua/internal/sagemaker"
+	"mohua/internal/tui"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecuteTUI_Unit(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "json output", args: []string{"tui", "-o", "json"}, wantErr: "mohua tui does not support --output json"},
+		{name: "negative refresh", args: []string{"tui", "--refresh", "-1s"}, wantErr: "--refresh must not be negative"},
+		{name: "not a terminal", args: []string{"tui", "--regions", "us-east-1,eu-west-1"}, wantErr: "stdin and stdout must be a terminal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mockExecute(t, tt.args, newDigestClient())
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTUIBackend(t *testing.T) {
+	west := newDigestClient()
+	east := new(MockSageMakerClient)
+	east.On("GetRegion").Return("us-east-1")
+	east.On("ListEndpoints", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, errors.New("AccessDenied"))
+	east.On("ListNotebooks", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{
+		{Name: "stopped", Arn: "arn:stopped", Status: "Stopped", InstanceType: "ml.t3.medium"},
+	}, nil)
+	east.On("ListStudioApps", mock.Anything, mock.Anything).Return([]sagemaker.ResourceInfo{}, nil)
+
+	backend := &tuiBackend{
+		clients: map[string]sagemaker.Client{"us-west-2": west, "us-east-1": east},
+		regions: []string{"us-west-2", "us-east-1"},
+	}
+
+	resources, errs := backend.List(context.Background())
+	var names []string
+	for _, r := range resources {
+		names = append(names, r.Region+" "+r.ResourceType+" "+r.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"us-east-1 Notebook stopped",
+		"us-west-2 Endpoint serving",
+		"us-west-2 Notebook exp",
+		"us-west-2 Studio bob/",
+	}, names)
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], "us-east-1: failed to list endpoints: AccessDenied")
+	}
+
+	var notebook, exp, studio tui.Resource
+	for _, r := range resources {
+		switch r.Name {
+		case "stopped":
+			notebook = r
+		case "exp":
+			exp = r
+		case "bob/":
+			studio = r
+		}
+	}
+	assert.Equal(t, "default", studio.Source.Name)
+	assert.Greater(t, studio.HourlyCost, 0.0)
+
+	// Details are described like mohua describe does, Studio apps by their full name
+	west.On("DescribeNotebook", mock.Anything, "exp").Return(sagemaker.Description{
+		ResourceInfo:    sagemaker.ResourceInfo{Name: "exp", Arn: "arn:exp", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1},
+		LifecycleConfig: "install-extensions",
+	}, nil)
+	detail, err := backend.Describe(context.Background(), exp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Notebook", detail.ResourceType)
+	assert.Equal(t, "us-west-2", detail.Region)
+	assert.Equal(t, "install-extensions", detail.LifecycleConfig)
+	assert.Equal(t, map[string]string{"team": "alice@example.com"}, detail.Tags)
+	assert.True(t, detail.Cost.Billed)
+
+	west.On("DescribeStudioApp", mock.Anything, studio.Source).Return(sagemaker.Description{
+		ResourceInfo: sagemaker.ResourceInfo{Name: "default", Status: "InService", UserProfile: "bob"},
+	}, nil)
+	detail, err = backend.Describe(context.Background(), studio)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob/", detail.Name)
+
+	// Actions go to the client of the resource's region
+	east.On("DeleteNotebook", mock.Anything, "stopped").Return(nil)
+	assert.NoError(t, backend.Run(context.Background(), tui.Action{Kind: tui.ActionDelete, Resource: notebook}))
+	west.On("DeleteStudioApp", mock.Anything, studio.Source).Return(errors.New("ResourceInUse"))
+	assert.EqualError(t, backend.Run(context.Background(), tui.Action{Kind: tui.ActionDelete, Resource: studio}), "ResourceInUse")
+	assert.EqualError(t, backend.Run(context.Background(), tui.Action{Kind: tui.ActionStop, Resource: studio}), "cannot stop a Studio")
+
+	notebook.Region = "ap-northeast-1"
+	assert.EqualError(t, backend.Run(context.Background(), tui.Action{Kind: tui.ActionStop, Resource: notebook}), "no client for region ap-northeast-1")
+	east.AssertExpectations(t)
+}
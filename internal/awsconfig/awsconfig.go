@@ -0,0 +1,95 @@
+package awsconfig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// Environment variables read by mohua on top of the standard AWS ones
+const (
+	EnvEndpointURL     = "MOHUA_ENDPOINT_URL"
+	EnvAccessKeyID     = "MOHUA_ACCESS_KEY_ID"
+	EnvSecretAccessKey = "MOHUA_SECRET_ACCESS_KEY"
+	EnvSessionToken    = "MOHUA_SESSION_TOKEN"
+)
+
+// StaticCredentials are fixed credentials, e.g. dummy keys accepted by a local stand-in service
+type StaticCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Options customizes how the shared AWS configuration is loaded; the zero value uses the SDK defaults
+type Options struct {
+	Profile     string             // Selects the shared config profile when set, overriding AWS_PROFILE
+	Region      string             // Overrides the configured region when set
+	EndpointURL string             // Sends every service client to this URL instead of AWS when set
+	Credentials *StaticCredentials // Replaces the default credential chain when set
+}
+
+// StaticCredentialsFromEnv returns the credentials in MOHUA_ACCESS_KEY_ID, MOHUA_SECRET_ACCESS_KEY
+// and MOHUA_SESSION_TOKEN, or nil when the key pair is not set
+func StaticCredentialsFromEnv() *StaticCredentials {
+	creds := &StaticCredentials{
+		AccessKeyID:     os.Getenv(EnvAccessKeyID),
+		SecretAccessKey: os.Getenv(EnvSecretAccessKey),
+		SessionToken:    os.Getenv(EnvSessionToken),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil
+	}
+	return creds
+}
+
+// ValidateEndpointURL checks that endpointURL is an absolute http or https URL
+func ValidateEndpointURL(endpointURL string) error {
+	u, err := url.Parse(endpointURL)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint URL %q: %w", endpointURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint URL %q: must be an absolute http or https URL", endpointURL)
+	}
+	return nil
+}
+
+// Load resolves the AWS configuration shared by every service client. The endpoint URL is set
+// as the config-wide BaseEndpoint, so it applies to any client created from the result.
+func Load(ctx context.Context, opts Options) (aws.Config, error) {
+	var loadOpts []func(*config.LoadOptions) error
+
+	// If region is provided, use it; otherwise, let AWS SDK handle region selection
+	if opts.Region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(opts.Region))
+	}
+	if opts.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.Credentials != nil {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			opts.Credentials.AccessKeyID,
+			opts.Credentials.SecretAccessKey,
+			opts.Credentials.SessionToken,
+		)))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if opts.EndpointURL != "" {
+		if err := ValidateEndpointURL(opts.EndpointURL); err != nil {
+			return aws.Config{}, err
+		}
+		cfg.BaseEndpoint = aws.String(opts.EndpointURL)
+	}
+	return cfg, nil
+}
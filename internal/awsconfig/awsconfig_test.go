@@ -0,0 +1,88 @@
+package awsconfig
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEndpointURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "http://localhost:5000", wantErr: false},
+		{url: "https://sagemaker.example.com/", wantErr: false},
+		{url: "localhost:5000", wantErr: true},
+		{url: "ftp://localhost", wantErr: true},
+		{url: "http://", wantErr: true},
+		{url: "://bad", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateEndpointURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStaticCredentialsFromEnv(t *testing.T) {
+	t.Setenv(EnvAccessKeyID, "")
+	t.Setenv(EnvSecretAccessKey, "")
+	assert.Nil(t, StaticCredentialsFromEnv())
+
+	t.Setenv(EnvAccessKeyID, "test")
+	assert.Nil(t, StaticCredentialsFromEnv(), "a key ID without a secret is ignored")
+
+	t.Setenv(EnvSecretAccessKey, "secret")
+	t.Setenv(EnvSessionToken, "token")
+	assert.Equal(t, &StaticCredentials{AccessKeyID: "test", SecretAccessKey: "secret", SessionToken: "token"}, StaticCredentialsFromEnv())
+}
+
+func TestLoad(t *testing.T) {
+	// Keep the test independent from the machine's AWS configuration
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+	t.Setenv("AWS_PROFILE", "")
+
+	cfg, err := Load(context.Background(), Options{
+		Region:      "us-west-2",
+		EndpointURL: "http://localhost:5000",
+		Credentials: &StaticCredentials{AccessKeyID: "test", SecretAccessKey: "secret"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", cfg.Region)
+	assert.Equal(t, "http://localhost:5000", aws.ToString(cfg.BaseEndpoint))
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "test", creds.AccessKeyID)
+	assert.Equal(t, "secret", creds.SecretAccessKey)
+
+	_, err = Load(context.Background(), Options{EndpointURL: "localhost:5000"})
+	assert.Error(t, err)
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(dir+"/config", []byte("[profile ml]\nregion = eu-central-1\n"), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", dir+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", dir+"/credentials")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+
+	cfg, err := Load(context.Background(), Options{Profile: "ml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-central-1", cfg.Region)
+
+	_, err = Load(context.Background(), Options{Profile: "missing"})
+	assert.Error(t, err)
+}
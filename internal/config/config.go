@@ -0,0 +1,257 @@
+// Package config loads mohua's configuration file and resolves every command line setting
+// from its flag, a MOHUA_* environment variable, a named view or the file, in that order.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfig overrides the configuration file location; the --config flag wins over it
+const EnvConfig = "MOHUA_CONFIG"
+
+// EnvPrefix prefixes the environment variable of every setting, e.g. MOHUA_GROUP_BY for --group-by
+const EnvPrefix = "MOHUA_"
+
+// Source tells where a resolved setting came from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceConfig  Source = "config"
+	SourceView    Source = "view"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// File is the content of config.yaml. Top-level keys are flag names without the dashes,
+// e.g. region, group-by or status, and set that flag's default.
+type File struct {
+	// Path is where the file was loaded from; empty when no file exists
+	Path string `yaml:"-"`
+	// Settings holds the top-level flag defaults
+	Settings map[string]any `yaml:",inline"`
+	// Pricing overrides the hourly price of instance types, e.g. ml.g5.xlarge: 1.41
+	Pricing map[string]float64 `yaml:"pricing"`
+	// Views are named sets of settings selected with --view
+	Views map[string]map[string]any `yaml:"views"`
+}
+
+// Setting is the resolved value of one flag
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/mohua/config.yaml, or ~/.config/mohua/config.yaml
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "mohua", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mohua", "config.yaml")
+}
+
+// Path returns the configuration file to load and whether it was chosen explicitly,
+// from flagPath, then MOHUA_CONFIG, then DefaultPath
+func Path(flagPath string) (string, bool) {
+	if flagPath != "" {
+		return flagPath, true
+	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, true
+	}
+	return DefaultPath(), false
+}
+
+// Load reads the configuration file at path. A missing file is an empty configuration,
+// unless it was chosen explicitly.
+func Load(path string, explicit bool) (*File, error) {
+	file := &File{}
+	if path == "" {
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	file.Path = path
+
+	pricing := make(map[string]float64, len(file.Pricing))
+	for instanceType, price := range file.Pricing {
+		if price < 0 {
+			return nil, fmt.Errorf("invalid config file %s: negative price for %s", path, instanceType)
+		}
+		pricing[strings.ToLower(instanceType)] = price
+	}
+	file.Pricing = pricing
+	return file, nil
+}
+
+// ViewNames returns the names of the views defined in the file, sorted
+func (f *File) ViewNames() []string {
+	names := make([]string, 0, len(f.Views))
+	for name := range f.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Without returns a copy of the file without the named settings, at the top level and in
+// every view
+func (f *File) Without(names map[string]bool) *File {
+	copied := *f
+	copied.Settings = without(f.Settings, names)
+	copied.Views = make(map[string]map[string]any, len(f.Views))
+	for name, settings := range f.Views {
+		copied.Views[name] = without(settings, names)
+	}
+	return &copied
+}
+
+func without(settings map[string]any, names map[string]bool) map[string]any {
+	kept := make(map[string]any, len(settings))
+	for name, value := range settings {
+		if !names[name] {
+			kept[name] = value
+		}
+	}
+	return kept
+}
+
+// EnvName returns the environment variable overriding a flag, e.g. MOHUA_GROUP_BY for group-by
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ViewFlag is the flag selecting a view; it is resolved before the other settings
+const ViewFlag = "view"
+
+// Apply resolves every flag in flags that was not set on the command line, from its
+// environment variable, then the selected view, then the file's top-level settings.
+// The skip flags, e.g. the config path itself, are left alone. It returns every flag's
+// resolved value and source, sorted by name.
+func Apply(flags *pflag.FlagSet, file *File, skip ...string) ([]Setting, error) {
+	skipped := map[string]bool{"help": true}
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	if err := checkNames(flags, skipped, "config file", file.Settings); err != nil {
+		return nil, err
+	}
+
+	// The view can itself come from the environment or the file
+	var view string
+	var viewSettings map[string]any
+	resolved := make(map[string]Source)
+	if f := flags.Lookup(ViewFlag); f != nil {
+		source, err := resolve(f, file.Settings, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		resolved[ViewFlag] = source
+		if view = f.Value.String(); view != "" {
+			var ok bool
+			if viewSettings, ok = file.Views[view]; !ok {
+				return nil, fmt.Errorf("unknown view %q: available views are %s", view, strings.Join(file.ViewNames(), ", "))
+			}
+			skipped[ViewFlag] = true
+			if err := checkNames(flags, skipped, "view "+view, viewSettings); err != nil {
+				return nil, err
+			}
+			delete(skipped, ViewFlag)
+		}
+	}
+
+	var settings []Setting
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || skipped[f.Name] {
+			return
+		}
+		source, ok := resolved[f.Name]
+		if !ok {
+			source, err = resolve(f, file.Settings, viewSettings, view)
+		}
+		settings = append(settings, Setting{Name: f.Name, Value: f.Value.String(), Source: source})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return settings, nil
+}
+
+// resolve sets one flag from the first source that has a value, unless it was set on the command line
+func resolve(f *pflag.Flag, fileSettings, viewSettings map[string]any, view string) (Source, error) {
+	if f.Changed {
+		return SourceFlag, nil
+	}
+	if value, ok := os.LookupEnv(EnvName(f.Name)); ok {
+		return SourceEnv, set(f, value, EnvName(f.Name))
+	}
+	if value, ok := viewSettings[f.Name]; ok {
+		return SourceView, setAny(f, value, "view "+view)
+	}
+	if value, ok := fileSettings[f.Name]; ok {
+		return SourceConfig, setAny(f, value, "config file")
+	}
+	return SourceDefault, nil
+}
+
+// checkNames rejects settings that don't correspond to a configurable flag, which are most likely typos
+func checkNames(flags *pflag.FlagSet, skipped map[string]bool, where string, settings map[string]any) error {
+	for name := range settings {
+		if flags.Lookup(name) == nil || skipped[name] {
+			return fmt.Errorf("unknown setting %q in %s", name, where)
+		}
+	}
+	return nil
+}
+
+// setAny converts a YAML value to the flag's string form; lists become comma-separated values
+func setAny(f *pflag.Flag, value any, where string) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return set(f, strings.Join(items, ","), where)
+	case map[string]any:
+		return fmt.Errorf("invalid %s in %s: expected a value, got a mapping", f.Name, where)
+	default:
+		return set(f, fmt.Sprint(v), where)
+	}
+}
+
+func set(f *pflag.Flag, value, where string) error {
+	if err := f.Value.Set(value); err != nil {
+		return fmt.Errorf("invalid %s in %s: %w", f.Name, where, err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+region: eu-west-1
+group-by: type
+status: [InService, Failed]
+timeout: 2m
+pricing:
+  ML.G5.XLARGE: 1.41
+views:
+  gpu-waste:
+    instance-type: "ml.g*"
+    older-than: 3d
+    group-by: user-profile
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// testFlags mirrors a subset of mohua's flags
+type testFlags struct {
+	set          *pflag.FlagSet
+	region       string
+	groupBy      string
+	instanceType string
+	olderThan    string
+	view         string
+	configPath   string
+	statuses     []string
+	timeout      time.Duration
+	json         bool
+}
+
+func newTestFlags(args ...string) *testFlags {
+	f := &testFlags{set: pflag.NewFlagSet("mohua", pflag.ContinueOnError)}
+	f.set.StringVar(&f.region, "region", "", "")
+	f.set.StringVar(&f.groupBy, "group-by", "", "")
+	f.set.StringVar(&f.instanceType, "instance-type", "", "")
+	f.set.StringVar(&f.olderThan, "older-than", "", "")
+	f.set.StringVar(&f.view, ViewFlag, "", "")
+	f.set.StringVar(&f.configPath, "config", "", "")
+	f.set.StringSliceVar(&f.statuses, "status", nil, "")
+	f.set.DurationVar(&f.timeout, "timeout", 5*time.Minute, "")
+	f.set.BoolVar(&f.json, "json", false, "")
+	if err := f.set.Parse(args); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func TestLoad(t *testing.T) {
+	file, err := Load(writeConfig(t, testConfig), true)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", file.Settings["region"])
+	assert.Equal(t, map[string]float64{"ml.g5.xlarge": 1.41}, file.Pricing)
+	assert.Equal(t, []string{"gpu-waste"}, file.ViewNames())
+	assert.NotContains(t, file.Settings, "views")
+
+	// A missing default file is an empty configuration, a missing explicit one is an error
+	file, err = Load(filepath.Join(t.TempDir(), "missing.yaml"), false)
+	assert.NoError(t, err)
+	assert.Empty(t, file.Settings)
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"), true)
+	assert.Error(t, err)
+
+	_, err = Load(writeConfig(t, "region: [unclosed"), true)
+	assert.ErrorContains(t, err, "invalid config file")
+	_, err = Load(writeConfig(t, "pricing:\n  ml.t3.medium: -1\n"), true)
+	assert.ErrorContains(t, err, "negative price")
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	t.Setenv(EnvConfig, "")
+
+	path, explicit := Path("")
+	assert.Equal(t, filepath.Join("/xdg", "mohua", "config.yaml"), path)
+	assert.False(t, explicit)
+
+	t.Setenv(EnvConfig, "/env.yaml")
+	path, explicit = Path("")
+	assert.Equal(t, "/env.yaml", path)
+	assert.True(t, explicit)
+
+	path, _ = Path("/flag.yaml")
+	assert.Equal(t, "/flag.yaml", path)
+}
+
+func TestWithout(t *testing.T) {
+	file, err := Load(writeConfig(t, testConfig+"since: 30d\n"), true)
+	assert.NoError(t, err)
+	file.Views["gpu-waste"]["since"] = "1d"
+
+	// The settings of another command's flags are dropped rather than reported as unknown
+	_, err = Apply(newTestFlags().set, file, "config")
+	assert.ErrorContains(t, err, `unknown setting "since"`)
+	trimmed := file.Without(map[string]bool{"since": true})
+	_, err = Apply(newTestFlags("--view", "gpu-waste").set, trimmed, "config")
+	assert.NoError(t, err)
+	assert.NotContains(t, trimmed.Settings, "since")
+	assert.NotContains(t, trimmed.Views["gpu-waste"], "since")
+
+	// The original is unchanged
+	assert.Contains(t, file.Settings, "since")
+	assert.Equal(t, "1d", file.Views["gpu-waste"]["since"])
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "MOHUA_REGION", EnvName("region"))
+	assert.Equal(t, "MOHUA_GROUP_BY", EnvName("group-by"))
+}
+
+func TestApply(t *testing.T) {
+	file, err := Load(writeConfig(t, testConfig), true)
+	assert.NoError(t, err)
+	// Unset the variables the subtests rely on; t.Setenv restores them afterwards
+	for _, name := range []string{"MOHUA_GROUP_BY", "MOHUA_REGION", "MOHUA_VIEW"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	t.Run("config file", func(t *testing.T) {
+		flags := newTestFlags()
+		settings, err := Apply(flags.set, file, "config")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", flags.region)
+		assert.Equal(t, "type", flags.groupBy)
+		assert.Equal(t, []string{"InService", "Failed"}, flags.statuses)
+		assert.Equal(t, 2*time.Minute, flags.timeout)
+		assert.False(t, flags.json)
+
+		sources := make(map[string]Source)
+		for _, s := range settings {
+			sources[s.Name] = s.Source
+		}
+		assert.Equal(t, SourceConfig, sources["region"])
+		assert.Equal(t, SourceDefault, sources["json"])
+		assert.NotContains(t, sources, "config")
+	})
+
+	t.Run("flag beats env beats view beats config", func(t *testing.T) {
+		t.Setenv("MOHUA_GROUP_BY", "region")
+		t.Setenv("MOHUA_REGION", "us-west-2")
+		flags := newTestFlags("--view", "gpu-waste", "--region", "ap-south-1")
+		settings, err := Apply(flags.set, file)
+		assert.NoError(t, err)
+		assert.Equal(t, "ap-south-1", flags.region)
+		assert.Equal(t, "region", flags.groupBy)
+		assert.Equal(t, "ml.g*", flags.instanceType)
+		assert.Equal(t, "3d", flags.olderThan)
+		assert.Equal(t, []string{"InService", "Failed"}, flags.statuses)
+
+		assert.Contains(t, settings, Setting{Name: "region", Value: "ap-south-1", Source: SourceFlag})
+		assert.Contains(t, settings, Setting{Name: "group-by", Value: "region", Source: SourceEnv})
+		assert.Contains(t, settings, Setting{Name: "instance-type", Value: "ml.g*", Source: SourceView})
+		assert.Contains(t, settings, Setting{Name: "view", Value: "gpu-waste", Source: SourceFlag})
+	})
+
+	t.Run("view from environment", func(t *testing.T) {
+		t.Setenv("MOHUA_VIEW", "gpu-waste")
+		flags := newTestFlags()
+		_, err := Apply(flags.set, file)
+		assert.NoError(t, err)
+		assert.Equal(t, "user-profile", flags.groupBy)
+	})
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		want    string
+	}{
+		{name: "unknown setting", content: "regoin: us-east-1", want: `unknown setting "regoin" in config file`},
+		{name: "skipped setting", content: "config: other.yaml", want: `unknown setting "config"`},
+		{name: "unknown view", content: "views:\n  a: {}\n", args: []string{"--view", "b"}, want: `unknown view "b": available views are a`},
+		{name: "unknown setting in view", content: "views:\n  a:\n    view: b\n", args: []string{"--view", "a"}, want: `unknown setting "view" in view a`},
+		{name: "invalid value", content: "timeout: soon", want: "invalid timeout in config file"},
+		{name: "mapping value", content: "region:\n  a: b\n", want: "expected a value, got a mapping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Load(writeConfig(t, tt.content), true)
+			assert.NoError(t, err)
+			_, err = Apply(newTestFlags(tt.args...).set, file, "config")
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
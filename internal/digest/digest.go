@@ -0,0 +1,158 @@
+// Package digest summarizes the running resources per owner and emails the summary.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/pricing"
+)
+
+// NoOwner is the owner of resources with neither a user profile nor an owner tag
+const NoOwner = "(none)"
+
+// Options configures how a digest is built
+type Options struct {
+	// OwnerTag is the tag naming the owner of endpoints and notebooks; Studio apps are owned by
+	// their user profile
+	OwnerTag string
+	// IdleAfter flags billed notebooks and Studio apps running longer than this as possibly
+	// idle; 0 disables the flag
+	IdleAfter time.Duration
+	Region    string
+	Now       time.Time
+}
+
+// Item is a listed resource with its idle flag
+type Item struct {
+	display.ResourceInfo
+	Idle bool `json:"idle"`
+}
+
+// Group holds the resources of one owner and their totals
+type Group struct {
+	Owner       string  `json:"owner"`
+	Items       []Item  `json:"resources"`
+	Idle        int     `json:"idle"`
+	HourlyCost  float64 `json:"estimatedHourlyCost"`
+	MonthlyCost float64 `json:"estimatedMonthlyCost"`
+}
+
+// Digest is the per-owner summary of a listing
+type Digest struct {
+	Region      string    `json:"region,omitempty"`
+	Generated   time.Time `json:"generated"`
+	OwnerTag    string    `json:"ownerTag"`
+	IdleAfter   string    `json:"idleAfter,omitempty"`
+	Groups      []Group   `json:"groups"`
+	Resources   int       `json:"resources"`
+	Idle        int       `json:"idle"`
+	HourlyCost  float64   `json:"estimatedHourlyCost"`
+	MonthlyCost float64   `json:"estimatedMonthlyCost"`
+}
+
+// Build groups resources by owner; groups are sorted by descending hourly cost and resources
+// within a group by descending hourly cost, then name
+func Build(resources []display.ResourceInfo, opts Options) Digest {
+	d := Digest{
+		Region:    opts.Region,
+		Generated: opts.Now,
+		OwnerTag:  opts.OwnerTag,
+		Groups:    []Group{},
+	}
+	if opts.IdleAfter > 0 {
+		d.IdleAfter = strconv.FormatFloat(opts.IdleAfter.Hours(), 'f', -1, 64) + "h"
+	}
+
+	groups := make(map[string]*Group)
+	var order []string
+	for _, r := range resources {
+		owner := owner(r, opts.OwnerTag)
+		group, ok := groups[owner]
+		if !ok {
+			group = &Group{Owner: owner}
+			groups[owner] = group
+			order = append(order, owner)
+		}
+		item := Item{ResourceInfo: r, Idle: display.IsIdle(r, opts.IdleAfter)}
+		group.Items = append(group.Items, item)
+		group.HourlyCost += r.HourlyCost
+		if item.Idle {
+			group.Idle++
+		}
+	}
+
+	for _, owner := range order {
+		group := groups[owner]
+		sort.SliceStable(group.Items, func(i, j int) bool {
+			a, b := group.Items[i], group.Items[j]
+			if a.HourlyCost != b.HourlyCost {
+				return a.HourlyCost > b.HourlyCost
+			}
+			return a.Name < b.Name
+		})
+		d.Groups = append(d.Groups, *group)
+	}
+	sort.SliceStable(d.Groups, func(i, j int) bool {
+		a, b := d.Groups[i], d.Groups[j]
+		if a.HourlyCost != b.HourlyCost {
+			return a.HourlyCost > b.HourlyCost
+		}
+		return a.Owner < b.Owner
+	})
+	d.total()
+	return d
+}
+
+// total computes the monthly costs and the digest totals from the groups
+func (d *Digest) total() {
+	d.Resources, d.Idle, d.HourlyCost = 0, 0, 0
+	for i := range d.Groups {
+		group := &d.Groups[i]
+		group.MonthlyCost = group.HourlyCost * pricing.HoursPerMonth
+		d.Resources += len(group.Items)
+		d.Idle += group.Idle
+		d.HourlyCost += group.HourlyCost
+	}
+	d.MonthlyCost = d.HourlyCost * pricing.HoursPerMonth
+}
+
+// ForOwners returns the digest restricted to the groups of the given owners
+func (d Digest) ForOwners(owners map[string]bool) Digest {
+	filtered := d
+	filtered.Groups = []Group{}
+	for _, group := range d.Groups {
+		if owners[group.Owner] {
+			filtered.Groups = append(filtered.Groups, group)
+		}
+	}
+	filtered.total()
+	return filtered
+}
+
+// Subject returns the email subject summarizing the digest
+func (d Digest) Subject() string {
+	subject := fmt.Sprintf("mohua digest: %d resource(s), $%.2f/hour", d.Resources, d.HourlyCost)
+	if d.Idle > 0 {
+		subject += fmt.Sprintf(", %d possibly idle", d.Idle)
+	}
+	if d.Region != "" {
+		subject += " in " + d.Region
+	}
+	return subject
+}
+
+// owner returns the Studio user profile or the owner tag of a resource
+func owner(r display.ResourceInfo, ownerTag string) string {
+	if r.UserProfile != "" {
+		return r.UserProfile
+	}
+	if value := strings.TrimSpace(r.Tags[ownerTag]); value != "" {
+		return value
+	}
+	return NoOwner
+}
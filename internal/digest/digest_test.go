@@ -0,0 +1,162 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+
+// testResources are owned by alice (a tagged notebook and her Studio app), the ml-team tag
+// and nobody
+func testResources() []display.ResourceInfo {
+	day := int64(24 * 60 * 60)
+	return []display.ResourceInfo{
+		{ResourceType: "Endpoint", Name: "serving", Status: "InService", InstanceType: "ml.g5.xlarge", RunningTime: "10d", RunningSeconds: 10 * day, HourlyCost: 1.41, Tags: map[string]string{"team": "ml-team"}},
+		{ResourceType: "Notebook", Name: "exp", Status: "InService", InstanceType: "ml.g5.2xlarge", RunningTime: "3d", RunningSeconds: 3 * day, HourlyCost: 1.52, Tags: map[string]string{"team": "alice@example.com"}},
+		{ResourceType: "Studio", Name: "default", Status: "InService", InstanceType: "ml.t3.medium", RunningTime: "2h", RunningSeconds: 7200, HourlyCost: 0.05, UserProfile: "alice@example.com"},
+		{ResourceType: "Notebook", Name: "stopped", Status: "Stopped", InstanceType: "ml.t3.medium", RunningTime: "5d", RunningSeconds: 5 * day},
+		{ResourceType: "Notebook", Name: "scratch", Status: "InService", InstanceType: "ml.t3.medium", RunningTime: "2d", RunningSeconds: 2 * day, HourlyCost: 0.05},
+	}
+}
+
+func testDigest() Digest {
+	return Build(testResources(), Options{OwnerTag: "team", IdleAfter: 24 * time.Hour, Region: "us-west-2", Now: testNow})
+}
+
+func TestBuild(t *testing.T) {
+	d := testDigest()
+
+	assert.Equal(t, 5, d.Resources)
+	assert.Equal(t, 2, d.Idle)
+	assert.InDelta(t, 3.03, d.HourlyCost, 1e-9)
+	assert.Equal(t, "24h", d.IdleAfter)
+	if assert.Len(t, d.Groups, 3) {
+		alice := d.Groups[0]
+		assert.Equal(t, "alice@example.com", alice.Owner)
+		assert.InDelta(t, 1.57, alice.HourlyCost, 1e-9)
+		assert.Equal(t, 1, alice.Idle)
+		if assert.Len(t, alice.Items, 2) {
+			assert.Equal(t, "exp", alice.Items[0].Name)
+			assert.True(t, alice.Items[0].Idle)
+			// Studio apps running for less than --idle-after are not flagged
+			assert.False(t, alice.Items[1].Idle)
+		}
+
+		team := d.Groups[1]
+		assert.Equal(t, "ml-team", team.Owner)
+		// Endpoints are expected to run for long
+		assert.False(t, team.Items[0].Idle)
+
+		none := d.Groups[2]
+		assert.Equal(t, NoOwner, none.Owner)
+		if assert.Len(t, none.Items, 2) {
+			assert.Equal(t, "scratch", none.Items[0].Name)
+			assert.True(t, none.Items[0].Idle)
+			// Stopped notebooks are not billed, so they are not idle
+			assert.False(t, none.Items[1].Idle)
+		}
+	}
+
+	// Disabled idle flag and an empty listing
+	d = Build(testResources(), Options{OwnerTag: "team"})
+	assert.Equal(t, 0, d.Idle)
+	d = Build(nil, Options{OwnerTag: "team"})
+	assert.Equal(t, 0, d.Resources)
+	assert.NotNil(t, d.Groups)
+}
+
+func TestPlan(t *testing.T) {
+	d := testDigest()
+	routes, err := ParseRoutes([]string{"ml-team=ml-leads@example.com", "ml-team=oncall@example.com", "(none)=finops@example.com"})
+	assert.NoError(t, err)
+
+	deliveries := Plan(d, []string{"manager@example.com"}, routes)
+	if assert.Len(t, deliveries, 5) {
+		assert.Equal(t, []string{"manager@example.com"}, deliveries[0].To)
+		assert.Equal(t, 5, deliveries[0].Digest.Resources)
+
+		// Owners that are addresses receive their own resources without a route
+		assert.Equal(t, []string{"alice@example.com"}, deliveries[1].To)
+		assert.Equal(t, 2, deliveries[1].Digest.Resources)
+		assert.InDelta(t, 1.57, deliveries[1].Digest.HourlyCost, 1e-9)
+
+		assert.Equal(t, []string{"finops@example.com"}, deliveries[2].To)
+		assert.Equal(t, NoOwner, deliveries[2].Digest.Groups[0].Owner)
+		assert.Equal(t, 1, deliveries[2].Digest.Idle)
+
+		assert.Equal(t, []string{"ml-leads@example.com"}, deliveries[3].To)
+		assert.Equal(t, []string{"oncall@example.com"}, deliveries[4].To)
+		assert.Equal(t, "ml-team", deliveries[4].Digest.Groups[0].Owner)
+	}
+
+	// Without default recipients, unrouted owners are left out
+	deliveries = Plan(d, nil, Routes{})
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, []string{"alice@example.com"}, deliveries[0].To)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    Routes
+		wantErr string
+	}{
+		{name: "none", want: Routes{}},
+		{name: "repeated owner", specs: []string{"a=x@example.com", " a = y@example.com"}, want: Routes{"a": {"x@example.com", "y@example.com"}}},
+		{name: "missing address", specs: []string{"a"}, wantErr: `invalid route "a": expected owner=address`},
+		{name: "invalid address", specs: []string{"a=alice"}, wantErr: `invalid email address "alice"`},
+		{name: "display name", specs: []string{"a=Alice <alice@example.com>"}, wantErr: "invalid email address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := ParseRoutes(tt.specs)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, routes)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := testDigest()
+	assert.Equal(t, "mohua digest: 5 resource(s), $3.03/hour, 2 possibly idle in us-west-2", d.Subject())
+
+	text := Text(d)
+	assert.True(t, strings.HasPrefix(text, "mohua digest for us-west-2, 2025-03-03 08:00 UTC\n\n5 resource(s), estimated $3.03/hour ($2211.90/month), 2 possibly idle\n"), text)
+	assert.Contains(t, text, "\nalice@example.com: 2 resource(s), $1.57/hour ($1146.10/month)\n")
+	assert.Regexp(t, `  Notebook +exp +ml\.g5\.2xlarge +InService +3d +\$1\.52 +idle\?\n`, text)
+	assert.Regexp(t, `  Endpoint +serving +ml\.g5\.xlarge +InService +10d +\$1\.41 +\n`, text)
+	assert.Contains(t, text, "idle?: notebook or Studio app running for more than 24h")
+
+	html, err := HTML(d)
+	assert.NoError(t, err)
+	assert.Contains(t, html, "<h2 style=\"font-size: 18px;\">mohua digest for us-west-2, 2025-03-03 08:00 UTC</h2>")
+	assert.Contains(t, html, "<h3 style=\"font-size: 16px; margin-bottom: 4px;\">ml-team</h3>")
+	assert.Contains(t, html, "<td>exp</td><td>ml.g5.2xlarge</td>")
+	assert.Contains(t, html, "Total: 2 resource(s), $1146.10/month")
+	assert.Equal(t, 2, strings.Count(html, ">idle?</span>"))
+
+	// Names are escaped
+	d.Groups[0].Items[0].Name = "<script>"
+	html, err = HTML(d)
+	assert.NoError(t, err)
+	assert.Contains(t, html, "&lt;script&gt;")
+
+	empty := Build(nil, Options{Now: testNow})
+	assert.Contains(t, Text(empty), "Nothing is running.")
+	html, err = HTML(empty)
+	assert.NoError(t, err)
+	assert.Contains(t, html, "<p>Nothing is running.</p>")
+}
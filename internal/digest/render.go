@@ -0,0 +1,93 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"text/tabwriter"
+)
+
+// Text renders the digest as plain text, one table per owner
+func Text(d Digest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", heading(d))
+	fmt.Fprintf(&b, "%d resource(s), estimated $%.2f/hour ($%.2f/month)", d.Resources, d.HourlyCost, d.MonthlyCost)
+	if d.Idle > 0 {
+		fmt.Fprintf(&b, ", %d possibly idle", d.Idle)
+	}
+	b.WriteString("\n")
+	if len(d.Groups) == 0 {
+		b.WriteString("\nNothing is running.\n")
+	}
+
+	for _, group := range d.Groups {
+		fmt.Fprintf(&b, "\n%s: %d resource(s), $%.2f/hour ($%.2f/month)\n", group.Owner, len(group.Items), group.HourlyCost, group.MonthlyCost)
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Type\tName\tInstance Type\tStatus\tRunning\tCost/Hour\t")
+		for _, item := range group.Items {
+			idle := ""
+			if item.Idle {
+				idle = "idle?"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t$%.2f\t%s\n",
+				item.ResourceType, item.Name, item.InstanceType, item.Status, item.RunningTime, item.HourlyCost, idle)
+		}
+		w.Flush()
+	}
+
+	if d.Idle > 0 {
+		fmt.Fprintf(&b, "\nidle?: notebook or Studio app running for more than %s; stop it if nobody is using it.\n", d.IdleAfter)
+	}
+	return b.String()
+}
+
+// heading is the first line of both renderings
+func heading(d Digest) string {
+	heading := "mohua digest"
+	if d.Region != "" {
+		heading += " for " + d.Region
+	}
+	return heading + ", " + d.Generated.Format("2006-01-02 15:04 MST")
+}
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"cost": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px; color: #222;">
+<h2 style="font-size: 18px;">{{.Heading}}</h2>
+<p>{{.Resources}} resource(s), estimated <b>{{cost .HourlyCost}}/hour</b> ({{cost .MonthlyCost}}/month){{if .Idle}}, <span style="color: #c00;">{{.Idle}} possibly idle</span>{{end}}</p>
+{{- if not .Groups}}
+<p>Nothing is running.</p>
+{{- end}}
+{{- range .Groups}}
+<h3 style="font-size: 16px; margin-bottom: 4px;">{{.Owner}}</h3>
+<table style="border-collapse: collapse;" cellpadding="4">
+<tr style="background: #eee; text-align: left;"><th>Type</th><th>Name</th><th>Instance Type</th><th>Status</th><th>Running</th><th style="text-align: right;">Cost/Hour</th><th></th></tr>
+{{- range .Items}}
+<tr style="border-top: 1px solid #ddd;"><td>{{.ResourceType}}</td><td>{{.Name}}</td><td>{{.InstanceType}}</td><td>{{.Status}}</td><td>{{.RunningTime}}</td><td style="text-align: right;">{{cost .HourlyCost}}</td><td>{{if .Idle}}<span style="color: #c00;">idle?</span>{{end}}</td></tr>
+{{- end}}
+<tr style="border-top: 2px solid #999; font-weight: bold;"><td colspan="5">Total: {{len .Items}} resource(s), {{cost .MonthlyCost}}/month</td><td style="text-align: right;">{{cost .HourlyCost}}</td><td></td></tr>
+</table>
+{{- end}}
+{{- if .Idle}}
+<p style="color: #666;">idle?: notebook or Studio app running for more than {{.IdleAfter}}; stop it if nobody is using it.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders the digest as an HTML document with inline styles, as mail clients ignore
+// style sheets
+func HTML(d Digest) (string, error) {
+	var b bytes.Buffer
+	data := struct {
+		Digest
+		Heading string
+	}{d, heading(d)}
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// Routes maps owners, i.e. values of the owner tag or Studio user profiles, to email addresses
+type Routes map[string][]string
+
+// Delivery is one email: the digest its recipients are sent
+type Delivery struct {
+	To     []string
+	Digest Digest
+}
+
+// ParseRoutes parses "owner=address" routes, e.g. ml-team=ml-leads@example.com; repeating an
+// owner adds recipients
+func ParseRoutes(specs []string) (Routes, error) {
+	routes := make(Routes, len(specs))
+	for _, spec := range specs {
+		owner, address, found := strings.Cut(spec, "=")
+		owner, address = strings.TrimSpace(owner), strings.TrimSpace(address)
+		if !found || owner == "" {
+			return nil, fmt.Errorf("invalid route %q: expected owner=address", spec)
+		}
+		if err := ValidateAddress(address); err != nil {
+			return nil, fmt.Errorf("invalid route %q: %w", spec, err)
+		}
+		routes[owner] = append(routes[owner], address)
+	}
+	return routes, nil
+}
+
+// ValidateAddress checks that s is a single bare email address, e.g. alice@example.com
+func ValidateAddress(s string) error {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return fmt.Errorf("invalid email address %q", s)
+	}
+	return nil
+}
+
+// recipients returns the routed addresses of an owner, or the owner itself when it is an address
+func (r Routes) recipients(owner string) []string {
+	if addresses, ok := r[owner]; ok {
+		return addresses
+	}
+	if ValidateAddress(owner) == nil {
+		return []string{owner}
+	}
+	return nil
+}
+
+// Plan decides who receives what: every address in to receives the whole digest, and every
+// routed address receives the groups of the owners routed to it. Owners without a route are
+// only in the whole digest.
+func Plan(d Digest, to []string, routes Routes) []Delivery {
+	var deliveries []Delivery
+	if len(to) > 0 {
+		deliveries = append(deliveries, Delivery{To: to, Digest: d})
+	}
+
+	owners := make(map[string]map[string]bool)
+	for _, group := range d.Groups {
+		for _, address := range routes.recipients(group.Owner) {
+			if owners[address] == nil {
+				owners[address] = make(map[string]bool)
+			}
+			owners[address][group.Owner] = true
+		}
+	}
+	addresses := make([]string, 0, len(owners))
+	for address := range owners {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		deliveries = append(deliveries, Delivery{To: []string{address}, Digest: d.ForOwners(owners[address])})
+	}
+	return deliveries
+}
@@ -0,0 +1,134 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mohua/internal/fakesmtp"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T, options fakesmtp.Options) *fakesmtp.Server {
+	server, err := fakesmtp.New(options)
+	assert.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server
+}
+
+var testMessage = Message{
+	From:    "mohua@example.com",
+	To:      []string{"alice@example.com", "bob@example.com"},
+	Subject: "mohua digest: 1 resource(s) — $1.41/hour",
+	Text:    "Endpoint serving\n.leading dot\n",
+	HTML:    "<p>Endpoint serving</p>\n",
+	Date:    testNow,
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name    string
+		options fakesmtp.Options
+		config  func(s *fakesmtp.Server) SMTPConfig
+		wantTLS bool
+		wantErr string
+	}{
+		{
+			name:    "plain",
+			options: fakesmtp.Options{},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port()}
+			},
+		},
+		{
+			name:    "STARTTLS and auth",
+			options: fakesmtp.Options{StartTLS: true, Username: "mohua", Password: "secret"},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port(), StartTLS: true, TLSConfig: s.ClientTLSConfig(), Username: "mohua", Password: "secret"}
+			},
+			wantTLS: true,
+		},
+		{
+			name:    "untrusted certificate",
+			options: fakesmtp.Options{StartTLS: true},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port(), StartTLS: true}
+			},
+			wantErr: "STARTTLS failed",
+		},
+		{
+			name:    "STARTTLS not offered",
+			options: fakesmtp.Options{},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port(), StartTLS: true}
+			},
+			wantErr: "does not support STARTTLS",
+		},
+		{
+			name:    "wrong password",
+			options: fakesmtp.Options{StartTLS: true, Username: "mohua", Password: "secret"},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port(), StartTLS: true, TLSConfig: s.ClientTLSConfig(), Username: "mohua", Password: "wrong"}
+			},
+			wantErr: "SMTP authentication failed",
+		},
+		{
+			name:    "authentication required",
+			options: fakesmtp.Options{Username: "mohua", Password: "secret"},
+			config: func(s *fakesmtp.Server) SMTPConfig {
+				return SMTPConfig{Host: s.Host(), Port: s.Port()}
+			},
+			wantErr: "SMTP server rejected sender",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, tt.options)
+			err := Send(tt.config(server), testMessage)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Empty(t, server.Messages())
+				return
+			}
+			assert.NoError(t, err)
+
+			messages := server.Messages()
+			if !assert.Len(t, messages, 1) {
+				return
+			}
+			msg := messages[0]
+			assert.Equal(t, tt.wantTLS, msg.TLS)
+			assert.Equal(t, "mohua@example.com", msg.From)
+			assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, msg.To)
+			assert.Equal(t, testMessage.Subject, msg.Header("Subject"))
+			assert.Equal(t, "alice@example.com, bob@example.com", msg.Header("To"))
+
+			parts, err := msg.Parts()
+			assert.NoError(t, err)
+			assert.Equal(t, "Endpoint serving\n.leading dot\n", parts["text/plain"])
+			assert.Equal(t, "<p>Endpoint serving</p>\n", parts["text/html"])
+		})
+	}
+}
+
+func TestSendConnectionRefused(t *testing.T) {
+	server := newServer(t, fakesmtp.Options{})
+	host, port := server.Host(), server.Port()
+	server.Close()
+
+	err := Send(SMTPConfig{Host: host, Port: port, Timeout: time.Second}, testMessage)
+	assert.ErrorContains(t, err, "failed to connect to SMTP server")
+}
+
+func TestMessageBytes(t *testing.T) {
+	data, err := testMessage.Bytes()
+	assert.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "Subject: =?utf-8?q?")
+	assert.Contains(t, text, "Content-Type: multipart/alternative; boundary=\"mohua-")
+	assert.Contains(t, text, "Date: Mon, 03 Mar 2025 08:00:00 +0000\r\n")
+	// Every line ends with CRLF
+	assert.NotContains(t, strings.ReplaceAll(text, "\r\n", ""), "\n")
+}
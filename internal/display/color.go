@@ -0,0 +1,129 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Supported color modes
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Palette maps resource statuses to the color used to render them
+type Palette map[string]color.Attribute
+
+// colorNames lists the color names accepted in palette specifications
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// DefaultPalette covers every endpoint, notebook instance and Studio app status:
+// green for serving resources, yellow for transitional or paused states and red for failures and teardown
+var DefaultPalette = Palette{
+	"InService":            color.FgGreen,
+	"Running":              color.FgGreen,
+	"Pending":              color.FgYellow,
+	"Creating":             color.FgYellow,
+	"Updating":             color.FgYellow,
+	"SystemUpdating":       color.FgYellow,
+	"RollingBack":          color.FgYellow,
+	"Stopping":             color.FgYellow,
+	"Stopped":              color.FgYellow,
+	"OutOfService":         color.FgRed,
+	"Failed":               color.FgRed,
+	"UpdateRollbackFailed": color.FgRed,
+	"Deleting":             color.FgRed,
+	"Deleted":              color.FgRed,
+}
+
+// ValidateColorMode checks that the given color mode is supported
+func ValidateColorMode(mode string) error {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+	return fmt.Errorf("invalid color mode %q: must be one of auto, always or never", mode)
+}
+
+// ColorEnabled decides whether output written to out should be colorized.
+// In auto mode colors are disabled when NO_COLOR is set or out is not a terminal.
+func ColorEnabled(mode string, out io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// ParsePalette parses a "Status=color,Status=color" specification and merges it over DefaultPalette
+func ParsePalette(spec string) (Palette, error) {
+	palette := make(Palette, len(DefaultPalette))
+	for status, attr := range DefaultPalette {
+		palette[status] = attr
+	}
+
+	if strings.TrimSpace(spec) == "" {
+		return palette, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		status, name, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || status == "" {
+			return nil, fmt.Errorf("invalid status color %q: expected Status=color", entry)
+		}
+		attr, ok := colorNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid color %q for status %s: must be one of %s", name, status, strings.Join(colorNameList(), ", "))
+		}
+		palette[status] = attr
+	}
+
+	return palette, nil
+}
+
+func colorNameList() []string {
+	names := make([]string, 0, len(colorNames))
+	for name := range colorNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newColor creates a color whose output is explicitly enabled or disabled,
+// independent of the global color.NoColor setting
+func newColor(enabled bool, attrs ...color.Attribute) *color.Color {
+	c := color.New(attrs...)
+	if enabled {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	return c
+}
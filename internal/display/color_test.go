@@ -0,0 +1,91 @@
+package display
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorEnabled(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, ColorEnabled(ColorAlways, &buf))
+	assert.False(t, ColorEnabled(ColorNever, &buf))
+	assert.False(t, ColorEnabled(ColorAuto, &buf), "non-terminal writers should not be colored")
+
+	devNull, err := os.Open(os.DevNull)
+	assert.NoError(t, err)
+	defer devNull.Close()
+	assert.False(t, ColorEnabled(ColorAuto, devNull), "non-TTY files should not be colored")
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, ColorEnabled(ColorAuto, &buf))
+	assert.True(t, ColorEnabled(ColorAlways, &buf), "always overrides NO_COLOR")
+}
+
+func TestValidateColorMode(t *testing.T) {
+	assert.NoError(t, ValidateColorMode(ColorAuto))
+	assert.NoError(t, ValidateColorMode(ColorAlways))
+	assert.NoError(t, ValidateColorMode(ColorNever))
+	assert.Error(t, ValidateColorMode("sometimes"))
+}
+
+func TestParsePalette(t *testing.T) {
+	t.Run("empty spec returns defaults", func(t *testing.T) {
+		palette, err := ParsePalette("")
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultPalette, palette)
+	})
+
+	t.Run("overrides are merged", func(t *testing.T) {
+		palette, err := ParsePalette("Pending=cyan, Failed=Magenta")
+		assert.NoError(t, err)
+		assert.Equal(t, color.FgCyan, palette["Pending"])
+		assert.Equal(t, color.FgMagenta, palette["Failed"])
+		assert.Equal(t, color.FgGreen, palette["InService"])
+		assert.Equal(t, color.FgYellow, DefaultPalette["Pending"], "defaults must not be modified")
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		_, err := ParsePalette("Pending")
+		assert.Error(t, err)
+		_, err = ParsePalette("Pending=orange")
+		assert.Error(t, err)
+	})
+}
+
+func TestDefaultPaletteCoversStatuses(t *testing.T) {
+	statuses := []string{
+		// Endpoint statuses
+		"OutOfService", "Creating", "Updating", "SystemUpdating", "RollingBack", "InService", "Deleting", "Failed", "UpdateRollbackFailed",
+		// Notebook instance statuses
+		"Pending", "Stopping", "Stopped",
+		// Studio app statuses
+		"Deleted",
+	}
+	for _, status := range statuses {
+		_, ok := DefaultPalette[status]
+		assert.True(t, ok, "missing color for status %s", status)
+	}
+}
+
+func TestPrinterColorOutput(t *testing.T) {
+	info := ResourceInfo{ResourceType: "Endpoint", Name: "ep", Status: "Failed", InstanceType: "ml.t3.medium", RunningTime: "1h"}
+
+	var colored bytes.Buffer
+	printer := &Printer{output: &colored, palette: DefaultPalette}
+	printer.SetColorMode(ColorAlways)
+	printer.PrintResource(info)
+	assert.Contains(t, colored.String(), "\x1b[31mFailed      \x1b[0m")
+
+	var plain bytes.Buffer
+	printer = &Printer{output: &plain, palette: DefaultPalette}
+	printer.SetColorMode(ColorNever)
+	printer.PrintResource(info)
+	assert.NotContains(t, plain.String(), "\x1b[")
+	assert.Contains(t, plain.String(), "Failed       ml.t3.medium")
+}
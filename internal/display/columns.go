@@ -0,0 +1,55 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// column is a table column; values longer than the width are truncated when truncate is set
+type column struct {
+	header   string
+	width    int
+	truncate bool
+	value    func(info ResourceInfo) string
+}
+
+// columns are the table columns selectable with SetColumns
+var columns = map[string]column{
+	"type":           {header: "Type", width: 15, value: func(info ResourceInfo) string { return info.ResourceType }},
+	"name":           {header: "Name", width: 30, truncate: true, value: func(info ResourceInfo) string { return info.Name }},
+	"status":         {header: "Status", width: 12, value: func(info ResourceInfo) string { return info.Status }},
+	"instance":       {header: "Instance", width: 15, value: func(info ResourceInfo) string { return info.InstanceType }},
+	"running-time":   {header: "Running Time", width: 15, value: func(info ResourceInfo) string { return info.RunningTime }},
+	"instance-count": {header: "Count", width: 6, value: func(info ResourceInfo) string { return fmt.Sprint(info.InstanceCount) }},
+	"user-profile":   {header: "User Profile", width: 20, truncate: true, value: func(info ResourceInfo) string { return info.UserProfile }},
+	"region":         {header: "Region", width: 15, value: func(info ResourceInfo) string { return info.Region }},
+	"created":        {header: "Created", width: 21, value: func(info ResourceInfo) string { return info.CreationTime.UTC().Format(time.RFC3339) }},
+	"hourly-cost":    {header: "Hourly Cost", width: 12, value: func(info ResourceInfo) string { return fmt.Sprintf("$%.2f", info.HourlyCost) }},
+}
+
+// DefaultColumns are the table columns shown unless SetColumns picks others
+var DefaultColumns = []string{"type", "name", "status", "instance", "running-time"}
+
+// ValidateColumns checks that every name is a known table column
+func ValidateColumns(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("invalid column %q: must be one of %s", name, strings.Join(columnNames(), ", "))
+		}
+	}
+	return nil
+}
+
+func columnNames() []string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,36 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateColumns(t *testing.T) {
+	assert.NoError(t, ValidateColumns(DefaultColumns))
+	assert.NoError(t, ValidateColumns([]string{"name", "hourly-cost", "created"}))
+	assert.ErrorContains(t, ValidateColumns([]string{"name", "cost"}), `invalid column "cost"`)
+	assert.Error(t, ValidateColumns(nil))
+}
+
+func TestPrinterColumns(t *testing.T) {
+	var buf bytes.Buffer
+	printer := &Printer{output: &buf}
+	printer.SetColumns([]string{"name", "user-profile", "hourly-cost", "created"})
+
+	printer.PrintHeader()
+	printer.PrintResource(ResourceInfo{
+		ResourceType: "Studio",
+		Name:         "alice/JupyterLab",
+		UserProfile:  "a-very-long-user-profile-name",
+		HourlyCost:   1.41,
+		CreationTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "Name                           User Profile         Hourly Cost  Created              ", lines[0])
+	assert.Equal(t, "alice/JupyterLab               a-very-long-user...  $1.41        2024-01-02T03:04:05Z ", lines[2])
+}
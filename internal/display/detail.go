@@ -0,0 +1,284 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+	"mohua/internal/pricing"
+)
+
+// Detail is the full description of a single resource, as printed by mohua describe
+type Detail struct {
+	ResourceType     string     `json:"resourceType" yaml:"resourceType"`
+	Name             string     `json:"name" yaml:"name"`
+	Arn              string     `json:"arn,omitempty" yaml:"arn,omitempty"`
+	Region           string     `json:"region,omitempty" yaml:"region,omitempty"`
+	Status           string     `json:"status" yaml:"status"`
+	FailureReason    string     `json:"failureReason,omitempty" yaml:"failureReason,omitempty"`
+	InstanceType     string     `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	InstanceCount    int        `json:"instanceCount" yaml:"instanceCount"`
+	VolumeSizeGB     int        `json:"volumeSizeGB,omitempty" yaml:"volumeSizeGB,omitempty"`
+	CreationTime     time.Time  `json:"creationTime" yaml:"creationTime"`
+	RunningSeconds   int64      `json:"runningSeconds" yaml:"runningSeconds"`
+	LastModifiedTime *time.Time `json:"lastModifiedTime,omitempty" yaml:"lastModifiedTime,omitempty"`
+	RoleArn          string     `json:"roleArn,omitempty" yaml:"roleArn,omitempty"`
+	KmsKeyID         string     `json:"kmsKeyId,omitempty" yaml:"kmsKeyId,omitempty"`
+	LifecycleConfig  string     `json:"lifecycleConfig,omitempty" yaml:"lifecycleConfig,omitempty"`
+	Network          Network    `json:"network" yaml:"network"`
+
+	// Endpoints
+	EndpointConfigName string    `json:"endpointConfigName,omitempty" yaml:"endpointConfigName,omitempty"`
+	Variants           []Variant `json:"variants,omitempty" yaml:"variants,omitempty"`
+	DataCapture        bool      `json:"dataCapture,omitempty" yaml:"dataCapture,omitempty"`
+
+	// Notebook instances
+	URL                   string `json:"url,omitempty" yaml:"url,omitempty"`
+	RootAccess            string `json:"rootAccess,omitempty" yaml:"rootAccess,omitempty"`
+	PlatformIdentifier    string `json:"platformIdentifier,omitempty" yaml:"platformIdentifier,omitempty"`
+	DefaultCodeRepository string `json:"defaultCodeRepository,omitempty" yaml:"defaultCodeRepository,omitempty"`
+
+	// Studio apps
+	AppName          string     `json:"appName,omitempty" yaml:"appName,omitempty"`
+	AppType          string     `json:"appType,omitempty" yaml:"appType,omitempty"`
+	StudioType       string     `json:"studioType,omitempty" yaml:"studioType,omitempty"`
+	UserProfile      string     `json:"userProfile,omitempty" yaml:"userProfile,omitempty"`
+	SpaceName        string     `json:"spaceName,omitempty" yaml:"spaceName,omitempty"`
+	DomainID         string     `json:"domainId,omitempty" yaml:"domainId,omitempty"`
+	Image            string     `json:"image,omitempty" yaml:"image,omitempty"`
+	LastUserActivity *time.Time `json:"lastUserActivity,omitempty" yaml:"lastUserActivity,omitempty"`
+
+	Tags     map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Cost     Cost              `json:"cost" yaml:"cost"`
+	Timeline []Event           `json:"timeline,omitempty" yaml:"timeline,omitempty"`
+	// Warnings lists what could not be described, e.g. a deleted model or tags without permission
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Network holds the network settings of a resource
+type Network struct {
+	VpcID                string   `json:"vpcId,omitempty" yaml:"vpcId,omitempty"`
+	SubnetIDs            []string `json:"subnetIds,omitempty" yaml:"subnetIds,omitempty"`
+	SecurityGroupIDs     []string `json:"securityGroupIds,omitempty" yaml:"securityGroupIds,omitempty"`
+	DirectInternetAccess string   `json:"directInternetAccess,omitempty" yaml:"directInternetAccess,omitempty"`
+	AppNetworkAccess     string   `json:"appNetworkAccess,omitempty" yaml:"appNetworkAccess,omitempty"`
+	NetworkIsolation     bool     `json:"networkIsolation,omitempty" yaml:"networkIsolation,omitempty"`
+}
+
+// empty reports whether no network setting is known
+func (n Network) empty() bool {
+	return n.VpcID == "" && len(n.SubnetIDs) == 0 && len(n.SecurityGroupIDs) == 0 &&
+		n.DirectInternetAccess == "" && n.AppNetworkAccess == "" && !n.NetworkIsolation
+}
+
+// Variant is a production variant of an endpoint with its estimated cost
+type Variant struct {
+	Name                 string  `json:"name" yaml:"name"`
+	ModelName            string  `json:"modelName,omitempty" yaml:"modelName,omitempty"`
+	InstanceType         string  `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	CurrentInstanceCount int     `json:"currentInstanceCount" yaml:"currentInstanceCount"`
+	DesiredInstanceCount int     `json:"desiredInstanceCount" yaml:"desiredInstanceCount"`
+	Weight               float64 `json:"weight" yaml:"weight"`
+	Serverless           string  `json:"serverless,omitempty" yaml:"serverless,omitempty"`
+	HourlyCost           float64 `json:"estimatedHourlyCost" yaml:"estimatedHourlyCost"`
+}
+
+// Cost projects the estimated hourly cost of a resource
+type Cost struct {
+	// Billed is false for resources that cost nothing in their status, e.g. stopped notebooks
+	Billed       bool    `json:"billed" yaml:"billed"`
+	Hourly       float64 `json:"estimatedHourlyCost" yaml:"estimatedHourlyCost"`
+	Daily        float64 `json:"estimatedDailyCost" yaml:"estimatedDailyCost"`
+	Monthly      float64 `json:"estimatedMonthlyCost" yaml:"estimatedMonthlyCost"`
+	SinceCreated float64 `json:"estimatedCostSinceCreated" yaml:"estimatedCostSinceCreated"`
+}
+
+// NewCost projects an hourly cost over a day and a month, and estimates what a resource that
+// has been running for runningSeconds cost since it was created, assuming it was billed the
+// whole time
+func NewCost(status string, hourly float64, runningSeconds int64) Cost {
+	if !pricing.IsBilled(status) {
+		return Cost{}
+	}
+	return Cost{
+		Billed:       true,
+		Hourly:       hourly,
+		Daily:        hourly * 24,
+		Monthly:      hourly * pricing.HoursPerMonth,
+		SinceCreated: hourly * float64(runningSeconds) / 3600,
+	}
+}
+
+// Event is a point in a resource's timeline
+type Event struct {
+	Time        time.Time `json:"time" yaml:"time"`
+	Description string    `json:"description" yaml:"description"`
+}
+
+// detailLabelWidth aligns the values of the table form
+const detailLabelWidth = 22
+
+// PrintDetail outputs a resource description as labeled sections, or as a JSON or YAML document
+func (p *Printer) PrintDetail(d Detail) {
+	switch {
+	case p.useJSON:
+		jsonData, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Fprintln(p.output, string(jsonData))
+		return
+	case p.useYAML:
+		encoder := yaml.NewEncoder(p.output)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d); err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling YAML: %v\n", err)
+		}
+		encoder.Close()
+		return
+	}
+
+	heading := newColor(p.colorEnabled, color.FgGreen, color.Bold).SprintFunc()
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(p.output, "%-*s %s\n", detailLabelWidth, label, value)
+		}
+	}
+	section := func(title string) {
+		fmt.Fprintf(p.output, "\n%s\n", heading(title))
+	}
+
+	fmt.Fprintf(p.output, "%s\n", heading(strings.TrimSpace(fmt.Sprintf("%s %s %s", d.ResourceType, d.Name, parenthesize(d.Region)))))
+	status := d.Status
+	if c, ok := p.statusColors[d.Status]; ok {
+		status = c.Sprint(d.Status)
+	}
+	field("Status", status)
+	field("Failure reason", d.FailureReason)
+	field("ARN", d.Arn)
+	if d.InstanceType != "" {
+		field("Instance", fmt.Sprintf("%s x %d", d.InstanceType, d.InstanceCount))
+	}
+	if d.VolumeSizeGB > 0 {
+		field("Volume", fmt.Sprintf("%d GB", d.VolumeSizeGB))
+	}
+	if !d.CreationTime.IsZero() {
+		field("Created", fmt.Sprintf("%s (%s ago)", formatDetailTime(d.CreationTime), FormatDuration(time.Duration(d.RunningSeconds)*time.Second)))
+	}
+	if d.LastModifiedTime != nil {
+		field("Last modified", formatDetailTime(*d.LastModifiedTime))
+	}
+
+	section("Configuration")
+	field("Role", d.RoleArn)
+	field("KMS key", d.KmsKeyID)
+	field("Lifecycle config", d.LifecycleConfig)
+	field("Endpoint config", d.EndpointConfigName)
+	if d.ResourceType == "Endpoint" {
+		field("Data capture", enabledText(d.DataCapture))
+	}
+	field("URL", d.URL)
+	field("Root access", d.RootAccess)
+	field("Platform", d.PlatformIdentifier)
+	field("Code repository", d.DefaultCodeRepository)
+	field("App", strings.TrimSpace(fmt.Sprintf("%s %s", d.AppName, parenthesize(d.StudioType))))
+	field("User profile", d.UserProfile)
+	field("Space", d.SpaceName)
+	field("Domain", d.DomainID)
+	field("Image", d.Image)
+	if d.LastUserActivity != nil {
+		field("Last user activity", formatDetailTime(*d.LastUserActivity))
+	}
+
+	if len(d.Variants) > 0 {
+		section("Variants")
+		fmt.Fprintf(p.output, "%-20s %-30s %-17s %-9s %-7s %s\n", "Name", "Model", "Instance", "Instances", "Weight", "Hourly Cost")
+		for _, v := range d.Variants {
+			instance := v.InstanceType
+			if v.Serverless != "" {
+				instance = "serverless"
+			}
+			count := fmt.Sprint(v.CurrentInstanceCount)
+			if v.DesiredInstanceCount != v.CurrentInstanceCount {
+				count = fmt.Sprintf("%d->%d", v.CurrentInstanceCount, v.DesiredInstanceCount)
+			}
+			fmt.Fprintf(p.output, "%-20s %-30s %-17s %-9s %-7.2f $%.2f\n",
+				TruncateString(v.Name, 19), TruncateString(v.ModelName, 29), instance, count, v.Weight, v.HourlyCost)
+			if v.Serverless != "" {
+				fmt.Fprintf(p.output, "%-20s %s\n", "", v.Serverless)
+			}
+		}
+	}
+
+	if !d.Network.empty() {
+		section("Network")
+		field("VPC", d.Network.VpcID)
+		field("Subnets", strings.Join(d.Network.SubnetIDs, ", "))
+		field("Security groups", strings.Join(d.Network.SecurityGroupIDs, ", "))
+		field("Direct internet access", d.Network.DirectInternetAccess)
+		field("App network access", d.Network.AppNetworkAccess)
+		if d.Network.NetworkIsolation {
+			field("Network isolation", "Enabled")
+		}
+	}
+
+	if len(d.Tags) > 0 {
+		section("Tags")
+		keys := make([]string, 0, len(d.Tags))
+		for key := range d.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field(key, d.Tags[key])
+		}
+	}
+
+	section("Cost")
+	if d.Cost.Billed {
+		field("Hourly", fmt.Sprintf("$%.2f", d.Cost.Hourly))
+		field("Daily", fmt.Sprintf("$%.2f", d.Cost.Daily))
+		field("Monthly", fmt.Sprintf("$%.2f", d.Cost.Monthly))
+		field("Since created", fmt.Sprintf("$%.2f", d.Cost.SinceCreated))
+	} else {
+		fmt.Fprintf(p.output, "Not billed while %s\n", d.Status)
+	}
+
+	if len(d.Timeline) > 0 {
+		section("Timeline")
+		for _, event := range d.Timeline {
+			fmt.Fprintf(p.output, "%-*s %s\n", detailLabelWidth, formatDetailTime(event.Time), event.Description)
+		}
+	}
+
+	if len(d.Warnings) > 0 {
+		section("Warnings")
+		for _, warning := range d.Warnings {
+			fmt.Fprintln(p.output, warning)
+		}
+	}
+}
+
+func formatDetailTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func enabledText(enabled bool) string {
+	if enabled {
+		return "Enabled"
+	}
+	return "Disabled"
+}
+
+func parenthesize(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "(" + s + ")"
+}
@@ -0,0 +1,138 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestDetail() Detail {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	modified := created.Add(2 * time.Hour)
+	return Detail{
+		ResourceType:       "Endpoint",
+		Name:               "serving",
+		Arn:                "arn:aws:sagemaker:us-east-1:123456789012:endpoint/serving",
+		Region:             "us-east-1",
+		Status:             "InService",
+		InstanceType:       "ml.m5.large",
+		InstanceCount:      2,
+		CreationTime:       created,
+		LastModifiedTime:   &modified,
+		RoleArn:            "arn:aws:iam::123456789012:role/inference",
+		EndpointConfigName: "serving-config",
+		Network:            Network{VpcID: "vpc-1", SubnetIDs: []string{"subnet-1", "subnet-2"}, NetworkIsolation: true},
+		Variants: []Variant{
+			{Name: "primary", ModelName: "model-v1", InstanceType: "ml.m5.large", CurrentInstanceCount: 2, DesiredInstanceCount: 2, Weight: 1, HourlyCost: 0.23},
+		},
+		Tags:     map[string]string{"team": "ml", "env": "prod"},
+		Cost:     NewCost("InService", 0.23, 3600*10),
+		Timeline: []Event{{Time: created, Description: "Created"}, {Time: modified, Description: "Last modified, status InService"}},
+		Warnings: []string{"failed to describe model model-v1: access denied"},
+	}
+}
+
+func TestNewCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		hourly   float64
+		running  int64
+		expected Cost
+	}{
+		{
+			name:     "billed",
+			status:   "InService",
+			hourly:   2,
+			running:  1800,
+			expected: Cost{Billed: true, Hourly: 2, Daily: 48, Monthly: 1460, SinceCreated: 1},
+		},
+		{
+			name:     "not billed",
+			status:   "Stopped",
+			hourly:   2,
+			running:  1800,
+			expected: Cost{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewCost(tt.status, tt.hourly, tt.running))
+		})
+	}
+}
+
+func TestPrintDetail(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.PrintDetail(newTestDetail())
+
+		output := buf.String()
+		for _, want := range []string{
+			"Endpoint serving (us-east-1)\n",
+			"Status                 InService\n",
+			"Created                2024-03-01T09:00:00Z (",
+			"Last modified          2024-03-01T11:00:00Z\n",
+			"Role                   arn:aws:iam::123456789012:role/inference\n",
+			"Endpoint config        serving-config\n",
+			"primary",
+			"VPC                    vpc-1\n",
+			"Subnets                subnet-1, subnet-2\n",
+			"Network isolation      Enabled\n",
+			"env                    prod\n",
+			"Hourly                 $0.23\n",
+			"Since created          $2.30\n",
+			"Last modified, status InService\n",
+			"failed to describe model model-v1: access denied\n",
+		} {
+			assert.Contains(t, output, want)
+		}
+		// Tags are sorted by key
+		assert.Less(t, bytes.Index(buf.Bytes(), []byte("env ")), bytes.Index(buf.Bytes(), []byte("team ")))
+	})
+
+	t.Run("not billed without optional sections", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.PrintDetail(Detail{ResourceType: "Notebook", Name: "dev", Status: "Stopped", InstanceType: "ml.t3.medium", InstanceCount: 1})
+
+		output := buf.String()
+		assert.Contains(t, output, "Not billed while Stopped\n")
+		for _, notWant := range []string{"Variants\n", "Network\n", "Tags\n", "Timeline\n", "Warnings\n"} {
+			assert.NotContains(t, output, notWant)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{useJSON: true, output: &buf}
+		printer.PrintDetail(newTestDetail())
+
+		var result map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, "serving", result["name"])
+		assert.Equal(t, "2024-03-01T11:00:00Z", result["lastModifiedTime"])
+		assert.NotContains(t, result, "lastUserActivity")
+		cost := result["cost"].(map[string]interface{})
+		assert.InDelta(t, 2.3, cost["estimatedCostSinceCreated"], 0.0001)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.SetFormat(OutputYAML)
+		printer.PrintDetail(newTestDetail())
+
+		var result Detail
+		assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, newTestDetail(), result)
+		assert.Contains(t, buf.String(), "resourceType: Endpoint\n")
+		assert.Contains(t, buf.String(), "  subnetIds:\n    - subnet-1\n")
+	})
+}
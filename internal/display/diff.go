@@ -0,0 +1,261 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"mohua/internal/pricing"
+)
+
+// Kinds of resource differences
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// FieldChange is a single field whose value differs between two inventories
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ResourceDiff describes a resource that was added, removed or changed
+type ResourceDiff struct {
+	Change       string        `json:"change"`
+	ResourceType string        `json:"resourceType"`
+	Name         string        `json:"name"`
+	Region       string        `json:"region,omitempty"`
+	Old          *ResourceInfo `json:"old,omitempty"`
+	New          *ResourceInfo `json:"new,omitempty"`
+	Fields       []FieldChange `json:"fields,omitempty"`
+	// HourlyCostDelta is the new estimated hourly cost minus the old one
+	HourlyCostDelta float64 `json:"estimatedHourlyCostDelta"`
+}
+
+// Diff is the difference between two inventories
+type Diff struct {
+	// Old and New describe where each inventory came from, e.g. a file name or "live"
+	Old              string         `json:"old"`
+	New              string         `json:"new"`
+	Resources        []ResourceDiff `json:"resources"`
+	Added            int            `json:"added"`
+	Removed          int            `json:"removed"`
+	Changed          int            `json:"changed"`
+	Unchanged        int            `json:"unchanged"`
+	OldHourlyCost    float64        `json:"oldEstimatedHourlyCost"`
+	NewHourlyCost    float64        `json:"newEstimatedHourlyCost"`
+	HourlyCostDelta  float64        `json:"estimatedHourlyCostDelta"`
+	MonthlyCostDelta float64        `json:"estimatedMonthlyCostDelta"`
+}
+
+// HasChanges reports whether any resource was added, removed or changed
+func (d Diff) HasChanges() bool {
+	return len(d.Resources) > 0
+}
+
+// resourceKey identifies a resource across inventories. A user profile can have several
+// Studio apps of a type, and space apps have no user profile, so Studio apps are also told
+// apart by their domain, space and app name.
+type resourceKey struct {
+	resourceType, name, region   string
+	domainID, spaceName, appName string
+}
+
+// keyOf returns the key of a resource; studio leaves out the Studio app fields for
+// inventories saved before they were recorded
+func keyOf(info ResourceInfo, studio bool) resourceKey {
+	key := resourceKey{resourceType: info.ResourceType, name: info.Name, region: info.Region}
+	if studio {
+		key.domainID, key.spaceName, key.appName = info.DomainID, info.SpaceName, info.AppName
+	}
+	return key
+}
+
+// hasAppNames reports whether every Studio app of an inventory has its app name recorded
+func hasAppNames(resources []ResourceInfo) bool {
+	for _, info := range resources {
+		if info.ResourceType == "Studio" && info.AppName == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare matches resources by type, name and region, and Studio apps also by app name and
+// space, and reports the added, removed and changed ones; a change is a different status,
+// instance type or instance count
+func Compare(oldResources, newResources []ResourceInfo) Diff {
+	d := Diff{Resources: []ResourceDiff{}}
+	studio := hasAppNames(oldResources) && hasAppNames(newResources)
+	oldByKey := make(map[resourceKey]ResourceInfo, len(oldResources))
+	for _, info := range oldResources {
+		oldByKey[keyOf(info, studio)] = info
+		d.OldHourlyCost += info.HourlyCost
+	}
+
+	seen := make(map[resourceKey]bool, len(newResources))
+	for _, info := range newResources {
+		key := keyOf(info, studio)
+		seen[key] = true
+		d.NewHourlyCost += info.HourlyCost
+
+		old, ok := oldByKey[key]
+		if !ok {
+			d.Added++
+			d.Resources = append(d.Resources, newResourceDiff(ChangeAdded, nil, &info))
+			continue
+		}
+		fields := changedFields(old, info)
+		if len(fields) == 0 {
+			d.Unchanged++
+			continue
+		}
+		d.Changed++
+		rd := newResourceDiff(ChangeChanged, &old, &info)
+		rd.Fields = fields
+		d.Resources = append(d.Resources, rd)
+	}
+	for _, info := range oldResources {
+		if !seen[keyOf(info, studio)] {
+			d.Removed++
+			d.Resources = append(d.Resources, newResourceDiff(ChangeRemoved, &info, nil))
+		}
+	}
+
+	sort.SliceStable(d.Resources, func(i, j int) bool {
+		a, b := d.Resources[i], d.Resources[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Region < b.Region
+	})
+	d.HourlyCostDelta = d.NewHourlyCost - d.OldHourlyCost
+	d.MonthlyCostDelta = d.HourlyCostDelta * pricing.HoursPerMonth
+	return d
+}
+
+func newResourceDiff(change string, before, after *ResourceInfo) ResourceDiff {
+	rd := ResourceDiff{Change: change, Old: before, New: after}
+	info := after
+	if info == nil {
+		info = before
+	}
+	rd.ResourceType, rd.Name, rd.Region = info.ResourceType, diffName(*info), info.Region
+	if after != nil {
+		rd.HourlyCostDelta += after.HourlyCost
+	}
+	if before != nil {
+		rd.HourlyCostDelta -= before.HourlyCost
+	}
+	return rd
+}
+
+// diffName names a resource in a diff; Studio apps add their app name and space, as a user
+// profile can have several apps of a type, e.g. alice/KernelGateway/datascience-1-0 or
+// /JupyterLab/default (space team)
+func diffName(info ResourceInfo) string {
+	if info.AppName == "" {
+		return info.Name
+	}
+	name := info.Name + "/" + info.AppName
+	if info.SpaceName != "" {
+		name += " (space " + info.SpaceName + ")"
+	}
+	return name
+}
+
+// changedFields lists the compared fields that differ between before and after
+func changedFields(before, after ResourceInfo) []FieldChange {
+	var fields []FieldChange
+	if before.Status != after.Status {
+		fields = append(fields, FieldChange{Field: "status", Old: before.Status, New: after.Status})
+	}
+	if before.InstanceType != after.InstanceType {
+		fields = append(fields, FieldChange{Field: "instanceType", Old: before.InstanceType, New: after.InstanceType})
+	}
+	if before.InstanceCount != after.InstanceCount {
+		fields = append(fields, FieldChange{Field: "instanceCount", Old: fmt.Sprint(before.InstanceCount), New: fmt.Sprint(after.InstanceCount)})
+	}
+	return fields
+}
+
+// PrintDiff outputs a diff as a table, colored by kind of change, or as a JSON document
+func (p *Printer) PrintDiff(d Diff) {
+	if p.useJSON {
+		jsonData, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Fprintln(p.output, string(jsonData))
+		return
+	}
+
+	fmt.Fprintf(p.output, "Comparing %s with %s\n\n", d.Old, d.New)
+	if d.HasChanges() {
+		headerFmt := newColor(p.colorEnabled, color.FgGreen, color.Bold).SprintfFunc()
+		fmt.Fprintf(p.output, "%s\n", headerFmt("%-10s %-15s %-30s %-15s %-50s %s", "Change", "Type", "Name", "Region", "Details", "Hourly Cost"))
+		fmt.Fprintln(p.output, strings.Repeat("-", 120))
+		changeColors := map[string]*color.Color{
+			ChangeAdded:   newColor(p.colorEnabled, color.FgGreen),
+			ChangeRemoved: newColor(p.colorEnabled, color.FgRed),
+			ChangeChanged: newColor(p.colorEnabled, color.FgYellow),
+		}
+		for _, rd := range d.Resources {
+			row := fmt.Sprintf("%-10s %-15s %-30s %-15s %-50s %s",
+				rd.Change,
+				rd.ResourceType,
+				TruncateString(rd.Name, 29),
+				rd.Region,
+				TruncateString(diffDetails(rd), 49),
+				formatCostDelta(rd.HourlyCostDelta),
+			)
+			fmt.Fprintln(p.output, changeColors[rd.Change].Sprint(row))
+		}
+		fmt.Fprintln(p.output, strings.Repeat("-", 120))
+	}
+
+	fmt.Fprintf(p.output, "Added: %d  Removed: %d  Changed: %d  Unchanged: %d\n", d.Added, d.Removed, d.Changed, d.Unchanged)
+	fmt.Fprintf(p.output, "Hourly cost: $%.2f -> $%.2f (%s/hour, %s/month)\n",
+		d.OldHourlyCost, d.NewHourlyCost, formatCostDelta(d.HourlyCostDelta), formatCostDelta(d.MonthlyCostDelta))
+}
+
+// diffDetails summarizes a resource difference for the table
+func diffDetails(rd ResourceDiff) string {
+	switch rd.Change {
+	case ChangeAdded:
+		return resourceDetails(*rd.New)
+	case ChangeRemoved:
+		return resourceDetails(*rd.Old)
+	}
+	var details []string
+	for _, f := range rd.Fields {
+		details = append(details, fmt.Sprintf("%s %s -> %s", f.Field, f.Old, f.New))
+	}
+	return strings.Join(details, ", ")
+}
+
+func resourceDetails(info ResourceInfo) string {
+	details := info.Status
+	if info.InstanceType != "" {
+		details += fmt.Sprintf(" %s x%d", info.InstanceType, info.InstanceCount)
+	}
+	return details
+}
+
+// formatCostDelta formats a signed dollar amount, e.g. +$1.20 or -$0.05
+func formatCostDelta(delta float64) string {
+	if delta < 0 {
+		return fmt.Sprintf("-$%.2f", -delta)
+	}
+	return fmt.Sprintf("+$%.2f", delta)
+}
@@ -0,0 +1,142 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	oldResources := []ResourceInfo{
+		{ResourceType: "Endpoint", Name: "prod", Region: "us-east-1", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1, HourlyCost: 0.1},
+		{ResourceType: "Endpoint", Name: "prod", Region: "eu-west-1", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1, HourlyCost: 0.1},
+		{ResourceType: "Notebook", Name: "old", Region: "us-east-1", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, HourlyCost: 0.05},
+	}
+	newResources := []ResourceInfo{
+		// Only the running time differs, which is not a change
+		{ResourceType: "Endpoint", Name: "prod", Region: "eu-west-1", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1, RunningTime: "2h", HourlyCost: 0.1},
+		{ResourceType: "Endpoint", Name: "prod", Region: "us-east-1", Status: "Updating", InstanceType: "ml.m5.xlarge", InstanceCount: 2, HourlyCost: 0.4},
+		{ResourceType: "Studio", Name: "alice/JupyterLab", Region: "us-east-1", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 1, HourlyCost: 1.5},
+	}
+
+	d := Compare(oldResources, newResources)
+	assert.True(t, d.HasChanges())
+	assert.Equal(t, 1, d.Added)
+	assert.Equal(t, 1, d.Removed)
+	assert.Equal(t, 1, d.Changed)
+	assert.Equal(t, 1, d.Unchanged)
+	assert.InDelta(t, 0.25, d.OldHourlyCost, 1e-9)
+	assert.InDelta(t, 2.0, d.NewHourlyCost, 1e-9)
+	assert.InDelta(t, 1.75, d.HourlyCostDelta, 1e-9)
+	assert.InDelta(t, 1.75*730, d.MonthlyCostDelta, 1e-9)
+
+	// Sorted by type, name and region
+	if assert.Len(t, d.Resources, 3) {
+		changed := d.Resources[0]
+		assert.Equal(t, ChangeChanged, changed.Change)
+		assert.Equal(t, "us-east-1", changed.Region)
+		assert.Equal(t, []FieldChange{
+			{Field: "status", Old: "InService", New: "Updating"},
+			{Field: "instanceType", Old: "ml.m5.large", New: "ml.m5.xlarge"},
+			{Field: "instanceCount", Old: "1", New: "2"},
+		}, changed.Fields)
+		assert.InDelta(t, 0.3, changed.HourlyCostDelta, 1e-9)
+
+		assert.Equal(t, ChangeRemoved, d.Resources[1].Change)
+		assert.Equal(t, "old", d.Resources[1].Name)
+		assert.Nil(t, d.Resources[1].New)
+		assert.InDelta(t, -0.05, d.Resources[1].HourlyCostDelta, 1e-9)
+
+		assert.Equal(t, ChangeAdded, d.Resources[2].Change)
+		assert.Nil(t, d.Resources[2].Old)
+	}
+
+	d = Compare(oldResources, oldResources)
+	assert.False(t, d.HasChanges())
+	assert.Equal(t, 3, d.Unchanged)
+	assert.NotNil(t, d.Resources)
+}
+
+func TestCompare_StudioApps(t *testing.T) {
+	app := func(profile, appType, space, appName, status string) ResourceInfo {
+		return ResourceInfo{ResourceType: "Studio", Name: profile + "/" + appType, Region: "us-east-1", DomainID: "d-1", SpaceName: space, AppName: appName, Status: status}
+	}
+	oldResources := []ResourceInfo{
+		app("alice", "KernelGateway", "", "datascience", "InService"),
+		app("alice", "KernelGateway", "", "pytorch", "InService"),
+		app("", "JupyterLab", "team-a", "default", "InService"),
+	}
+	newResources := []ResourceInfo{
+		app("alice", "KernelGateway", "", "datascience", "InService"),
+		app("", "JupyterLab", "team-a", "default", "Deleting"),
+		app("", "JupyterLab", "team-b", "default", "InService"),
+	}
+
+	// Apps sharing a display name are still told apart
+	d := Compare(oldResources, newResources)
+	assert.Equal(t, 1, d.Added)
+	assert.Equal(t, 1, d.Removed)
+	assert.Equal(t, 1, d.Changed)
+	assert.Equal(t, 1, d.Unchanged)
+	if assert.Len(t, d.Resources, 3) {
+		names := map[string]string{}
+		for _, rd := range d.Resources {
+			names[rd.Change] = rd.Name
+		}
+		assert.Equal(t, map[string]string{
+			ChangeAdded:   "/JupyterLab/default (space team-b)",
+			ChangeRemoved: "alice/KernelGateway/pytorch",
+			ChangeChanged: "/JupyterLab/default (space team-a)",
+		}, names)
+	}
+
+	// Inventories saved without app names are matched on the display name
+	legacy := []ResourceInfo{{ResourceType: "Studio", Name: "alice/KernelGateway", Region: "us-east-1", Status: "InService"}}
+	d = Compare(legacy, newResources[:1])
+	assert.False(t, d.HasChanges())
+}
+
+func TestPrintDiff(t *testing.T) {
+	d := Compare(
+		[]ResourceInfo{{ResourceType: "Notebook", Name: "old", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, HourlyCost: 0.05}},
+		[]ResourceInfo{{ResourceType: "Endpoint", Name: "new", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 2, HourlyCost: 0.23}},
+	)
+	d.Old, d.New = "before.json", "live"
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.PrintDiff(d)
+		out := buf.String()
+		assert.Contains(t, out, "Comparing before.json with live")
+		assert.Regexp(t, `added\s+Endpoint\s+new\s+InService ml\.m5\.large x2\s+\+\$0\.23`, out)
+		assert.Regexp(t, `removed\s+Notebook\s+old\s+InService ml\.t3\.medium x1\s+-\$0\.05`, out)
+		assert.Contains(t, out, "Added: 1  Removed: 1  Changed: 0  Unchanged: 0")
+		assert.Contains(t, out, "Hourly cost: $0.05 -> $0.23 (+$0.18/hour, +$131.40/month)")
+	})
+
+	t.Run("colored", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf, colorEnabled: true}
+		printer.PrintDiff(d)
+		assert.Contains(t, buf.String(), "\x1b[32madded")
+		assert.Contains(t, buf.String(), "\x1b[31mremoved")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{useJSON: true, output: &buf}
+		printer.PrintDiff(d)
+		var got Diff
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, d, got)
+	})
+}
+
+func TestFormatCostDelta(t *testing.T) {
+	assert.Equal(t, "+$1.20", formatCostDelta(1.2))
+	assert.Equal(t, "-$0.05", formatCostDelta(-0.05))
+	assert.Equal(t, "+$0.00", formatCostDelta(0))
+}
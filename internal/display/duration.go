@@ -0,0 +1,68 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Supported running time formats for table output
+const (
+	TimeFormatRelative = "relative"
+	TimeFormatISO      = "iso"
+	TimeFormatSeconds  = "seconds"
+)
+
+// ValidateTimeFormat checks that the given time format is supported
+func ValidateTimeFormat(format string) error {
+	switch format {
+	case TimeFormatRelative, TimeFormatISO, TimeFormatSeconds:
+		return nil
+	}
+	return fmt.Errorf("invalid time format %q: must be one of relative, iso or seconds", format)
+}
+
+// FormatDuration renders a duration as days, hours and minutes, e.g. "7d 0h 30m"
+func FormatDuration(d time.Duration) string {
+	days, hours, minutes := splitDuration(d)
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// formatISODuration renders a duration as an ISO 8601 duration in days, hours and minutes,
+// e.g. "P7DT0H30M"
+func formatISODuration(d time.Duration) string {
+	days, hours, minutes := splitDuration(d)
+	if days > 0 {
+		return fmt.Sprintf("P%dDT%dH%dM", days, hours, minutes)
+	}
+	return fmt.Sprintf("PT%dH%dM", hours, minutes)
+}
+
+// splitDuration splits a duration into whole days, hours and minutes; negative durations are zero
+func splitDuration(d time.Duration) (days, hours, minutes int64) {
+	if d < 0 {
+		d = 0
+	}
+	totalMinutes := int64(d / time.Minute)
+	return totalMinutes / (24 * 60), (totalMinutes / 60) % 24, totalMinutes % 60
+}
+
+// FormatRunningTime renders how long a resource created at creationTime has been running as of now.
+// Resources without a known creation time are shown as "-".
+func FormatRunningTime(creationTime, now time.Time, format string) string {
+	if creationTime.IsZero() {
+		return "-"
+	}
+
+	switch format {
+	case TimeFormatISO:
+		return formatISODuration(now.Sub(creationTime))
+	case TimeFormatSeconds:
+		return strconv.FormatInt(int64(now.Sub(creationTime)/time.Second), 10)
+	default:
+		return FormatDuration(now.Sub(creationTime))
+	}
+}
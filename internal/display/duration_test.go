@@ -0,0 +1,61 @@
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected string
+	}{
+		{name: "zero", duration: 0, expected: "0h 0m"},
+		{name: "negative", duration: -time.Hour, expected: "0h 0m"},
+		{name: "minutes only", duration: 45*time.Minute + 30*time.Second, expected: "0h 45m"},
+		{name: "hours and minutes", duration: 5*time.Hour + 3*time.Minute, expected: "5h 3m"},
+		{name: "exactly one day", duration: 24 * time.Hour, expected: "1d 0h 0m"},
+		{name: "week with remainder", duration: 168*time.Hour + 30*time.Minute + 12345678912, expected: "7d 0h 30m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatDuration(tt.duration))
+		})
+	}
+}
+
+func TestFormatRunningTime(t *testing.T) {
+	now := time.Date(2025, 2, 8, 12, 30, 0, 0, time.UTC)
+	created := now.Add(-(72*time.Hour + 15*time.Minute))
+
+	tests := []struct {
+		name     string
+		created  time.Time
+		format   string
+		expected string
+	}{
+		{name: "relative", created: created, format: TimeFormatRelative, expected: "3d 0h 15m"},
+		{name: "iso", created: created, format: TimeFormatISO, expected: "P3DT0H15M"},
+		{name: "iso under a day", created: now.Add(-(5*time.Hour + 3*time.Minute)), format: TimeFormatISO, expected: "PT5H3M"},
+		{name: "iso created in the future", created: now.Add(time.Minute), format: TimeFormatISO, expected: "PT0H0M"},
+		{name: "seconds", created: created, format: TimeFormatSeconds, expected: "260100"},
+		{name: "unknown creation time", created: time.Time{}, format: TimeFormatRelative, expected: "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatRunningTime(tt.created, now, tt.format))
+		})
+	}
+}
+
+func TestValidateTimeFormat(t *testing.T) {
+	assert.NoError(t, ValidateTimeFormat(TimeFormatRelative))
+	assert.NoError(t, ValidateTimeFormat(TimeFormatISO))
+	assert.NoError(t, ValidateTimeFormat(TimeFormatSeconds))
+	assert.Error(t, ValidateTimeFormat("unix"))
+}
@@ -0,0 +1,374 @@
+package display
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTMLPage is a self-contained HTML document with summary cards, bar charts and sortable,
+// filterable tables; styles and scripts are inlined so the file can be mailed or archived
+type HTMLPage struct {
+	Title    string
+	Subtitle string
+	Cards    []HTMLCard
+	// Violations and Warnings are listed below the cards in red and yellow
+	Violations []string
+	Warnings   []string
+	Charts     []HTMLChart
+	Tables     []HTMLTable
+	Notes      []string
+}
+
+// HTMLCard is a headline figure, e.g. the total hourly cost
+type HTMLCard struct {
+	Label string
+	Value string
+	Alert bool
+}
+
+// HTMLChart is a horizontal bar chart
+type HTMLChart struct {
+	Title string
+	Bars  []HTMLBar
+}
+
+// HTMLBar is one bar of a chart; Text is the label of the value, e.g. "$1.23/hour"
+type HTMLBar struct {
+	Label string
+	Value float64
+	Text  string
+}
+
+// HTMLTable is a table whose rows can be sorted by clicking a header and filtered by text
+type HTMLTable struct {
+	Title   string
+	Columns []HTMLColumn
+	Rows    []HTMLRow
+}
+
+// HTMLColumn is a table header; numeric columns sort by number and are right-aligned
+type HTMLColumn struct {
+	Header  string
+	Numeric bool
+}
+
+// HTMLRow is a table row; highlighted rows are shaded, e.g. possibly idle resources
+type HTMLRow struct {
+	Cells     []HTMLCell
+	Highlight bool
+}
+
+// HTMLCell is a table cell; Sort, when set, is the value sorted on instead of Text
+type HTMLCell struct {
+	Text string
+	Sort string
+}
+
+// percent returns the width of a bar relative to the largest bar of the chart
+func (c HTMLChart) percent(value float64) float64 {
+	var largest float64
+	for _, bar := range c.Bars {
+		largest = max(largest, bar.Value)
+	}
+	if largest <= 0 {
+		return 0
+	}
+	return value / largest * 100
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"width": func(c HTMLChart, value float64) string {
+		return strconv.FormatFloat(c.percent(value), 'f', 1, 64) + "%"
+	},
+	"sortKey": func(cell HTMLCell) string {
+		if cell.Sort != "" {
+			return cell.Sort
+		}
+		return cell.Text
+	},
+	"numeric": func(columns []HTMLColumn, i int) bool {
+		return i < len(columns) && columns[i].Numeric
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; color: #222; margin: 24px; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+h2 { font-size: 17px; margin-top: 28px; }
+.subtitle { color: #666; }
+.cards { display: flex; flex-wrap: wrap; gap: 12px; margin: 16px 0; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 10px 16px; min-width: 120px; }
+.card .value { font-size: 20px; font-weight: bold; }
+.card .label { color: #666; }
+.card.alert { border-color: #c00; color: #c00; }
+.violations li { color: #c00; }
+.warnings li { color: #a60; }
+.charts { display: flex; flex-wrap: wrap; gap: 32px; }
+.chart { min-width: 320px; flex: 1; }
+.bar-row { display: flex; align-items: center; margin: 3px 0; }
+.bar-label { width: 160px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.bar-track { flex: 1; background: #f2f2f2; margin: 0 8px; }
+.bar { background: #4a7fc1; height: 14px; }
+.bar-text { width: 110px; text-align: right; white-space: nowrap; }
+input.filter { margin: 4px 0 8px; padding: 4px 6px; width: 260px; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 10px; border-bottom: 1px solid #e5e5e5; text-align: left; white-space: nowrap; }
+th { background: #f5f5f5; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+.num { text-align: right; }
+tr.highlight td { background: #fff4d6; }
+.notes { color: #666; margin-top: 24px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Subtitle}}
+<div class="subtitle">{{.Subtitle}}</div>
+{{- end}}
+<div class="cards">
+{{- range .Cards}}
+<div class="card{{if .Alert}} alert{{end}}"><div class="value">{{.Value}}</div><div class="label">{{.Label}}</div></div>
+{{- end}}
+</div>
+{{- if .Violations}}
+<h2>Threshold violations</h2>
+<ul class="violations">
+{{- range .Violations}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Warnings}}
+<ul class="warnings">
+{{- range .Warnings}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Charts}}
+<div class="charts">
+{{- range $chart := .Charts}}
+<div class="chart">
+<h2>{{$chart.Title}}</h2>
+{{- range $chart.Bars}}
+<div class="bar-row"><div class="bar-label" title="{{.Label}}">{{.Label}}</div><div class="bar-track"><div class="bar" style="width: {{width $chart .Value}}"></div></div><div class="bar-text">{{.Text}}</div></div>
+{{- end}}
+</div>
+{{- end}}
+</div>
+{{- end}}
+{{- range .Tables}}
+<h2>{{.Title}} ({{len .Rows}})</h2>
+<input class="filter" type="search" placeholder="Filter rows">
+<table class="sortable">
+<thead><tr>
+{{- range .Columns}}
+<th{{if .Numeric}} class="num" data-numeric{{end}}>{{.Header}}</th>
+{{- end}}
+</tr></thead>
+<tbody>
+{{- $columns := .Columns}}
+{{- range .Rows}}
+<tr{{if .Highlight}} class="highlight"{{end}}>
+{{- range $i, $cell := .Cells}}<td{{if numeric $columns $i}} class="num"{{end}} data-sort="{{sortKey $cell}}">{{$cell.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- if .Notes}}
+<div class="notes">
+{{- range .Notes}}
+<p>{{.}}</p>
+{{- end}}
+</div>
+{{- end}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  var filter = table.previousElementSibling;
+  var body = table.tBodies[0];
+  filter.addEventListener("input", function () {
+    var text = filter.value.toLowerCase();
+    Array.prototype.forEach.call(body.rows, function (row) {
+      row.style.display = row.textContent.toLowerCase().indexOf(text) === -1 ? "none" : "";
+    });
+  });
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (header, column) {
+    header.addEventListener("click", function () {
+      var ascending = !header.classList.contains("asc");
+      var numeric = header.hasAttribute("data-numeric");
+      Array.prototype.forEach.call(header.parentNode.cells, function (h) { h.classList.remove("asc", "desc"); });
+      header.classList.add(ascending ? "asc" : "desc");
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column].getAttribute("data-sort");
+        var y = b.cells[column].getAttribute("data-sort");
+        var order = numeric ? parseFloat(x) - parseFloat(y) : x.localeCompare(y);
+        return ascending ? order : -order;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// WriteHTML renders the page
+func WriteHTML(w io.Writer, page HTMLPage) error {
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
+
+// htmlColumns are the columns of the per-type resource tables, in order
+var htmlColumns = []string{"name", "status", "instance", "instance-count", "running-time", "created", "user-profile", "region", "hourly-cost"}
+
+// htmlPage builds the page of the printed resources: totals, cost by type, instance type and
+// the group-by key, and one table per resource type with possibly idle resources highlighted
+func (p *Printer) htmlPage(region string) HTMLPage {
+	summary := Summarize(p.resources, GroupByType)
+	page := HTMLPage{
+		Title:    p.title,
+		Subtitle: htmlSubtitle(p.resources, region),
+		Cards: []HTMLCard{
+			{Label: "Resources", Value: strconv.Itoa(summary.Total.Count)},
+			{Label: "Estimated hourly cost", Value: fmt.Sprintf("$%.2f", summary.Total.HourlyCost)},
+			{Label: "Estimated monthly cost", Value: fmt.Sprintf("$%.2f", summary.Total.MonthlyCost)},
+		},
+	}
+	if page.Title == "" {
+		page.Title = "SageMaker resources"
+	}
+
+	var idle int
+	for _, info := range p.resources {
+		if IsIdle(info, DefaultIdleAfter) {
+			idle++
+		}
+	}
+	page.Cards = append(page.Cards, HTMLCard{Label: "Possibly idle", Value: strconv.Itoa(idle), Alert: idle > 0})
+	if len(p.violations) > 0 {
+		page.Cards = append(page.Cards, HTMLCard{Label: "Threshold violations", Value: strconv.Itoa(len(p.violations)), Alert: true})
+	}
+	for _, v := range p.violations {
+		page.Violations = append(page.Violations, v.Threshold+": "+v.Message)
+	}
+	for _, info := range p.errors {
+		if reason := info.incomplete(); reason != "" {
+			page.Warnings = append(page.Warnings, info.ResourceType+": "+reason)
+		} else {
+			page.Warnings = append(page.Warnings, info.ResourceType+": "+info.Message)
+		}
+	}
+
+	if len(p.resources) > 0 {
+		page.Charts = append(page.Charts, costChart("Hourly cost by type", summary))
+		page.Charts = append(page.Charts, costChart("Hourly cost by instance type", Summarize(p.resources, GroupByInstanceType)))
+		if p.groupBy != "" && p.groupBy != GroupByType && p.groupBy != GroupByInstanceType {
+			page.Charts = append(page.Charts, costChart("Hourly cost by "+p.groupBy, Summarize(p.resources, p.groupBy)))
+		}
+	}
+
+	byType := make(map[string][]ResourceInfo)
+	var types []string
+	for _, info := range p.resources {
+		if _, ok := byType[info.ResourceType]; !ok {
+			types = append(types, info.ResourceType)
+		}
+		byType[info.ResourceType] = append(byType[info.ResourceType], info)
+	}
+	for _, resourceType := range types {
+		page.Tables = append(page.Tables, resourceTable(resourceType, byType[resourceType]))
+	}
+
+	if idle > 0 {
+		page.Notes = append(page.Notes, fmt.Sprintf("Highlighted rows are notebooks and Studio apps running for more than %s, which may have been left idle.", formatHours(DefaultIdleAfter)))
+	}
+	page.Notes = append(page.Notes, "Costs are estimates from on-demand instance prices.")
+	return page
+}
+
+// htmlSubtitle describes when the page was generated and the regions of the resources
+func htmlSubtitle(resources []ResourceInfo, region string) string {
+	regions := make(map[string]bool)
+	if region != "" {
+		regions[region] = true
+	}
+	for _, info := range resources {
+		if info.Region != "" {
+			regions[info.Region] = true
+		}
+	}
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	subtitle := "Generated " + time.Now().Format("2006-01-02 15:04 MST")
+	if len(names) > 0 {
+		subtitle += " for " + strings.Join(names, ", ")
+	}
+	return subtitle
+}
+
+// costChart charts the hourly cost of each group, largest first
+func costChart(title string, summary Summary) HTMLChart {
+	groups := append([]GroupSummary(nil), summary.Groups...)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].HourlyCost > groups[j].HourlyCost
+	})
+	chart := HTMLChart{Title: title}
+	for _, group := range groups {
+		chart.Bars = append(chart.Bars, HTMLBar{
+			Label: group.Key,
+			Value: group.HourlyCost,
+			Text:  fmt.Sprintf("$%.2f/hour", group.HourlyCost),
+		})
+	}
+	return chart
+}
+
+// resourceTable lists the resources of one type; the user profile column is only shown for
+// Studio apps, which are the only resources that have one
+func resourceTable(resourceType string, resources []ResourceInfo) HTMLTable {
+	table := HTMLTable{Title: resourceType}
+	var names []string
+	for _, name := range htmlColumns {
+		if name == "user-profile" && resourceType != "Studio" {
+			continue
+		}
+		names = append(names, name)
+		table.Columns = append(table.Columns, HTMLColumn{
+			Header:  columns[name].header,
+			Numeric: name == "instance-count" || name == "running-time" || name == "hourly-cost",
+		})
+	}
+	for _, info := range resources {
+		row := HTMLRow{Highlight: IsIdle(info, DefaultIdleAfter)}
+		for _, name := range names {
+			cell := HTMLCell{Text: columns[name].value(info)}
+			switch name {
+			case "running-time":
+				cell.Sort = strconv.FormatInt(info.RunningSeconds, 10)
+			case "hourly-cost":
+				cell.Sort = strconv.FormatFloat(info.HourlyCost, 'f', -1, 64)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
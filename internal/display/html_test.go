@@ -0,0 +1,134 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{OutputTable, OutputJSON, OutputHTML} {
+		assert.NoError(t, ValidateOutputFormat(format))
+	}
+	assert.ErrorContains(t, ValidateOutputFormat("csv"), `invalid output "csv"`)
+}
+
+func TestIsIdle(t *testing.T) {
+	day := int64((24 * time.Hour).Seconds())
+	tests := []struct {
+		name string
+		info ResourceInfo
+		want bool
+	}{
+		{name: "long-running notebook", info: ResourceInfo{ResourceType: "Notebook", Status: "InService", RunningSeconds: 2 * day}, want: true},
+		{name: "long-running Studio app", info: ResourceInfo{ResourceType: "Studio", Status: "InService", RunningSeconds: 2 * day}, want: true},
+		{name: "recent notebook", info: ResourceInfo{ResourceType: "Notebook", Status: "InService", RunningSeconds: 3600}},
+		{name: "stopped notebook", info: ResourceInfo{ResourceType: "Notebook", Status: "Stopped", RunningSeconds: 2 * day}},
+		{name: "endpoint", info: ResourceInfo{ResourceType: "Endpoint", Status: "InService", RunningSeconds: 2 * day}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsIdle(tt.info, DefaultIdleAfter))
+			assert.False(t, IsIdle(tt.info, 0))
+		})
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteHTML(&buf, HTMLPage{
+		Title:      "Costs <Q1>",
+		Cards:      []HTMLCard{{Label: "Gaps", Value: "2", Alert: true}},
+		Violations: []string{"max-hourly-cost: too much"},
+		Charts: []HTMLChart{{Title: "By owner", Bars: []HTMLBar{
+			{Label: "alice", Value: 4, Text: "$4.00"},
+			{Label: "bob", Value: 1, Text: "$1.00"},
+		}}},
+		Tables: []HTMLTable{{
+			Title:   "Rows",
+			Columns: []HTMLColumn{{Header: "Name"}, {Header: "Cost", Numeric: true}},
+			Rows: []HTMLRow{
+				{Cells: []HTMLCell{{Text: "a&b"}, {Text: "$1.50", Sort: "1.5"}}, Highlight: true},
+			},
+		}},
+	})
+	assert.NoError(t, err)
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>Costs &lt;Q1&gt;</title>")
+	assert.Contains(t, out, `<div class="card alert"><div class="value">2</div><div class="label">Gaps</div></div>`)
+	assert.Contains(t, out, "<li>max-hourly-cost: too much</li>")
+	assert.Contains(t, out, `<div class="bar" style="width: 100.0%"></div>`)
+	assert.Contains(t, out, `<div class="bar" style="width: 25.0%"></div>`)
+	assert.Contains(t, out, `<th class="num" data-numeric>Cost</th>`)
+	assert.Contains(t, out, `<tr class="highlight"><td data-sort="a&amp;b">a&amp;b</td><td class="num" data-sort="1.5">$1.50</td></tr>`)
+	assert.Contains(t, out, `<input class="filter" type="search"`)
+
+	// Self-contained: no external scripts, styles or images
+	assert.Contains(t, out, "<script>")
+	assert.Contains(t, out, "<style>")
+	for _, external := range []string{"src=", "href=", "http://", "https://", "@import"} {
+		assert.NotContains(t, out, external)
+	}
+}
+
+func TestPrinterHTML(t *testing.T) {
+	day := int64((24 * time.Hour).Seconds())
+	var buf bytes.Buffer
+	printer := NewPrinter(false)
+	printer.SetOutput(&buf)
+	printer.SetFormat(OutputHTML)
+	printer.SetGroupBy(GroupByUserProfile)
+	printer.SetThresholds(Thresholds{MaxHourlyCost: 10})
+
+	printer.PrintHeader()
+	printer.PrintResource(ResourceInfo{ResourceType: "Endpoint", Name: "serving", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 2, RunningTime: "10d", RunningSeconds: 10 * day, Region: "us-west-2", HourlyCost: 2.82})
+	printer.PrintResource(ResourceInfo{ResourceType: "Notebook", Name: "exp", Status: "InService", InstanceType: "ml.p3.2xlarge", InstanceCount: 1, RunningTime: "3d", RunningSeconds: 3 * day, Region: "us-west-2", HourlyCost: 3.83})
+	printer.PrintResource(ResourceInfo{ResourceType: "Studio", Name: "alice/JupyterLab", Status: "InService", InstanceType: "ml.p3.2xlarge", InstanceCount: 1, RunningTime: "1h", RunningSeconds: 3600, UserProfile: "alice", Region: "us-west-2", HourlyCost: 3.83})
+	printer.PrintError(ErrorInfo{ResourceType: "Studio", Message: "timed out", TimedOut: true})
+	printer.PrintFooter()
+	out := buf.String()
+
+	// Nothing is written as a table
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"), out)
+	assert.NotContains(t, out, strings.Repeat("-", 120))
+
+	assert.Contains(t, out, "<title>SageMaker resources</title>")
+	assert.Regexp(t, `<div class="subtitle">Generated .* for us-west-2</div>`, out)
+	assert.Contains(t, out, `<div class="value">$10.48</div><div class="label">Estimated hourly cost</div>`)
+	assert.Contains(t, out, `<div class="card alert"><div class="value">1</div><div class="label">Possibly idle</div></div>`)
+	assert.Contains(t, out, "<li>max-hourly-cost: estimated hourly cost $10.48 exceeds $10.00</li>")
+	assert.Contains(t, out, "<li>Studio: timed out, results are incomplete</li>")
+	assert.Len(t, printer.Violations(), 1)
+
+	assert.Contains(t, out, "<h2>Hourly cost by type</h2>")
+	assert.Contains(t, out, "<h2>Hourly cost by instance type</h2>")
+	assert.Contains(t, out, `title="ml.p3.2xlarge">ml.p3.2xlarge</div><div class="bar-track"><div class="bar" style="width: 100.0%"></div></div><div class="bar-text">$7.66/hour</div>`)
+	assert.Contains(t, out, "<h2>Hourly cost by user-profile</h2>")
+
+	assert.Contains(t, out, "<h2>Endpoint (1)</h2>")
+	assert.Contains(t, out, "<h2>Notebook (1)</h2>")
+	assert.Contains(t, out, "<h2>Studio (1)</h2>")
+	assert.Contains(t, out, `<tr class="highlight"><td data-sort="exp">exp</td>`)
+	assert.Contains(t, out, `<td class="num" data-sort="259200">3d</td>`)
+	assert.Equal(t, 1, strings.Count(out, "<th>User Profile</th>"))
+	assert.Contains(t, out, "Highlighted rows are notebooks and Studio apps running for more than 24h")
+
+	// No resources still writes a page
+	buf.Reset()
+	printer = NewPrinter(false)
+	printer.SetOutput(&buf)
+	printer.SetFormat(OutputHTML)
+	printer.SetTitle("Snapshot")
+	printer.PrintNoResources("eu-west-1")
+	out = buf.String()
+	assert.Contains(t, out, "<title>Snapshot</title>")
+	assert.Contains(t, out, " for eu-west-1</div>")
+	assert.Contains(t, out, `<div class="value">0</div><div class="label">Resources</div>`)
+	assert.NotContains(t, out, "<table")
+}
@@ -0,0 +1,26 @@
+package display
+
+import (
+	"time"
+
+	"mohua/internal/pricing"
+)
+
+// DefaultIdleAfter is how long a notebook or Studio app runs before it is flagged as possibly idle
+const DefaultIdleAfter = 24 * time.Hour
+
+// interactiveTypes are the resource types someone starts by hand and forgets; endpoints are
+// expected to run for long
+var interactiveTypes = map[string]bool{
+	"Notebook": true,
+	"Studio":   true,
+}
+
+// IsIdle reports whether a billed notebook or Studio app has been running longer than idleAfter.
+// Without utilization data this is a hint that it was left running, not a measurement.
+func IsIdle(info ResourceInfo, idleAfter time.Duration) bool {
+	if idleAfter <= 0 || !interactiveTypes[info.ResourceType] || !pricing.IsBilled(info.Status) {
+		return false
+	}
+	return time.Duration(info.RunningSeconds)*time.Second > idleAfter
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -14,101 +15,343 @@ import (
 type ResourceInfo struct {
 	ResourceType  string `json:"resourceType"`
 	Name         string `json:"name"`
+	// Arn is empty for Studio apps, which are not listed with an ARN, unless their tags were fetched
+	Arn          string `json:"arn,omitempty"`
 	Status       string `json:"status"`
 	InstanceType string `json:"instanceType"`
 	RunningTime  string `json:"runningTime"`
+
+	CreationTime   time.Time `json:"creationTime"`
+	RunningSeconds int64     `json:"runningSeconds"`
+
+	InstanceCount int               `json:"instanceCount"`
+	UserProfile   string            `json:"userProfile,omitempty"`
+	// DomainID, SpaceName and AppName identify a Studio app, whose name is only its user
+	// profile and app type
+	DomainID      string            `json:"domainId,omitempty"`
+	SpaceName     string            `json:"spaceName,omitempty"`
+	AppName       string            `json:"appName,omitempty"`
+	Region        string            `json:"region,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	InstanceHours float64           `json:"instanceHours"`
+	HourlyCost    float64           `json:"estimatedHourlyCost"`
+}
+
+// ErrorInfo describes a resource type that could not be listed, including the retry metadata when known
+type ErrorInfo struct {
+	ResourceType   string  `json:"resourceType"`
+	Message        string  `json:"message"`
+	Classification string  `json:"classification,omitempty"`
+	Attempts       int     `json:"attempts,omitempty"`
+	ElapsedSeconds float64 `json:"elapsedSeconds,omitempty"`
+	TimedOut       bool    `json:"timedOut,omitempty"`
+	// Interrupted is set when the run was canceled, e.g. with Ctrl-C, rather than timed out
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// incomplete describes why the listing stopped early, or is empty if it ran to completion
+func (info ErrorInfo) incomplete() string {
+	switch {
+	case info.TimedOut:
+		return "timed out, results are incomplete"
+	case info.Interrupted:
+		return "interrupted, results are incomplete"
+	}
+	return ""
+}
+
+// Supported output formats
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputHTML  = "html"
+	// OutputYAML is only supported by describe
+	OutputYAML = "yaml"
+)
+
+// ValidateOutputFormat checks that the given output format is supported
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputJSON, OutputHTML, OutputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output %q: must be table, json, yaml or html", format)
 }
 
 // Printer handles the formatting and display of resource information
 type Printer struct {
 	useJSON bool
+	// useHTML writes a single HTML page in PrintFooter, like the JSON envelope
+	useHTML bool
+	// useYAML is only honored by PrintDetail
+	useYAML bool
+	// title is the HTML page title
+	title   string
 	output  io.Writer
-	isFirstResource bool
+	groupBy string
+	// columns are the table column names, in order; nil means DefaultColumns
+	columns []string
+	// resources collects everything printed so far for the JSON envelope and the summary footer
+	resources []ResourceInfo
+	// errors collects listing failures for the JSON envelope
+	errors []ErrorInfo
+	// thresholds are evaluated against the resources by PrintFooter, which stores the violations
+	thresholds Thresholds
+	violations []Violation
+
+	colorEnabled bool
+	palette      Palette
+	statusColors map[string]*color.Color
 }
 
 // NewPrinter creates a new printer instance
 func NewPrinter(useJSON bool) *Printer {
-	return &Printer{
+	p := &Printer{
 		useJSON: useJSON,
 		output:  os.Stdout,
-		isFirstResource: true,
+		palette: DefaultPalette,
+	}
+	p.SetColorMode(ColorAuto)
+	return p
+}
+
+// SetOutput replaces the writer the printer writes to; call it before SetColorMode, which
+// checks whether the writer is a terminal
+func (p *Printer) SetOutput(w io.Writer) {
+	p.output = w
+}
+
+// SetFormat selects table, JSON, HTML or YAML output, overriding NewPrinter's choice
+func (p *Printer) SetFormat(format string) {
+	p.useJSON = format == OutputJSON
+	p.useHTML = format == OutputHTML
+	p.useYAML = format == OutputYAML
+}
+
+// SetTitle sets the title of the HTML page
+func (p *Printer) SetTitle(title string) {
+	p.title = title
+}
+
+// tableOutput reports whether resources are written as table rows as they are printed
+func (p *Printer) tableOutput() bool {
+	return !p.useJSON && !p.useHTML
+}
+
+// SetColorMode enables or disables colored table output according to the given mode
+func (p *Printer) SetColorMode(mode string) {
+	p.colorEnabled = ColorEnabled(mode, p.output)
+	p.buildStatusColors()
+}
+
+// SetPalette replaces the status to color mapping used for table output
+func (p *Printer) SetPalette(palette Palette) {
+	p.palette = palette
+	p.buildStatusColors()
+}
+
+// buildStatusColors prepares the status colors once rather than on every row
+func (p *Printer) buildStatusColors() {
+	p.statusColors = make(map[string]*color.Color, len(p.palette))
+	for status, attr := range p.palette {
+		p.statusColors[status] = newColor(p.colorEnabled, attr)
 	}
 }
 
+// SetGroupBy enables per-group subtotals in the footer; an empty value disables them
+func (p *Printer) SetGroupBy(groupBy string) {
+	p.groupBy = groupBy
+}
+
+// SetThresholds sets the limits checked by PrintFooter; violations are printed in their own
+// section and returned by Violations
+func (p *Printer) SetThresholds(thresholds Thresholds) {
+	p.thresholds = thresholds
+}
+
+// SetColumns selects the table columns; names must have been checked with ValidateColumns.
+// JSON output always includes every field.
+func (p *Printer) SetColumns(names []string) {
+	p.columns = names
+}
+
+// tableColumns returns the selected table columns
+func (p *Printer) tableColumns() []string {
+	if len(p.columns) == 0 {
+		return DefaultColumns
+	}
+	return p.columns
+}
+
 // PrintHeader prepares the output for resource listing
 func (p *Printer) PrintHeader() {
-	if p.useJSON {
-		fmt.Fprint(p.output, "[\n")
-	} else {
-		headerFmt := color.New(color.FgGreen, color.Bold).SprintfFunc()
-		fmt.Fprintf(p.output, "%s\n", headerFmt(
-			"%-15s %-30s %-12s %-15s %-15s",
-			"Type", "Name", "Status", "Instance", "Running Time",
-		))
+	if p.tableOutput() {
+		headerFmt := newColor(p.colorEnabled, color.FgGreen, color.Bold).SprintfFunc()
+		var headers []string
+		for _, name := range p.tableColumns() {
+			c := columns[name]
+			headers = append(headers, fmt.Sprintf("%-*s", c.width, c.header))
+		}
+		fmt.Fprintf(p.output, "%s\n", headerFmt("%s", strings.Join(headers, " ")))
 		fmt.Fprintln(p.output, strings.Repeat("-", 120))
 	}
 }
 
 // PrintResource outputs a single resource
 func (p *Printer) PrintResource(info ResourceInfo) {
-	if p.useJSON {
-		p.printJSONResource(info)
-	} else {
+	p.resources = append(p.resources, info)
+	// JSON and HTML resources are written as part of the envelope or page in PrintFooter
+	if p.tableOutput() {
 		p.printTableResource(info)
 	}
 }
 
-// printJSONResource outputs a single resource in JSON format
-func (p *Printer) printJSONResource(info ResourceInfo) {
-	if !p.isFirstResource {
-		fmt.Fprint(p.output, ",\n")
+// printTableResource outputs a single resource in table format
+func (p *Printer) printTableResource(info ResourceInfo) {
+	var cells []string
+	for _, name := range p.tableColumns() {
+		c := columns[name]
+		value := c.value(info)
+		if c.truncate {
+			value = TruncateString(value, c.width-1)
+		}
+		// Pad before coloring so escape codes don't break the column alignment
+		cell := fmt.Sprintf("%-*s", c.width, value)
+		if statusColor, ok := p.statusColors[info.Status]; ok && name == "status" {
+			cell = statusColor.Sprint(cell)
+		}
+		cells = append(cells, cell)
+	}
+	fmt.Fprintln(p.output, strings.Join(cells, " "))
+}
+
+// Resources returns every resource printed so far
+func (p *Printer) Resources() []ResourceInfo {
+	return p.resources
+}
+
+// Errors returns every listing failure recorded so far
+func (p *Printer) Errors() []ErrorInfo {
+	return p.errors
+}
+
+// Violations returns the thresholds breached by the resources, once PrintFooter has run
+func (p *Printer) Violations() []Violation {
+	return p.violations
+}
+
+// PrintError records a listing failure; it is only written as part of the JSON output
+func (p *Printer) PrintError(info ErrorInfo) {
+	p.errors = append(p.errors, info)
+}
+
+// PrintFooter finalizes the output, including the group summary when grouping is enabled
+func (p *Printer) PrintFooter() {
+	p.violations = p.thresholds.Evaluate(p.resources)
+	if p.useJSON {
+		p.printJSONEnvelope()
+		return
 	}
-	
-	data, err := json.Marshal(info)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+	if p.useHTML {
+		p.printHTML("")
 		return
 	}
-	fmt.Fprint(p.output, "  ", string(data))
-	
-	p.isFirstResource = false
+
+	fmt.Fprintln(p.output, strings.Repeat("-", 120))
+	if p.groupBy != "" {
+		p.printTableSummary(Summarize(p.resources, p.groupBy))
+	}
+	p.printIncomplete()
+	p.printViolations()
 }
 
-// printTableResource outputs a single resource in table format
-func (p *Printer) printTableResource(info ResourceInfo) {
-	statusColor := map[string]func(a ...interface{}) string{
-		"InService":  color.New(color.FgGreen).SprintFunc(),
-		"Running":    color.New(color.FgGreen).SprintFunc(),
-		"Stopped":    color.New(color.FgYellow).SprintFunc(),
-		"Failed":     color.New(color.FgRed).SprintFunc(),
-		"Deleting":   color.New(color.FgRed).SprintFunc(),
+// printViolations outputs the breached thresholds, if any
+func (p *Printer) printViolations() {
+	if len(p.violations) == 0 {
+		return
+	}
+	alert := newColor(p.colorEnabled, color.FgRed, color.Bold).SprintfFunc()
+	fmt.Fprintf(p.output, "\n%s\n", alert("Threshold violations:"))
+	for _, v := range p.violations {
+		fmt.Fprintf(p.output, "%s\n", alert("  %s: %s", v.Threshold, v.Message))
+	}
+}
+
+// printIncomplete marks the resource types whose listing timed out or was interrupted, so partial tables aren't mistaken for complete ones
+func (p *Printer) printIncomplete() {
+	warn := newColor(p.colorEnabled, color.FgYellow).SprintfFunc()
+	for _, info := range p.errors {
+		if reason := info.incomplete(); reason != "" {
+			fmt.Fprintf(p.output, "%s\n", warn("%s: %s", info.ResourceType, reason))
+		}
 	}
+}
 
-	status := info.Status
-	if colorFunc, ok := statusColor[status]; ok {
-		status = colorFunc(status)
+// printJSONEnvelope outputs all collected resources and the optional summary as a single JSON document
+func (p *Printer) printJSONEnvelope() {
+	envelope := struct {
+		Resources  []ResourceInfo `json:"resources"`
+		Summary    *Summary       `json:"summary,omitempty"`
+		Errors     []ErrorInfo    `json:"errors,omitempty"`
+		Violations []Violation    `json:"violations,omitempty"`
+	}{
+		Resources:  p.resources,
+		Errors:     p.errors,
+		Violations: p.violations,
+	}
+	if envelope.Resources == nil {
+		envelope.Resources = []ResourceInfo{}
+	}
+	if p.groupBy != "" {
+		summary := Summarize(p.resources, p.groupBy)
+		envelope.Summary = &summary
 	}
 
-	fmt.Fprintf(p.output, "%-15s %-30s %-12s %-15s %-15s\n",
-		info.ResourceType,
-		truncateString(info.Name, 29),
-		status,
-		info.InstanceType,
-		info.RunningTime,
-	)
+	jsonData, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+		return
+	}
+	fmt.Fprintln(p.output, string(jsonData))
 }
 
-// PrintFooter finalizes the output
-func (p *Printer) PrintFooter() {
-	if p.useJSON {
-		fmt.Fprint(p.output, "\n]\n")
-	} else {
-		fmt.Fprintln(p.output, strings.Repeat("-", 120))
+// printHTML outputs the collected resources as a self-contained HTML page
+func (p *Printer) printHTML(region string) {
+	if err := WriteHTML(p.output, p.htmlPage(region)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering HTML: %v\n", err)
+	}
+}
+
+// printTableSummary outputs per-group subtotals followed by a grand total line
+func (p *Printer) printTableSummary(summary Summary) {
+	headerFmt := newColor(p.colorEnabled, color.FgGreen, color.Bold).SprintfFunc()
+	fmt.Fprintf(p.output, "%s\n", headerFmt(
+		"%-30s %8s %16s %14s %14s",
+		"Group ("+summary.GroupBy+")", "Count", "Instance Hours", "Hourly Cost", "Monthly Cost",
+	))
+	for _, group := range summary.Groups {
+		p.printSummaryRow(group)
 	}
+	fmt.Fprintln(p.output, strings.Repeat("-", 120))
+	p.printSummaryRow(summary.Total)
+}
+
+func (p *Printer) printSummaryRow(group GroupSummary) {
+	fmt.Fprintf(p.output, "%-30s %8d %16.1f %14s %14s\n",
+		TruncateString(group.Key, 29),
+		group.Count,
+		group.InstanceHours,
+		fmt.Sprintf("$%.2f", group.HourlyCost),
+		fmt.Sprintf("$%.2f", group.MonthlyCost),
+	)
 }
 
 // PrintNoResources handles the case when no resources are found
 func (p *Printer) PrintNoResources(region string) {
+	if p.useHTML {
+		p.printHTML(region)
+		return
+	}
 	if p.useJSON {
 		// Create a JSON object with metadata about no resources
 		noResourcesJSON := struct {
@@ -117,8 +360,10 @@ func (p *Printer) PrintNoResources(region string) {
 				Region  string `json:"region"`
 				Message string `json:"message"`
 			} `json:"metadata"`
+			Errors []ErrorInfo `json:"errors,omitempty"`
 		}{
 			Resources: []interface{}{},
+			Errors:    p.errors,
 			Metadata: struct {
 				Region  string `json:"region"`
 				Message string `json:"message"`
@@ -136,13 +381,14 @@ func (p *Printer) PrintNoResources(region string) {
 		fmt.Fprintln(p.output, string(jsonData))
 	} else {
 		// Use color for the no resources message in table format
-		noResourceMsg := color.New(color.FgYellow).SprintfFunc()
+		noResourceMsg := newColor(p.colorEnabled, color.FgYellow).SprintfFunc()
 		fmt.Fprintf(p.output, "%s\n", noResourceMsg("No SageMaker resources found in region %s", region))
+		p.printIncomplete()
 	}
 }
 
-// Helper function to truncate long strings
-func truncateString(s string, maxLen int) string {
+// TruncateString shortens s to at most maxLen characters, ending in "..." when cut
+func TruncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
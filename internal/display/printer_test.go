@@ -100,9 +100,22 @@ Endpoint        test-endpoint                  InService    ml.t3.medium    1h
 				InstanceType: "ml.t3.medium",
 				RunningTime:  "2h",
 			},
-			expected: `[
-  {"resourceType":"Notebook","name":"test-notebook","status":"InService","instanceType":"ml.t3.medium","runningTime":"2h"}
-]`,
+			expected: `{
+  "resources": [
+    {
+      "resourceType": "Notebook",
+      "name": "test-notebook",
+      "status": "InService",
+      "instanceType": "ml.t3.medium",
+      "runningTime": "2h",
+      "creationTime": "0001-01-01T00:00:00Z",
+      "runningSeconds": 0,
+      "instanceCount": 0,
+      "instanceHours": 0,
+      "estimatedHourlyCost": 0
+    }
+  ]
+}`,
 		},
 	}
 
@@ -112,7 +125,6 @@ Endpoint        test-endpoint                  InService    ml.t3.medium    1h
 			printer := &Printer{
 				useJSON: tt.useJSON,
 				output:  &buf,
-				isFirstResource: true,
 			}
 
 			printer.PrintHeader()
@@ -124,3 +136,109 @@ Endpoint        test-endpoint                  InService    ml.t3.medium    1h
 		})
 	}
 }
+
+func TestPrinterGroupSummary(t *testing.T) {
+	resources := []ResourceInfo{
+		{ResourceType: "Notebook", Name: "nb-1", Status: "InService", InstanceType: "ml.t3.medium", RunningTime: "1h", InstanceCount: 1, InstanceHours: 10, HourlyCost: 0.05},
+		{ResourceType: "Endpoint", Name: "ep-1", Status: "InService", InstanceType: "ml.g5.xlarge", RunningTime: "2h", InstanceCount: 2, InstanceHours: 4, HourlyCost: 2.816},
+	}
+
+	t.Run("Table format", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.SetGroupBy(GroupByType)
+
+		printer.PrintHeader()
+		for _, r := range resources {
+			printer.PrintResource(r)
+		}
+		printer.PrintFooter()
+
+		output := buf.String()
+		assert.Contains(t, output, "Group (type)")
+		assert.Contains(t, output, "Endpoint                              1              4.0          $2.82       $2055.68")
+		assert.Contains(t, output, "Total                                 2             14.0          $2.87       $2092.18")
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{useJSON: true, output: &buf}
+		printer.SetGroupBy(GroupByInstanceType)
+
+		printer.PrintHeader()
+		for _, r := range resources {
+			printer.PrintResource(r)
+		}
+		printer.PrintFooter()
+
+		var result struct {
+			Resources []ResourceInfo `json:"resources"`
+			Summary   Summary        `json:"summary"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Len(t, result.Resources, 2)
+		assert.Equal(t, GroupByInstanceType, result.Summary.GroupBy)
+		assert.Len(t, result.Summary.Groups, 2)
+		assert.Equal(t, "ml.g5.xlarge", result.Summary.Groups[0].Key)
+		assert.Equal(t, 2, result.Summary.Total.Count)
+	})
+}
+
+func TestPrinterJSONErrors(t *testing.T) {
+	var buf bytes.Buffer
+	printer := &Printer{useJSON: true, output: &buf}
+
+	printer.PrintResource(ResourceInfo{ResourceType: "Notebook", Name: "nb"})
+	printer.PrintError(ErrorInfo{
+		ResourceType:   "Endpoint",
+		Message:        "service unavailable",
+		Classification: "retries-exhausted",
+		Attempts:       4,
+		ElapsedSeconds: 7.5,
+	})
+	printer.PrintFooter()
+
+	var envelope struct {
+		Resources []ResourceInfo `json:"resources"`
+		Errors    []ErrorInfo    `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &envelope))
+	assert.Len(t, envelope.Resources, 1)
+	assert.Equal(t, []ErrorInfo{{
+		ResourceType:   "Endpoint",
+		Message:        "service unavailable",
+		Classification: "retries-exhausted",
+		Attempts:       4,
+		ElapsedSeconds: 7.5,
+	}}, envelope.Errors)
+
+	// Without errors the section is omitted entirely
+	buf.Reset()
+	printer = &Printer{useJSON: true, output: &buf}
+	printer.PrintFooter()
+	assert.NotContains(t, buf.String(), "errors")
+}
+
+func TestPrinterTableMarksTimedOut(t *testing.T) {
+	var buf bytes.Buffer
+	printer := &Printer{output: &buf}
+
+	printer.PrintError(ErrorInfo{ResourceType: "Endpoint", Message: "context deadline exceeded", TimedOut: true})
+	printer.PrintError(ErrorInfo{ResourceType: "Studio", Message: "access denied"})
+	printer.PrintFooter()
+
+	assert.Contains(t, buf.String(), "Endpoint: timed out, results are incomplete")
+	assert.NotContains(t, buf.String(), "Studio")
+
+	// Ctrl-C is not reported as a timeout
+	buf.Reset()
+	interrupted := &Printer{output: &buf}
+	interrupted.PrintError(ErrorInfo{ResourceType: "Notebook", Message: "context canceled", Interrupted: true})
+	interrupted.PrintFooter()
+	assert.Contains(t, buf.String(), "Notebook: interrupted, results are incomplete")
+	assert.NotContains(t, buf.String(), "timed out")
+
+	buf.Reset()
+	printer.PrintNoResources("us-east-1")
+	assert.Contains(t, buf.String(), "Endpoint: timed out, results are incomplete")
+}
@@ -0,0 +1,124 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mohua/internal/pricing"
+)
+
+// Supported group-by keys; tag grouping uses the "tag:<key>" form
+const (
+	GroupByType         = "type"
+	GroupByInstanceType = "instance-type"
+	GroupByUserProfile  = "user-profile"
+	GroupByRegion       = "region"
+	groupByTagPrefix    = "tag:"
+)
+
+// noGroupValue is used when a resource has no value for the grouping key
+const noGroupValue = "(none)"
+
+// GroupSummary holds the aggregated totals for a single group of resources
+type GroupSummary struct {
+	Key           string  `json:"key"`
+	Count         int     `json:"count"`
+	InstanceHours float64 `json:"instanceHours"`
+	HourlyCost    float64 `json:"estimatedHourlyCost"`
+	MonthlyCost   float64 `json:"estimatedMonthlyCost"`
+}
+
+// Summary holds per-group subtotals and the grand total for a resource listing
+type Summary struct {
+	GroupBy string         `json:"groupBy"`
+	Groups  []GroupSummary `json:"groups"`
+	Total   GroupSummary   `json:"total"`
+}
+
+// ValidateGroupBy checks that the given group-by key is supported
+func ValidateGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByType, GroupByInstanceType, GroupByUserProfile, GroupByRegion:
+		return nil
+	}
+	if TagKey(groupBy) != "" {
+		return nil
+	}
+	return fmt.Errorf("invalid group-by %q: must be one of type, instance-type, user-profile, region or tag:<key>", groupBy)
+}
+
+// TagKey returns the tag key for a "tag:<key>" group-by, or an empty string otherwise
+func TagKey(groupBy string) string {
+	if !strings.HasPrefix(groupBy, groupByTagPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(groupBy, groupByTagPrefix)
+}
+
+// Summarize aggregates resources into groups according to the given group-by key
+func Summarize(resources []ResourceInfo, groupBy string) Summary {
+	groups := make(map[string]*GroupSummary)
+	total := GroupSummary{Key: "Total"}
+
+	for _, info := range resources {
+		key := groupKey(info, groupBy)
+		group, ok := groups[key]
+		if !ok {
+			group = &GroupSummary{Key: key}
+			groups[key] = group
+		}
+		group.add(info)
+		total.add(info)
+	}
+
+	summary := Summary{
+		GroupBy: groupBy,
+		Groups:  make([]GroupSummary, 0, len(groups)),
+		Total:   total,
+	}
+	for _, group := range groups {
+		summary.Groups = append(summary.Groups, *group)
+	}
+
+	// Most expensive groups first, falling back to the key for a stable order
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		if summary.Groups[i].HourlyCost != summary.Groups[j].HourlyCost {
+			return summary.Groups[i].HourlyCost > summary.Groups[j].HourlyCost
+		}
+		return summary.Groups[i].Key < summary.Groups[j].Key
+	})
+
+	return summary
+}
+
+func (g *GroupSummary) add(info ResourceInfo) {
+	g.Count++
+	g.InstanceHours += info.InstanceHours
+	g.HourlyCost += info.HourlyCost
+	g.MonthlyCost += info.HourlyCost * pricing.HoursPerMonth
+}
+
+// groupKey returns the value of the grouping key for the given resource
+func groupKey(info ResourceInfo, groupBy string) string {
+	var key string
+	switch groupBy {
+	case GroupByType:
+		key = info.ResourceType
+	case GroupByInstanceType:
+		key = info.InstanceType
+	case GroupByUserProfile:
+		key = info.UserProfile
+	case GroupByRegion:
+		key = info.Region
+	default:
+		if tagKey := TagKey(groupBy); tagKey != "" {
+			key = info.Tags[tagKey]
+		}
+	}
+
+	if key == "" {
+		return noGroupValue
+	}
+	return key
+}
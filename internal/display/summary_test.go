@@ -0,0 +1,67 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGroupBy(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		wantErr bool
+	}{
+		{groupBy: "type"},
+		{groupBy: "instance-type"},
+		{groupBy: "user-profile"},
+		{groupBy: "region"},
+		{groupBy: "tag:team"},
+		{groupBy: "tag:", wantErr: true},
+		{groupBy: "owner", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			err := ValidateGroupBy(tt.groupBy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	resources := []ResourceInfo{
+		{ResourceType: "Notebook", UserProfile: "", InstanceType: "ml.t3.medium", InstanceHours: 10, HourlyCost: 0.05, Tags: map[string]string{"team": "a"}},
+		{ResourceType: "Studio", UserProfile: "alice", InstanceType: "ml.t3.medium", InstanceHours: 5, HourlyCost: 0.05, Tags: map[string]string{"team": "b"}},
+		{ResourceType: "Studio", UserProfile: "alice", InstanceType: "ml.g5.xlarge", InstanceHours: 2, HourlyCost: 1.408},
+	}
+
+	t.Run("by user profile", func(t *testing.T) {
+		summary := Summarize(resources, GroupByUserProfile)
+		assert.Len(t, summary.Groups, 2)
+		assert.Equal(t, "alice", summary.Groups[0].Key)
+		assert.Equal(t, 2, summary.Groups[0].Count)
+		assert.InDelta(t, 7, summary.Groups[0].InstanceHours, 1e-9)
+		assert.Equal(t, noGroupValue, summary.Groups[1].Key)
+	})
+
+	t.Run("by tag", func(t *testing.T) {
+		summary := Summarize(resources, "tag:team")
+		keys := []string{}
+		for _, g := range summary.Groups {
+			keys = append(keys, g.Key)
+		}
+		assert.Equal(t, []string{noGroupValue, "a", "b"}, keys)
+	})
+
+	t.Run("grand total", func(t *testing.T) {
+		summary := Summarize(resources, GroupByType)
+		assert.Equal(t, 3, summary.Total.Count)
+		assert.InDelta(t, 17, summary.Total.InstanceHours, 1e-9)
+		assert.InDelta(t, 1.508, summary.Total.HourlyCost, 1e-9)
+		assert.InDelta(t, 1.508*730, summary.Total.MonthlyCost, 1e-9)
+	})
+}
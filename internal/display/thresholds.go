@@ -0,0 +1,129 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mohua/internal/pricing"
+)
+
+// Threshold names, matching the flags that set them
+const (
+	ThresholdMaxHourlyCost = "max-hourly-cost"
+	ThresholdMaxAge        = "max-age"
+	ThresholdMaxCount      = "max-count"
+)
+
+// resourceTypes maps the lowercase names accepted by ParseMaxCount to resource types
+var resourceTypes = map[string]string{
+	"endpoint": "Endpoint",
+	"notebook": "Notebook",
+	"studio":   "Studio",
+}
+
+// Thresholds are the limits a run is checked against; zero values disable a limit
+type Thresholds struct {
+	// MaxHourlyCost limits the estimated hourly cost of all listed resources
+	MaxHourlyCost float64
+	// MaxAge limits how long any billed resource has been running
+	MaxAge time.Duration
+	// MaxCount limits the number of resources per resource type
+	MaxCount map[string]int
+}
+
+// Violation is a breached threshold. Limit and Value are in USD per hour for
+// max-hourly-cost, hours for max-age and resources for max-count.
+type Violation struct {
+	Threshold    string  `json:"threshold"`
+	ResourceType string  `json:"resourceType,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	Limit        float64 `json:"limit"`
+	Value        float64 `json:"value"`
+	Message      string  `json:"message"`
+}
+
+// ParseMaxCount parses "type=N" limits, e.g. notebook=5; types are endpoint, notebook or studio
+func ParseMaxCount(specs []string) (map[string]int, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	limits := make(map[string]int, len(specs))
+	for _, spec := range specs {
+		name, value, found := strings.Cut(strings.TrimSpace(spec), "=")
+		resourceType, ok := resourceTypes[strings.ToLower(name)]
+		if !found || !ok {
+			return nil, fmt.Errorf("invalid max-count %q: expected type=N with type endpoint, notebook or studio", spec)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid max-count %q: N must be a non-negative integer", spec)
+		}
+		limits[resourceType] = n
+	}
+	return limits, nil
+}
+
+// Evaluate checks resources against the thresholds and returns the violations, the total
+// cost first, then resources that ran too long in listing order, then counts by type
+func (t Thresholds) Evaluate(resources []ResourceInfo) []Violation {
+	var violations []Violation
+
+	var hourlyCost float64
+	counts := make(map[string]int)
+	for _, info := range resources {
+		hourlyCost += info.HourlyCost
+		counts[info.ResourceType]++
+	}
+	if t.MaxHourlyCost > 0 && hourlyCost > t.MaxHourlyCost {
+		violations = append(violations, Violation{
+			Threshold: ThresholdMaxHourlyCost,
+			Limit:     t.MaxHourlyCost,
+			Value:     hourlyCost,
+			Message:   fmt.Sprintf("estimated hourly cost $%.2f exceeds $%.2f", hourlyCost, t.MaxHourlyCost),
+		})
+	}
+
+	if t.MaxAge > 0 {
+		for _, info := range resources {
+			age := time.Duration(info.RunningSeconds) * time.Second
+			if !pricing.IsBilled(info.Status) || age <= t.MaxAge {
+				continue
+			}
+			violations = append(violations, Violation{
+				Threshold:    ThresholdMaxAge,
+				ResourceType: info.ResourceType,
+				Name:         info.Name,
+				Limit:        t.MaxAge.Hours(),
+				Value:        age.Hours(),
+				Message:      fmt.Sprintf("%s %s has been running for %.0fh, more than %s", info.ResourceType, info.Name, age.Hours(), formatHours(t.MaxAge)),
+			})
+		}
+	}
+
+	limited := make([]string, 0, len(t.MaxCount))
+	for resourceType := range t.MaxCount {
+		limited = append(limited, resourceType)
+	}
+	sort.Strings(limited)
+	for _, resourceType := range limited {
+		limit := t.MaxCount[resourceType]
+		if count := counts[resourceType]; count > limit {
+			violations = append(violations, Violation{
+				Threshold:    ThresholdMaxCount,
+				ResourceType: resourceType,
+				Limit:        float64(limit),
+				Value:        float64(count),
+				Message:      fmt.Sprintf("%d %s resources exceed the limit of %d", count, resourceType, limit),
+			})
+		}
+	}
+	return violations
+}
+
+// formatHours formats a duration as whole hours, e.g. 72h
+func formatHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64) + "h"
+}
@@ -0,0 +1,123 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMaxCount(t *testing.T) {
+	limits, err := ParseMaxCount([]string{"notebook=5", "Endpoint=0", " studio=2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Notebook": 5, "Endpoint": 0, "Studio": 2}, limits)
+
+	limits, err = ParseMaxCount(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, limits)
+
+	for _, spec := range []string{"notebook", "job=1", "notebook=-1", "notebook=many"} {
+		_, err := ParseMaxCount([]string{spec})
+		assert.ErrorContains(t, err, "invalid max-count", spec)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	day := int64((24 * time.Hour).Seconds())
+	resources := []ResourceInfo{
+		{ResourceType: "Notebook", Name: "old", Status: "InService", RunningSeconds: 4 * day, HourlyCost: 0.05},
+		{ResourceType: "Notebook", Name: "stopped", Status: "Stopped", RunningSeconds: 10 * day},
+		{ResourceType: "Notebook", Name: "new", Status: "InService", RunningSeconds: 3600, HourlyCost: 0.05},
+		{ResourceType: "Endpoint", Name: "gpu", Status: "InService", RunningSeconds: day, HourlyCost: 60},
+	}
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		want       []Violation
+	}{
+		{name: "disabled", thresholds: Thresholds{}},
+		{name: "within limits", thresholds: Thresholds{MaxHourlyCost: 100, MaxAge: 5 * 24 * time.Hour, MaxCount: map[string]int{"Notebook": 3}}},
+		{
+			name:       "hourly cost",
+			thresholds: Thresholds{MaxHourlyCost: 50},
+			want: []Violation{{
+				Threshold: ThresholdMaxHourlyCost, Limit: 50, Value: 60.1,
+				Message: "estimated hourly cost $60.10 exceeds $50.00",
+			}},
+		},
+		{
+			// Stopped resources are not running, however old they are
+			name:       "age",
+			thresholds: Thresholds{MaxAge: 72 * time.Hour},
+			want: []Violation{{
+				Threshold: ThresholdMaxAge, ResourceType: "Notebook", Name: "old", Limit: 72, Value: 96,
+				Message: "Notebook old has been running for 96h, more than 72h",
+			}},
+		},
+		{
+			name:       "count",
+			thresholds: Thresholds{MaxCount: map[string]int{"Notebook": 2, "Endpoint": 1, "Studio": 0}},
+			want: []Violation{{
+				Threshold: ThresholdMaxCount, ResourceType: "Notebook", Limit: 2, Value: 3,
+				Message: "3 Notebook resources exceed the limit of 2",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.thresholds.Evaluate(resources)
+			if assert.Len(t, got, len(tt.want)) {
+				for i := range got {
+					assert.InDelta(t, tt.want[i].Value, got[i].Value, 1e-9)
+					got[i].Value = tt.want[i].Value
+				}
+			}
+			if tt.want != nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrintViolations(t *testing.T) {
+	resources := []ResourceInfo{{ResourceType: "Endpoint", Name: "gpu", Status: "InService", HourlyCost: 60}}
+	thresholds := Thresholds{MaxHourlyCost: 50}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{output: &buf}
+		printer.SetThresholds(thresholds)
+		for _, info := range resources {
+			printer.PrintResource(info)
+		}
+		printer.PrintFooter()
+		assert.Len(t, printer.Violations(), 1)
+		assert.Contains(t, buf.String(), "Threshold violations:\n  max-hourly-cost: estimated hourly cost $60.00 exceeds $50.00\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{useJSON: true, output: &buf}
+		printer.SetThresholds(thresholds)
+		for _, info := range resources {
+			printer.PrintResource(info)
+		}
+		printer.PrintFooter()
+		var envelope struct {
+			Violations []Violation `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &envelope))
+		assert.Equal(t, printer.Violations(), envelope.Violations)
+	})
+
+	t.Run("no violations", func(t *testing.T) {
+		var buf bytes.Buffer
+		printer := &Printer{useJSON: true, output: &buf}
+		printer.PrintFooter()
+		assert.NotContains(t, buf.String(), "violations")
+	})
+}
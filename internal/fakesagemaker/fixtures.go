@@ -0,0 +1,69 @@
+package fakesagemaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Fixtures is the state served by a Server
+type Fixtures struct {
+	Domains   []Domain           `json:"domains"`
+	Endpoints []Endpoint         `json:"endpoints"`
+	Notebooks []NotebookInstance `json:"notebooks"`
+	Apps      []App              `json:"apps"`
+}
+
+// Domain is a SageMaker Studio domain
+type Domain struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// Endpoint is a SageMaker inference endpoint with a single variant, described with an
+// endpoint configuration named <name>-config and a model named <name>-model
+type Endpoint struct {
+	Name          string            `json:"name"`
+	Status        string            `json:"status"`
+	InstanceType  string            `json:"instanceType,omitempty"`
+	InstanceCount int               `json:"instanceCount,omitempty"`
+	CreationTime  time.Time         `json:"creationTime"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// NotebookInstance is a SageMaker notebook instance
+type NotebookInstance struct {
+	Name         string            `json:"name"`
+	Status       string            `json:"status"`
+	InstanceType string            `json:"instanceType"`
+	VolumeSize   int               `json:"volumeSize,omitempty"`
+	CreationTime time.Time         `json:"creationTime"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
+// App is a SageMaker Studio app
+type App struct {
+	Name         string    `json:"name"`
+	AppType      string    `json:"appType"`
+	DomainID     string    `json:"domainId"`
+	UserProfile  string    `json:"userProfile"`
+	SpaceName    string    `json:"spaceName,omitempty"`
+	Status       string    `json:"status"`
+	InstanceType string    `json:"instanceType,omitempty"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// LoadFixtures reads fixtures from a JSON file
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixtures, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fixtures, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,582 @@
+// Package fakesagemaker is an in-process stand-in for the SageMaker API. It speaks the
+// AWS JSON 1.1 protocol for the operations mohua uses, so the real SDK client can be
+// exercised in tests without network access or credentials.
+package fakesagemaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultPageSize is used when a request doesn't set MaxResults, like the real API
+const defaultPageSize = 10
+
+// accountID appears in every ARN the server generates
+const accountID = "123456789012"
+
+// Fault is a scripted error response
+type Fault struct {
+	Status  int    // HTTP status, e.g. 400 or 503
+	Code    string // AWS error code, e.g. ThrottlingException
+	Message string
+}
+
+// Throttling is the fault returned by the real API when the request rate is exceeded
+var Throttling = Fault{Status: http.StatusBadRequest, Code: "ThrottlingException", Message: "Rate exceeded"}
+
+// Server serves fixtures over the SageMaker JSON protocol
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	fixtures  Fixtures
+	pageSize  int
+	faults    map[string][]Fault
+	latency   map[string]time.Duration
+	calls     map[string]int
+	requestID int
+}
+
+// New starts a server serving the given fixtures; call Close when done
+func New(fixtures Fixtures) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		faults:   make(map[string][]Fault),
+		latency:  make(map[string]time.Duration),
+		calls:    make(map[string]int),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the endpoint URL to configure the SDK with
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetFixtures replaces the served state
+func (s *Server) SetFixtures(fixtures Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixtures = fixtures
+}
+
+// SetPageSize caps every page at n items regardless of MaxResults, to force pagination; 0 removes the cap
+func (s *Server) SetPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pageSize = n
+}
+
+// InjectFault makes the next times calls of operation, e.g. "ListEndpoints", fail with fault
+func (s *Server) InjectFault(operation string, fault Fault, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.faults[operation] = append(s.faults[operation], fault)
+	}
+}
+
+// Throttle makes the next times calls of operation fail with a ThrottlingException
+func (s *Server) Throttle(operation string, times int) {
+	s.InjectFault(operation, Throttling, times)
+}
+
+// SetLatency delays every response to operation by d; 0 removes the delay
+func (s *Server) SetLatency(operation string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[operation] = d
+}
+
+// Calls returns how many requests were received for operation, including failed ones
+func (s *Server) Calls(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[operation]
+}
+
+// regionPattern extracts the region from a SigV4 credential scope
+var regionPattern = regexp.MustCompile(`Credential=[^/]+/[^/]+/([^/]+)/`)
+
+// request is the union of the request parameters the server understands
+type request struct {
+	MaxResults            int
+	NextToken             string
+	StatusEquals          string
+	NameContains          string
+	CreationTimeBefore    *float64
+	CreationTimeAfter     *float64
+	UserProfileNameEquals string
+	DomainIdEquals        string
+	ResourceArn           string
+	EndpointName          string
+	NotebookInstanceName  string
+	DomainId              string
+	UserProfileName       string
+	SpaceName             string
+	AppType               string
+	AppName               string
+	EndpointConfigName    string
+	ModelName             string
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "SageMaker.")
+
+	s.mu.Lock()
+	s.calls[operation]++
+	s.requestID++
+	requestID := fmt.Sprintf("fake-%d", s.requestID)
+	latency := s.latency[operation]
+	var fault *Fault
+	if queued := s.faults[operation]; len(queued) > 0 {
+		fault = &queued[0]
+		s.faults[operation] = queued[1:]
+	}
+	fixtures := s.fixtures
+	pageSize := s.pageSize
+	s.mu.Unlock()
+
+	w.Header().Set("X-Amzn-Requestid", requestID)
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault != nil {
+		writeError(w, *fault)
+		return
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "SerializationException", Message: err.Error()})
+		return
+	}
+	if req.MaxResults <= 0 {
+		req.MaxResults = defaultPageSize
+	}
+	if pageSize > 0 && pageSize < req.MaxResults {
+		req.MaxResults = pageSize
+	}
+
+	region := "us-east-1"
+	if m := regionPattern.FindStringSubmatch(r.Header.Get("Authorization")); m != nil {
+		region = m[1]
+	}
+	arn := func(resourceType, name string) string {
+		return fmt.Sprintf("arn:aws:sagemaker:%s:%s:%s/%s", region, accountID, resourceType, strings.ToLower(name))
+	}
+
+	switch operation {
+	case "ListDomains":
+		var items []any
+		for _, d := range fixtures.Domains {
+			items = append(items, map[string]any{
+				"DomainId":   d.ID,
+				"DomainName": d.Name,
+				"Status":     d.Status,
+				"DomainArn":  arn("domain", d.ID),
+			})
+		}
+		writePage(w, "Domains", items, req)
+
+	case "ListEndpoints":
+		var items []any
+		for _, e := range fixtures.Endpoints {
+			if req.matches(e.Name, e.Status, e.CreationTime) {
+				items = append(items, map[string]any{
+					"EndpointName":     e.Name,
+					"EndpointArn":      arn("endpoint", e.Name),
+					"EndpointStatus":   e.Status,
+					"CreationTime":     epochSeconds(e.CreationTime),
+					"LastModifiedTime": epochSeconds(e.CreationTime),
+				})
+			}
+		}
+		writePage(w, "Endpoints", items, req)
+
+	case "ListNotebookInstances":
+		var items []any
+		for _, n := range fixtures.Notebooks {
+			if req.matches(n.Name, n.Status, n.CreationTime) {
+				items = append(items, map[string]any{
+					"NotebookInstanceName":   n.Name,
+					"NotebookInstanceArn":    arn("notebook-instance", n.Name),
+					"NotebookInstanceStatus": n.Status,
+					"InstanceType":           n.InstanceType,
+					"CreationTime":           epochSeconds(n.CreationTime),
+					"LastModifiedTime":       epochSeconds(n.CreationTime),
+				})
+			}
+		}
+		writePage(w, "NotebookInstances", items, req)
+
+	case "ListApps":
+		var items []any
+		for _, a := range fixtures.Apps {
+			if (req.UserProfileNameEquals != "" && a.UserProfile != req.UserProfileNameEquals) ||
+				(req.DomainIdEquals != "" && a.DomainID != req.DomainIdEquals) {
+				continue
+			}
+			item := map[string]any{
+				"AppName":         a.Name,
+				"AppType":         a.AppType,
+				"DomainId":        a.DomainID,
+				"UserProfileName": a.UserProfile,
+				"Status":          a.Status,
+				"CreationTime":    epochSeconds(a.CreationTime),
+			}
+			if a.SpaceName != "" {
+				item["SpaceName"] = a.SpaceName
+			}
+			if a.InstanceType != "" {
+				item["ResourceSpec"] = map[string]any{"InstanceType": a.InstanceType}
+			}
+			items = append(items, item)
+		}
+		writePage(w, "Apps", items, req)
+
+	case "ListTags":
+		var tags map[string]string
+		found := false
+		for _, e := range fixtures.Endpoints {
+			if arn("endpoint", e.Name) == req.ResourceArn {
+				tags, found = e.Tags, true
+			}
+		}
+		for _, n := range fixtures.Notebooks {
+			if arn("notebook-instance", n.Name) == req.ResourceArn {
+				tags, found = n.Tags, true
+			}
+		}
+		for _, a := range fixtures.Apps {
+			if appARN(arn, a) == req.ResourceArn {
+				found = true
+			}
+		}
+		if !found {
+			writeError(w, Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Resource " + req.ResourceArn + " not found"})
+			return
+		}
+		var items []any
+		for key, value := range tags {
+			items = append(items, map[string]any{"Key": key, "Value": value})
+		}
+		writePage(w, "Tags", items, req)
+
+	case "DescribeEndpoint", "DescribeEndpointConfig", "DescribeModel":
+		// Endpoints have a configuration and a model named after them
+		var endpoint *Endpoint
+		for i, e := range fixtures.Endpoints {
+			if (operation == "DescribeEndpoint" && e.Name == req.EndpointName) ||
+				(operation == "DescribeEndpointConfig" && e.Name+"-config" == req.EndpointConfigName) ||
+				(operation == "DescribeModel" && e.Name+"-model" == req.ModelName) {
+				endpoint = &fixtures.Endpoints[i]
+			}
+		}
+		if endpoint == nil {
+			writeError(w, Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Could not find " + req.EndpointName + req.EndpointConfigName + req.ModelName})
+			return
+		}
+		writeJSON(w, http.StatusOK, describeEndpoint(operation, *endpoint, arn))
+
+	case "DescribeNotebookInstance":
+		for _, n := range fixtures.Notebooks {
+			if n.Name == req.NotebookInstanceName {
+				item := map[string]any{
+					"NotebookInstanceName":   n.Name,
+					"NotebookInstanceArn":    arn("notebook-instance", n.Name),
+					"NotebookInstanceStatus": n.Status,
+					"InstanceType":           n.InstanceType,
+					"RoleArn":                roleARN,
+					"DirectInternetAccess":   "Enabled",
+					"RootAccess":             "Enabled",
+					"CreationTime":           epochSeconds(n.CreationTime),
+					"LastModifiedTime":       epochSeconds(n.CreationTime),
+				}
+				if n.VolumeSize > 0 {
+					item["VolumeSizeInGB"] = n.VolumeSize
+				}
+				writeJSON(w, http.StatusOK, item)
+				return
+			}
+		}
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "RecordNotFound"})
+
+	case "DescribeApp":
+		for _, a := range fixtures.Apps {
+			if a.matches(req) {
+				item := map[string]any{
+					"AppName":         a.Name,
+					"AppArn":          appARN(arn, a),
+					"AppType":         a.AppType,
+					"DomainId":        a.DomainID,
+					"UserProfileName": a.UserProfile,
+					"Status":          a.Status,
+					"CreationTime":    epochSeconds(a.CreationTime),
+				}
+				if a.SpaceName != "" {
+					item["SpaceName"] = a.SpaceName
+				}
+				if a.InstanceType != "" {
+					item["ResourceSpec"] = map[string]any{"InstanceType": a.InstanceType}
+				}
+				writeJSON(w, http.StatusOK, item)
+				return
+			}
+		}
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "ResourceNotFound", Message: "App " + req.AppName + " does not exist"})
+
+	case "DescribeDomain":
+		for _, d := range fixtures.Domains {
+			if d.ID == req.DomainId {
+				writeJSON(w, http.StatusOK, map[string]any{
+					"DomainId":             d.ID,
+					"DomainName":           d.Name,
+					"DomainArn":            arn("domain", d.ID),
+					"Status":               d.Status,
+					"AppNetworkAccessType": "PublicInternetOnly",
+					"DefaultUserSettings":  map[string]any{"ExecutionRole": roleARN},
+				})
+				return
+			}
+		}
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "ResourceNotFound", Message: "Domain " + req.DomainId + " does not exist"})
+
+	case "DescribeUserProfile":
+		for _, a := range fixtures.Apps {
+			if a.DomainID == req.DomainId && a.UserProfile == req.UserProfileName {
+				writeJSON(w, http.StatusOK, map[string]any{
+					"DomainId":        a.DomainID,
+					"UserProfileName": a.UserProfile,
+					"UserProfileArn":  arn("user-profile", a.DomainID+"/"+a.UserProfile),
+					"Status":          "InService",
+					"UserSettings":    map[string]any{"ExecutionRole": roleARN},
+				})
+				return
+			}
+		}
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "ResourceNotFound", Message: "User profile " + req.UserProfileName + " does not exist"})
+
+	case "DescribeSpace":
+		// Spaces are private to the user profile of their apps
+		for _, a := range fixtures.Apps {
+			if a.DomainID == req.DomainId && a.SpaceName != "" && a.SpaceName == req.SpaceName {
+				writeJSON(w, http.StatusOK, map[string]any{
+					"DomainId":             a.DomainID,
+					"SpaceName":            a.SpaceName,
+					"SpaceArn":             arn("space", a.DomainID+"/"+a.SpaceName),
+					"Status":               "InService",
+					"OwnershipSettings":    map[string]any{"OwnerUserProfileName": a.UserProfile},
+					"SpaceSharingSettings": map[string]any{"SharingType": "Private"},
+				})
+				return
+			}
+		}
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "ResourceNotFound", Message: "Space " + req.SpaceName + " does not exist"})
+
+	case "StopNotebookInstance", "DeleteNotebookInstance", "DeleteEndpoint", "DeleteApp":
+		if fault := s.mutate(operation, req); fault != nil {
+			writeError(w, *fault)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{})
+
+	default:
+		writeError(w, Fault{Status: http.StatusBadRequest, Code: "UnknownOperationException", Message: "Unsupported operation " + operation})
+	}
+}
+
+// mutate applies a stop or delete request to the fixtures. Changes take effect immediately,
+// skipping the Stopping and Deleting statuses the real API goes through.
+func (s *Server) mutate(operation string, req request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notFound := func(resource string) *Fault {
+		return &Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Could not find " + resource}
+	}
+
+	switch operation {
+	case "StopNotebookInstance", "DeleteNotebookInstance":
+		notebooks := s.fixtures.Notebooks
+		for i, n := range notebooks {
+			if n.Name != req.NotebookInstanceName {
+				continue
+			}
+			if operation == "StopNotebookInstance" {
+				if n.Status != "InService" {
+					return &Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Status (" + n.Status + ") not in ([InService]). Unable to transition to (Stopping) for Notebook Instance (" + n.Name + ")."}
+				}
+				notebooks = append([]NotebookInstance{}, notebooks...)
+				notebooks[i].Status = "Stopped"
+			} else {
+				if n.Status != "Stopped" && n.Status != "Failed" {
+					return &Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Status (" + n.Status + ") not in ([Stopped, Failed]). Unable to transition to (Deleting) for Notebook Instance (" + n.Name + ")."}
+				}
+				notebooks = append(append([]NotebookInstance{}, notebooks[:i]...), notebooks[i+1:]...)
+			}
+			s.fixtures.Notebooks = notebooks
+			return nil
+		}
+		return notFound("notebook instance " + req.NotebookInstanceName)
+
+	case "DeleteEndpoint":
+		for i, e := range s.fixtures.Endpoints {
+			if e.Name == req.EndpointName {
+				s.fixtures.Endpoints = append(append([]Endpoint{}, s.fixtures.Endpoints[:i]...), s.fixtures.Endpoints[i+1:]...)
+				return nil
+			}
+		}
+		return notFound("endpoint " + req.EndpointName)
+
+	default: // DeleteApp
+		for i, a := range s.fixtures.Apps {
+			if a.matches(req) && a.Status != "Deleted" {
+				// Deleted apps are still listed for a while, like in the real API
+				apps := append([]App{}, s.fixtures.Apps...)
+				apps[i].Status = "Deleted"
+				s.fixtures.Apps = apps
+				return nil
+			}
+		}
+		return &Fault{Status: http.StatusBadRequest, Code: "ResourceNotFound", Message: "App " + req.AppName + " does not exist"}
+	}
+}
+
+// roleARN is the execution role of every described resource
+const roleARN = "arn:aws:iam::" + accountID + ":role/mohua-fake-execution"
+
+// describeEndpoint answers the Describe operations of an endpoint, its configuration and its
+// model
+func describeEndpoint(operation string, e Endpoint, arn func(resourceType, name string) string) map[string]any {
+	count := e.InstanceCount
+	if count == 0 {
+		count = 1
+	}
+	switch operation {
+	case "DescribeEndpointConfig":
+		variant := map[string]any{"VariantName": "AllTraffic", "ModelName": e.Name + "-model", "InitialInstanceCount": count}
+		if e.InstanceType != "" {
+			variant["InstanceType"] = e.InstanceType
+		}
+		return map[string]any{
+			"EndpointConfigName": e.Name + "-config",
+			"EndpointConfigArn":  arn("endpoint-config", e.Name+"-config"),
+			"ProductionVariants": []any{variant},
+			"CreationTime":       epochSeconds(e.CreationTime),
+		}
+	case "DescribeModel":
+		return map[string]any{
+			"ModelName":        e.Name + "-model",
+			"ModelArn":         arn("model", e.Name+"-model"),
+			"ExecutionRoleArn": roleARN,
+			"CreationTime":     epochSeconds(e.CreationTime),
+		}
+	}
+	return map[string]any{
+		"EndpointName":       e.Name,
+		"EndpointArn":        arn("endpoint", e.Name),
+		"EndpointStatus":     e.Status,
+		"EndpointConfigName": e.Name + "-config",
+		"ProductionVariants": []any{map[string]any{
+			"VariantName":          "AllTraffic",
+			"CurrentInstanceCount": count,
+			"DesiredInstanceCount": count,
+			"CurrentWeight":        1,
+		}},
+		"CreationTime":     epochSeconds(e.CreationTime),
+		"LastModifiedTime": epochSeconds(e.CreationTime),
+	}
+}
+
+// matches reports whether a DescribeApp or DeleteApp request addresses the app; apps in a
+// space are addressed by the space, others by the user profile
+func (a App) matches(req request) bool {
+	owner := a.UserProfile == req.UserProfileName
+	if req.SpaceName != "" {
+		owner = a.SpaceName == req.SpaceName
+	}
+	return a.DomainID == req.DomainId && owner && a.AppType == req.AppType && a.Name == req.AppName
+}
+
+// appARN is the ARN of a Studio app, which ListApps doesn't return
+func appARN(arn func(resourceType, name string) string, a App) string {
+	owner := a.UserProfile
+	if a.SpaceName != "" {
+		owner = a.SpaceName
+	}
+	return arn("app", a.DomainID+"/"+owner+"/"+strings.ToLower(a.AppType)+"/"+a.Name)
+}
+
+// matches applies the List API server-side filters
+func (req request) matches(name, status string, created time.Time) bool {
+	if req.StatusEquals != "" && status != req.StatusEquals {
+		return false
+	}
+	if req.NameContains != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(req.NameContains)) {
+		return false
+	}
+	if req.CreationTimeBefore != nil && epochSeconds(created) >= *req.CreationTimeBefore {
+		return false
+	}
+	if req.CreationTimeAfter != nil && epochSeconds(created) <= *req.CreationTimeAfter {
+		return false
+	}
+	return true
+}
+
+// writePage writes one page of items under key, with a NextToken when more remain
+func writePage(w http.ResponseWriter, key string, items []any, req request) {
+	start := 0
+	if req.NextToken != "" {
+		offset, err := strconv.Atoi(req.NextToken)
+		if err != nil || offset < 0 || offset > len(items) {
+			writeError(w, Fault{Status: http.StatusBadRequest, Code: "ValidationException", Message: "Invalid NextToken"})
+			return
+		}
+		start = offset
+	}
+	end := start + req.MaxResults
+	if end > len(items) {
+		end = len(items)
+	}
+
+	body := map[string]any{key: append([]any{}, items[start:end]...)}
+	if end < len(items) {
+		body["NextToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeError(w http.ResponseWriter, fault Fault) {
+	w.Header().Set("X-Amzn-Errortype", fault.Code)
+	writeJSON(w, fault.Status, map[string]string{"__type": fault.Code, "message": fault.Message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// epochSeconds encodes a timestamp the way the JSON protocol does
+func epochSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
@@ -0,0 +1,298 @@
+package fakesagemaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSDKClient returns a SageMaker SDK client talking to the server, with SDK retries disabled
+func newSDKClient(s *Server) *sagemaker.Client {
+	return sagemaker.New(sagemaker.Options{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String(s.URL()),
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+func loadTestFixtures(t *testing.T) Fixtures {
+	fixtures, err := LoadFixtures("testdata/fixtures.json")
+	assert.NoError(t, err)
+	return fixtures
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures := loadTestFixtures(t)
+	assert.Len(t, fixtures.Domains, 1)
+	assert.Len(t, fixtures.Endpoints, 2)
+	assert.Len(t, fixtures.Notebooks, 2)
+	assert.Len(t, fixtures.Apps, 2)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), fixtures.Endpoints[0].CreationTime)
+
+	_, err := LoadFixtures("testdata/missing.json")
+	assert.Error(t, err)
+}
+
+func TestServer_ListOperations(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+	ctx := context.Background()
+
+	endpoints, err := client.ListEndpoints(ctx, &sagemaker.ListEndpointsInput{})
+	assert.NoError(t, err)
+	assert.Len(t, endpoints.Endpoints, 2)
+	assert.Equal(t, "prod-classifier", aws.ToString(endpoints.Endpoints[0].EndpointName))
+	assert.Equal(t, "arn:aws:sagemaker:eu-west-1:123456789012:endpoint/prod-classifier", aws.ToString(endpoints.Endpoints[0].EndpointArn))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), aws.ToTime(endpoints.Endpoints[0].CreationTime).UTC())
+
+	notebooks, err := client.ListNotebookInstances(ctx, &sagemaker.ListNotebookInstancesInput{
+		StatusEquals: types.NotebookInstanceStatusInService,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, notebooks.NotebookInstances, 1)
+	assert.Equal(t, types.InstanceTypeMlT3Medium, notebooks.NotebookInstances[0].InstanceType)
+
+	apps, err := client.ListApps(ctx, &sagemaker.ListAppsInput{UserProfileNameEquals: aws.String("bob")})
+	assert.NoError(t, err)
+	assert.Len(t, apps.Apps, 1)
+	assert.Equal(t, "bob-space", aws.ToString(apps.Apps[0].SpaceName))
+	assert.Equal(t, types.AppInstanceTypeMlG5Xlarge, apps.Apps[0].ResourceSpec.InstanceType)
+
+	domains, err := client.ListDomains(ctx, &sagemaker.ListDomainsInput{})
+	assert.NoError(t, err)
+	assert.Len(t, domains.Domains, 1)
+
+	tags, err := client.ListTags(ctx, &sagemaker.ListTagsInput{ResourceArn: endpoints.Endpoints[0].EndpointArn})
+	assert.NoError(t, err)
+	assert.Len(t, tags.Tags, 1)
+	assert.Equal(t, "team", aws.ToString(tags.Tags[0].Key))
+	assert.Equal(t, "ml", aws.ToString(tags.Tags[0].Value))
+}
+
+func TestServer_Filters(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		input    *sagemaker.ListEndpointsInput
+		expected []string
+	}{
+		{name: "status", input: &sagemaker.ListEndpointsInput{StatusEquals: types.EndpointStatusFailed}, expected: []string{"exp-ranker"}},
+		{name: "name contains is case-insensitive", input: &sagemaker.ListEndpointsInput{NameContains: aws.String("PROD")}, expected: []string{"prod-classifier"}},
+		{
+			name:     "created before",
+			input:    &sagemaker.ListEndpointsInput{CreationTimeBefore: aws.Time(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))},
+			expected: []string{"prod-classifier"},
+		},
+		{
+			name:     "created after",
+			input:    &sagemaker.ListEndpointsInput{CreationTimeAfter: aws.Time(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))},
+			expected: []string{"exp-ranker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := client.ListEndpoints(ctx, tt.input)
+			assert.NoError(t, err)
+			var names []string
+			for _, e := range output.Endpoints {
+				names = append(names, aws.ToString(e.EndpointName))
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	s.SetPageSize(1)
+	client := newSDKClient(s)
+
+	var names []string
+	paginator := sagemaker.NewListEndpointsPaginator(client, &sagemaker.ListEndpointsInput{MaxResults: aws.Int32(100)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, page.Endpoints, 1)
+		for _, e := range page.Endpoints {
+			names = append(names, aws.ToString(e.EndpointName))
+		}
+	}
+
+	assert.Equal(t, []string{"prod-classifier", "exp-ranker"}, names)
+	assert.Equal(t, 2, s.Calls("ListEndpoints"))
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+	ctx := context.Background()
+
+	s.Throttle("ListEndpoints", 1)
+	s.InjectFault("ListEndpoints", Fault{Status: 503, Code: "ServiceUnavailable", Message: "down"}, 1)
+
+	_, err := client.ListEndpoints(ctx, &sagemaker.ListEndpointsInput{})
+	var apiErr smithy.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ThrottlingException", apiErr.ErrorCode())
+	assert.True(t, retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err).Bool())
+
+	_, err = client.ListEndpoints(ctx, &sagemaker.ListEndpointsInput{})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ServiceUnavailable", apiErr.ErrorCode())
+
+	// Scripted faults are consumed in order, then requests succeed again
+	_, err = client.ListEndpoints(ctx, &sagemaker.ListEndpointsInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.Calls("ListEndpoints"))
+
+	// Other operations are unaffected
+	assert.Equal(t, 0, s.Calls("ListApps"))
+}
+
+func TestServer_Latency(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+
+	s.SetLatency("ListApps", 200*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.ListApps(ctx, &sagemaker.ListAppsInput{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	s.SetLatency("ListApps", 0)
+	_, err = client.ListApps(context.Background(), &sagemaker.ListAppsInput{})
+	assert.NoError(t, err)
+}
+
+func TestServer_UnknownResource(t *testing.T) {
+	s := New(Fixtures{})
+	defer s.Close()
+
+	_, err := newSDKClient(s).ListTags(context.Background(), &sagemaker.ListTagsInput{
+		ResourceArn: aws.String("arn:aws:sagemaker:eu-west-1:123456789012:endpoint/missing"),
+	})
+	var apiErr smithy.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ValidationException", apiErr.ErrorCode())
+}
+
+func TestServer_Actions(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+	ctx := context.Background()
+	var apiErr smithy.APIError
+
+	// A running notebook must be stopped before it can be deleted
+	_, err := client.DeleteNotebookInstance(ctx, &sagemaker.DeleteNotebookInstanceInput{NotebookInstanceName: aws.String("dev-notebook")})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ValidationException", apiErr.ErrorCode())
+	_, err = client.StopNotebookInstance(ctx, &sagemaker.StopNotebookInstanceInput{NotebookInstanceName: aws.String("dev-notebook")})
+	assert.NoError(t, err)
+	_, err = client.StopNotebookInstance(ctx, &sagemaker.StopNotebookInstanceInput{NotebookInstanceName: aws.String("dev-notebook")})
+	assert.True(t, errors.As(err, &apiErr))
+	_, err = client.DeleteNotebookInstance(ctx, &sagemaker.DeleteNotebookInstanceInput{NotebookInstanceName: aws.String("dev-notebook")})
+	assert.NoError(t, err)
+	notebooks, err := client.ListNotebookInstances(ctx, &sagemaker.ListNotebookInstancesInput{})
+	assert.NoError(t, err)
+	if assert.Len(t, notebooks.NotebookInstances, 1) {
+		assert.Equal(t, "old-notebook", aws.ToString(notebooks.NotebookInstances[0].NotebookInstanceName))
+	}
+
+	_, err = client.DeleteEndpoint(ctx, &sagemaker.DeleteEndpointInput{EndpointName: aws.String("prod-classifier")})
+	assert.NoError(t, err)
+	_, err = client.DeleteEndpoint(ctx, &sagemaker.DeleteEndpointInput{EndpointName: aws.String("prod-classifier")})
+	assert.True(t, errors.As(err, &apiErr))
+	endpoints, err := client.ListEndpoints(ctx, &sagemaker.ListEndpointsInput{})
+	assert.NoError(t, err)
+	assert.Len(t, endpoints.Endpoints, 1)
+
+	// Apps in a space are addressed by the space; deleted apps are still listed
+	_, err = client.DeleteApp(ctx, &sagemaker.DeleteAppInput{DomainId: aws.String("d-abc123"), SpaceName: aws.String("bob-space"), AppType: types.AppTypeJupyterLab, AppName: aws.String("lab")})
+	assert.NoError(t, err)
+	_, err = client.DeleteApp(ctx, &sagemaker.DeleteAppInput{DomainId: aws.String("d-abc123"), UserProfileName: aws.String("bob"), AppType: types.AppTypeJupyterServer, AppName: aws.String("default")})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ResourceNotFound", apiErr.ErrorCode())
+	apps, err := client.ListApps(ctx, &sagemaker.ListAppsInput{UserProfileNameEquals: aws.String("bob")})
+	assert.NoError(t, err)
+	if assert.Len(t, apps.Apps, 1) {
+		assert.Equal(t, types.AppStatusDeleted, apps.Apps[0].Status)
+	}
+}
+
+func TestServer_Describe(t *testing.T) {
+	s := New(loadTestFixtures(t))
+	defer s.Close()
+	client := newSDKClient(s)
+	ctx := context.Background()
+	var apiErr smithy.APIError
+
+	endpoint, err := client.DescribeEndpoint(ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("prod-classifier")})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-classifier-config", aws.ToString(endpoint.EndpointConfigName))
+	if assert.Len(t, endpoint.ProductionVariants, 1) {
+		assert.Equal(t, int32(2), aws.ToInt32(endpoint.ProductionVariants[0].CurrentInstanceCount))
+	}
+	config, err := client.DescribeEndpointConfig(ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String("prod-classifier-config")})
+	assert.NoError(t, err)
+	if assert.Len(t, config.ProductionVariants, 1) {
+		assert.Equal(t, types.ProductionVariantInstanceTypeMlM5Large, config.ProductionVariants[0].InstanceType)
+		assert.Equal(t, "prod-classifier-model", aws.ToString(config.ProductionVariants[0].ModelName))
+	}
+	model, err := client.DescribeModel(ctx, &sagemaker.DescribeModelInput{ModelName: aws.String("prod-classifier-model")})
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/mohua-fake-execution", aws.ToString(model.ExecutionRoleArn))
+	_, err = client.DescribeEndpoint(ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("missing")})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ValidationException", apiErr.ErrorCode())
+
+	notebook, err := client.DescribeNotebookInstance(ctx, &sagemaker.DescribeNotebookInstanceInput{NotebookInstanceName: aws.String("dev-notebook")})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), aws.ToInt32(notebook.VolumeSizeInGB))
+	assert.Equal(t, types.DirectInternetAccessEnabled, notebook.DirectInternetAccess)
+
+	app, err := client.DescribeApp(ctx, &sagemaker.DescribeAppInput{DomainId: aws.String("d-abc123"), SpaceName: aws.String("bob-space"), AppType: types.AppTypeJupyterLab, AppName: aws.String("lab")})
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:sagemaker:eu-west-1:123456789012:app/d-abc123/bob-space/jupyterlab/lab", aws.ToString(app.AppArn))
+	assert.Equal(t, types.AppInstanceTypeMlG5Xlarge, app.ResourceSpec.InstanceType)
+	// Apps have no tags in the fixtures, but their ARN is known
+	tags, err := client.ListTags(ctx, &sagemaker.ListTagsInput{ResourceArn: app.AppArn})
+	assert.NoError(t, err)
+	assert.Empty(t, tags.Tags)
+
+	domain, err := client.DescribeDomain(ctx, &sagemaker.DescribeDomainInput{DomainId: aws.String("d-abc123")})
+	assert.NoError(t, err)
+	assert.Equal(t, "research", aws.ToString(domain.DomainName))
+	_, err = client.DescribeDomain(ctx, &sagemaker.DescribeDomainInput{DomainId: aws.String("d-missing")})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ResourceNotFound", apiErr.ErrorCode())
+
+	space, err := client.DescribeSpace(ctx, &sagemaker.DescribeSpaceInput{DomainId: aws.String("d-abc123"), SpaceName: aws.String("bob-space")})
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", aws.ToString(space.OwnershipSettings.OwnerUserProfileName))
+	user, err := client.DescribeUserProfile(ctx, &sagemaker.DescribeUserProfileInput{DomainId: aws.String("d-abc123"), UserProfileName: aws.String("bob")})
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/mohua-fake-execution", aws.ToString(user.UserSettings.ExecutionRole))
+	_, err = client.DescribeUserProfile(ctx, &sagemaker.DescribeUserProfileInput{DomainId: aws.String("d-abc123"), UserProfileName: aws.String("nobody")})
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ResourceNotFound", apiErr.ErrorCode())
+}
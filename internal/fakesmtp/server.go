@@ -0,0 +1,320 @@
+// Package fakesmtp is an in-process stand-in for an SMTP server. It accepts mail on a local
+// port, optionally requiring STARTTLS and PLAIN authentication, and records every message so
+// that tests can send real email without a mail server.
+package fakesmtp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures the server
+type Options struct {
+	// StartTLS offers STARTTLS with a self-signed certificate for 127.0.0.1
+	StartTLS bool
+	// Username and Password, when set, are required with AUTH PLAIN before sending mail
+	Username string
+	Password string
+}
+
+// Message is a received email
+type Message struct {
+	From string
+	To   []string
+	Data []byte
+	// TLS tells whether the message was sent over a connection upgraded with STARTTLS
+	TLS bool
+}
+
+// Server accepts SMTP connections until Close is called
+type Server struct {
+	listener  net.Listener
+	options   Options
+	tlsConfig *tls.Config
+	certPool  *x509.CertPool
+	wg        sync.WaitGroup
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// New starts a server on a random local port
+func New(options Options) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, options: options}
+	if options.StartTLS {
+		if err := s.generateCertificate(); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Host returns the address the server listens on
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// ClientTLSConfig returns a TLS configuration trusting the server's certificate
+func (s *Server) ClientTLSConfig() *tls.Config {
+	return &tls.Config{RootCAs: s.certPool, ServerName: s.Host()}
+}
+
+// Messages returns the messages received so far
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops accepting connections and waits for the open ones to finish
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle runs one SMTP session with just enough of RFC 5321 for net/smtp
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	var (
+		msg           Message
+		encrypted     bool
+		authenticated = s.options.Username == ""
+	)
+	reply("220 fakesmtp ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-fakesmtp")
+			if s.tlsConfig != nil && !encrypted {
+				reply("250-STARTTLS")
+			}
+			if s.options.Username != "" {
+				reply("250-AUTH PLAIN")
+			}
+			reply("250 8BITMIME")
+		case "STARTTLS":
+			if s.tlsConfig == nil || encrypted {
+				reply("502 STARTTLS not available")
+				continue
+			}
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, encrypted = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			if !s.checkAuth(arg) {
+				reply("535 authentication failed")
+				continue
+			}
+			authenticated = true
+			reply("235 authenticated")
+		case "MAIL":
+			if !authenticated {
+				reply("530 authentication required")
+				continue
+			}
+			msg = Message{From: address(arg), TLS: encrypted}
+			reply("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, address(arg))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			msg.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "RSET":
+			msg = Message{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// checkAuth verifies an "AUTH PLAIN <base64>" argument
+func (s *Server) checkAuth(arg string) bool {
+	mechanism, encoded, _ := strings.Cut(arg, " ")
+	if !strings.EqualFold(mechanism, "PLAIN") {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(string(decoded), "\x00")
+	return len(parts) == 3 && parts[1] == s.options.Username && parts[2] == s.options.Password
+}
+
+// address extracts the address of a "FROM:<a@b>" or "TO:<a@b>" argument
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+	return strings.Trim(value, "<>")
+}
+
+// readData reads the message up to the terminating dot line, undoing dot-stuffing
+func readData(r *bufio.Reader) ([]byte, error) {
+	var data []byte
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" {
+			return data, nil
+		}
+		data = append(data, strings.TrimPrefix(line, ".")...)
+	}
+}
+
+// generateCertificate creates the self-signed certificate offered with STARTTLS
+func (s *Server) generateCertificate() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fakesmtp"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	s.certPool = x509.NewCertPool()
+	s.certPool.AddCert(cert)
+	s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	return nil
+}
+
+// Header returns a decoded header of the message
+func (m Message) Header(name string) string {
+	parsed, err := mail.ReadMessage(strings.NewReader(string(m.Data)))
+	if err != nil {
+		return ""
+	}
+	value := parsed.Header.Get(name)
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// Parts returns the decoded bodies of a multipart message by media type, e.g. "text/plain"
+func (m Message) Parts() (map[string]string, error) {
+	parsed, err := mail.ReadMessage(strings.NewReader(string(m.Data)))
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("not a multipart message: %s", mediaType)
+	}
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var body io.Reader = part
+		if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+			body = quotedprintable.NewReader(part)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = strings.ReplaceAll(string(data), "\r\n", "\n")
+	}
+}
@@ -0,0 +1,37 @@
+package fakesmtp
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	server, err := New(Options{Username: "user", Password: "pass"})
+	assert.NoError(t, err)
+	defer server.Close()
+	addr := net.JoinHostPort(server.Host(), strconv.Itoa(server.Port()))
+
+	body := "Subject: =?utf-8?q?caf=C3=A9?=\r\nContent-Type: text/plain\r\n\r\n.dot\r\nbody\r\n"
+	err = smtp.SendMail(addr, smtp.PlainAuth("", "user", "pass", server.Host()), "from@example.com", []string{"a@example.com", "b@example.com"}, []byte(body))
+	assert.NoError(t, err)
+
+	err = smtp.SendMail(addr, smtp.PlainAuth("", "user", "wrong", server.Host()), "from@example.com", []string{"a@example.com"}, []byte(body))
+	assert.ErrorContains(t, err, "535")
+
+	messages := server.Messages()
+	if assert.Len(t, messages, 1) {
+		msg := messages[0]
+		assert.Equal(t, "from@example.com", msg.From)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, msg.To)
+		assert.False(t, msg.TLS)
+		// Dot-stuffing is undone
+		assert.Equal(t, body, string(msg.Data))
+		assert.Equal(t, "café", msg.Header("Subject"))
+		_, err := msg.Parts()
+		assert.ErrorContains(t, err, "not a multipart message")
+	}
+}
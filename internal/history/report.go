@@ -0,0 +1,288 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/pricing"
+)
+
+// Supported report bucket sizes
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+// noOwner is used when a resource has neither a user profile nor an owner tag
+const noOwner = "(none)"
+
+// ValidateBucket checks that the given bucket size is supported
+func ValidateBucket(bucket string) error {
+	switch bucket {
+	case BucketHour, BucketDay, BucketWeek:
+		return nil
+	}
+	return fmt.Errorf("invalid bucket %q: must be one of hour, day or week", bucket)
+}
+
+// BucketStart returns the start of the bucket containing t, in t's location; weeks start on Monday
+func BucketStart(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case BucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextBucket returns the start of the bucket following the one starting at start
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketHour:
+		return start.Add(time.Hour)
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// ReportOptions control how snapshots are integrated into a report
+type ReportOptions struct {
+	From, To time.Time
+	Bucket   string
+	// MaxGap is how long a snapshot is assumed to stay accurate; longer intervals without a
+	// snapshot are reported as gaps and contribute nothing
+	MaxGap time.Duration
+	// OwnerTag is the tag naming the owner of endpoints and notebooks; Studio apps are owned by
+	// their user profile
+	OwnerTag string
+}
+
+// ReportRow is the usage of one resource type, instance type and owner in one bucket
+type ReportRow struct {
+	Bucket        time.Time `json:"bucket"`
+	ResourceType  string    `json:"resourceType"`
+	InstanceType  string    `json:"instanceType"`
+	Owner         string    `json:"owner"`
+	InstanceHours float64   `json:"instanceHours"`
+	Cost          float64   `json:"estimatedCost"`
+}
+
+// BucketTotal is the usage in one bucket and how much of it was covered by snapshots
+type BucketTotal struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	InstanceHours float64   `json:"instanceHours"`
+	Cost          float64   `json:"estimatedCost"`
+	// Coverage is the fraction of the bucket covered by snapshots, from 0 to 1
+	Coverage float64 `json:"coverage"`
+}
+
+// Gap is an interval of a region's history without any snapshot
+type Gap struct {
+	Region  string    `json:"region"`
+	Profile string    `json:"profile,omitempty"`
+	Account string    `json:"account,omitempty"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// Report is the usage integrated over time from snapshots
+type Report struct {
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	Bucket        string        `json:"bucket"`
+	Snapshots     int           `json:"snapshots"`
+	Rows          []ReportRow   `json:"rows"`
+	Buckets       []BucketTotal `json:"buckets"`
+	Gaps          []Gap         `json:"gaps"`
+	InstanceHours float64       `json:"instanceHours"`
+	Cost          float64       `json:"estimatedCost"`
+}
+
+// series identifies the snapshots of one region and profile, integrated independently. The
+// account is not part of the key: it comes from resource ARNs, so it is empty for snapshots
+// without any ARN-bearing resource.
+type series struct {
+	region, profile string
+}
+
+// rowKey identifies a report row within a bucket
+type rowKey struct {
+	resourceType, instanceType, owner string
+}
+
+// interval is a period during which a snapshot describes what was running
+type interval struct {
+	start, end time.Time
+	snapshot   *Snapshot
+}
+
+// BuildReport integrates snapshots into instance-hours and estimated cost per bucket. Each
+// snapshot is assumed to hold until the next one of the same region and profile, for at most
+// MaxGap. Snapshots should include the last one taken before From, which describes the start.
+func BuildReport(snapshots []Snapshot, opts ReportOptions) Report {
+	report := Report{
+		From:    opts.From,
+		To:      opts.To,
+		Bucket:  opts.Bucket,
+		Rows:    []ReportRow{},
+		Buckets: []BucketTotal{},
+		Gaps:    []Gap{},
+	}
+
+	bySeries := make(map[series][]*Snapshot)
+	accounts := make(map[series]string)
+	var order []series
+	for i := range snapshots {
+		s := &snapshots[i]
+		key := series{s.Region, s.Profile}
+		if _, ok := bySeries[key]; !ok {
+			order = append(order, key)
+		}
+		bySeries[key] = append(bySeries[key], s)
+		if accounts[key] == "" {
+			accounts[key] = s.Account
+		}
+		if !s.Timestamp.Before(opts.From) && s.Timestamp.Before(opts.To) {
+			report.Snapshots++
+		}
+	}
+	if len(order) == 0 {
+		// Without any snapshot the whole range is a gap
+		order = append(order, series{})
+	}
+
+	var intervals []interval
+	for _, key := range order {
+		covered := seriesIntervals(bySeries[key], opts)
+		intervals = append(intervals, covered...)
+		report.Gaps = append(report.Gaps, gaps(key, accounts[key], covered, opts.From, opts.To)...)
+	}
+	sort.SliceStable(report.Gaps, func(i, j int) bool {
+		return report.Gaps[i].Start.Before(report.Gaps[j].Start)
+	})
+
+	for start := opts.From; start.Before(opts.To); {
+		end := nextBucket(start, opts.Bucket)
+		if end.After(opts.To) {
+			end = opts.To
+		}
+		total := BucketTotal{Start: start, End: end}
+		rows := make(map[rowKey]*ReportRow)
+		var covered time.Duration
+		for _, iv := range intervals {
+			from, to := maxTime(iv.start, start), minTime(iv.end, end)
+			if !from.Before(to) {
+				continue
+			}
+			covered += to.Sub(from)
+			hours := to.Sub(from).Hours()
+			for _, r := range iv.snapshot.Resources {
+				if !pricing.IsBilled(r.Status) {
+					continue
+				}
+				key := rowKey{r.ResourceType, r.InstanceType, owner(r, opts.OwnerTag)}
+				row, ok := rows[key]
+				if !ok {
+					row = &ReportRow{Bucket: start, ResourceType: key.resourceType, InstanceType: key.instanceType, Owner: key.owner}
+					rows[key] = row
+				}
+				row.InstanceHours += hours * float64(max(r.InstanceCount, 1))
+				row.Cost += hours * r.HourlyCost
+				total.InstanceHours += hours * float64(max(r.InstanceCount, 1))
+				total.Cost += hours * r.HourlyCost
+			}
+		}
+		total.Coverage = float64(covered) / float64(end.Sub(start)*time.Duration(len(order)))
+		for _, row := range rows {
+			report.Rows = append(report.Rows, *row)
+		}
+		report.Buckets = append(report.Buckets, total)
+		report.InstanceHours += total.InstanceHours
+		report.Cost += total.Cost
+		start = end
+	}
+
+	// Chronological, then most expensive first, falling back to the keys for a stable order
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if !a.Bucket.Equal(b.Bucket) {
+			return a.Bucket.Before(b.Bucket)
+		}
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.InstanceType != b.InstanceType {
+			return a.InstanceType < b.InstanceType
+		}
+		return a.Owner < b.Owner
+	})
+	return report
+}
+
+// seriesIntervals returns the periods described by the snapshots of one series, clipped to
+// the report range; snapshots must be in chronological order
+func seriesIntervals(snapshots []*Snapshot, opts ReportOptions) []interval {
+	var intervals []interval
+	for i, s := range snapshots {
+		end := s.Timestamp.Add(opts.MaxGap)
+		if i+1 < len(snapshots) && snapshots[i+1].Timestamp.Before(end) {
+			end = snapshots[i+1].Timestamp
+		}
+		start, end := maxTime(s.Timestamp, opts.From), minTime(end, opts.To)
+		if start.Before(end) {
+			intervals = append(intervals, interval{start: start, end: end, snapshot: s})
+		}
+	}
+	return intervals
+}
+
+// gaps returns the parts of [from, to) not covered by the chronological intervals
+func gaps(key series, account string, intervals []interval, from, to time.Time) []Gap {
+	var result []Gap
+	cursor := from
+	for _, iv := range intervals {
+		if cursor.Before(iv.start) {
+			result = append(result, Gap{Region: key.region, Profile: key.profile, Account: account, Start: cursor, End: iv.start})
+		}
+		cursor = maxTime(cursor, iv.end)
+	}
+	if cursor.Before(to) {
+		result = append(result, Gap{Region: key.region, Profile: key.profile, Account: account, Start: cursor, End: to})
+	}
+	return result
+}
+
+// owner returns the Studio user profile or the owner tag of a resource
+func owner(r display.ResourceInfo, ownerTag string) string {
+	if r.UserProfile != "" {
+		return r.UserProfile
+	}
+	if value := r.Tags[ownerTag]; value != "" {
+		return value
+	}
+	return noOwner
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBucket(t *testing.T) {
+	for _, bucket := range []string{"hour", "day", "week"} {
+		assert.NoError(t, ValidateBucket(bucket))
+	}
+	assert.ErrorContains(t, ValidateBucket("month"), `invalid bucket "month"`)
+}
+
+func TestBucketStart(t *testing.T) {
+	// A Wednesday
+	ts := time.Date(2024, 5, 1, 15, 42, 10, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC), BucketStart(ts, BucketHour))
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), BucketStart(ts, BucketDay))
+	assert.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC), BucketStart(ts, BucketWeek))
+	// Sundays belong to the week starting the previous Monday
+	assert.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC), BucketStart(time.Date(2024, 5, 5, 23, 0, 0, 0, time.UTC), BucketWeek))
+}
+
+func TestBuildReport(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	endpoint := display.ResourceInfo{ResourceType: "Endpoint", Name: "prod", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 2, HourlyCost: 0.23, Tags: map[string]string{"owner": "ml-team"}}
+	app := display.ResourceInfo{ResourceType: "Studio", Name: "alice/JupyterLab", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 1, UserProfile: "alice", HourlyCost: 1.408}
+	stopped := display.ResourceInfo{ResourceType: "Notebook", Name: "old", Status: "Stopped", InstanceType: "ml.t3.medium", InstanceCount: 1}
+
+	snapshots := []Snapshot{
+		// Taken before the report starts, describes the first hour
+		NewSnapshot(day.Add(-30*time.Minute), "us-east-1", "", []display.ResourceInfo{endpoint, stopped}, nil),
+		NewSnapshot(day.Add(time.Hour), "us-east-1", "", []display.ResourceInfo{endpoint, app}, nil),
+		NewSnapshot(day.Add(2*time.Hour), "us-east-1", "", []display.ResourceInfo{endpoint}, nil),
+		// Nothing was taken between 4:00 and 20:00
+		NewSnapshot(day.Add(20*time.Hour), "us-east-1", "", []display.ResourceInfo{endpoint}, nil),
+		// Another region is integrated on its own
+		NewSnapshot(day.Add(22*time.Hour), "eu-west-1", "", []display.ResourceInfo{app}, nil),
+	}
+
+	report := BuildReport(snapshots, ReportOptions{
+		From:     day,
+		To:       day.Add(48 * time.Hour),
+		Bucket:   BucketDay,
+		MaxGap:   2 * time.Hour,
+		OwnerTag: "owner",
+	})
+
+	assert.Equal(t, 4, report.Snapshots)
+	if assert.Len(t, report.Buckets, 2) {
+		first := report.Buckets[0]
+		// The endpoint ran 0:00-4:00 and 20:00-22:00, the app 1:00-2:00 and 22:00-24:00
+		assert.InDelta(t, 6*2+3, first.InstanceHours, 1e-9)
+		assert.InDelta(t, 6*0.23+3*1.408, first.Cost, 1e-9)
+		// us-east-1 covered 6 of 24 hours, eu-west-1 2 of 24
+		assert.InDelta(t, 8.0/48, first.Coverage, 1e-9)
+		assert.Equal(t, 0.0, report.Buckets[1].Coverage)
+	}
+	assert.InDelta(t, report.Buckets[0].Cost, report.Cost, 1e-9)
+
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, ReportRow{Bucket: day, ResourceType: "Studio", InstanceType: "ml.g5.xlarge", Owner: "alice", InstanceHours: 3, Cost: 3 * 1.408}, roundRow(report.Rows[0]))
+		assert.Equal(t, "Endpoint", report.Rows[1].ResourceType)
+		assert.Equal(t, "ml-team", report.Rows[1].Owner)
+	}
+
+	assert.Equal(t, []Gap{
+		{Region: "eu-west-1", Start: day, End: day.Add(22 * time.Hour)},
+		{Region: "us-east-1", Start: day.Add(4 * time.Hour), End: day.Add(20 * time.Hour)},
+		{Region: "us-east-1", Start: day.Add(22 * time.Hour), End: day.Add(48 * time.Hour)},
+		{Region: "eu-west-1", Start: day.Add(24 * time.Hour), End: day.Add(48 * time.Hour)},
+	}, report.Gaps)
+}
+
+func TestBuildReport_NoSnapshots(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	report := BuildReport(nil, ReportOptions{From: day, To: day.Add(2 * time.Hour), Bucket: BucketHour, MaxGap: time.Hour})
+	assert.Len(t, report.Buckets, 2)
+	assert.Empty(t, report.Rows)
+	assert.Equal(t, []Gap{{Start: day, End: day.Add(2 * time.Hour)}}, report.Gaps)
+}
+
+func TestBuildReport_SnapshotWithoutAccount(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	endpoint := display.ResourceInfo{ResourceType: "Endpoint", Name: "prod", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1, HourlyCost: 1,
+		Arn: "arn:aws:sagemaker:us-east-1:123456789012:endpoint/prod"}
+
+	snapshots := []Snapshot{
+		NewSnapshot(day, "us-east-1", "dev", []display.ResourceInfo{endpoint}, nil),
+		// Nothing is running, so the account cannot be told from any ARN
+		NewSnapshot(day.Add(time.Hour), "us-east-1", "dev", nil, nil),
+	}
+	assert.Equal(t, "123456789012", snapshots[0].Account)
+	assert.Empty(t, snapshots[1].Account)
+
+	report := BuildReport(snapshots, ReportOptions{From: day, To: day.Add(2 * time.Hour), Bucket: BucketHour, MaxGap: 2 * time.Hour})
+
+	// Both snapshots belong to one series: the endpoint ran until the empty snapshot
+	assert.InDelta(t, 1.0, report.Cost, 1e-9)
+	assert.Empty(t, report.Gaps)
+	for _, bucket := range report.Buckets {
+		assert.Equal(t, 1.0, bucket.Coverage)
+	}
+}
+
+// roundRow rounds away floating point noise so rows can be compared
+func roundRow(row ReportRow) ReportRow {
+	row.InstanceHours = float64(int(row.InstanceHours*1e6+0.5)) / 1e6
+	row.Cost = float64(int(row.Cost*1e6+0.5)) / 1e6
+	return row
+}
@@ -0,0 +1,278 @@
+// Package history keeps snapshots of the listed resources in a local bbolt database, so past
+// runs can be browsed and compared.
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"mohua/internal/display"
+	"mohua/internal/pricing"
+)
+
+// snapshotsBucket holds one snapshot per key, keyed by the big-endian UnixNano timestamp so
+// keys sort chronologically, followed by the region and profile so runs of several regions
+// or profiles saved in the same second are all kept
+var snapshotsBucket = []byte("snapshots")
+
+// ErrNotFound is returned when no snapshot matches a lookup
+var ErrNotFound = errors.New("no snapshot found")
+
+// Snapshot is the state of one run
+type Snapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Region    string    `json:"region"`
+	// Account is derived from the resource ARNs; it is empty when no listed resource has one
+	Account     string                 `json:"account,omitempty"`
+	Profile     string                 `json:"profile,omitempty"`
+	Resources   []display.ResourceInfo `json:"resources"`
+	Errors      []display.ErrorInfo    `json:"errors,omitempty"`
+	HourlyCost  float64                `json:"estimatedHourlyCost"`
+	MonthlyCost float64                `json:"estimatedMonthlyCost"`
+}
+
+// Scope selects the snapshots of a region and profile; an empty field matches any
+type Scope struct {
+	Region  string
+	Profile string
+}
+
+// matches reports whether the snapshot was taken in the scope
+func (sc Scope) matches(s Snapshot) bool {
+	return (sc.Region == "" || s.Region == sc.Region) && (sc.Profile == "" || s.Profile == sc.Profile)
+}
+
+// describe names the scope for error messages, e.g. " in region us-east-1"
+func (sc Scope) describe() string {
+	var parts []string
+	if sc.Region != "" {
+		parts = append(parts, "region "+sc.Region)
+	}
+	if sc.Profile != "" {
+		parts = append(parts, "profile "+sc.Profile)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " in " + strings.Join(parts, ", ")
+}
+
+// Complete reports whether every resource type was listed successfully
+func (s Snapshot) Complete() bool {
+	return len(s.Errors) == 0
+}
+
+// Summary describes a snapshot without its resources
+type Summary struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Region      string    `json:"region"`
+	Account     string    `json:"account,omitempty"`
+	Profile     string    `json:"profile,omitempty"`
+	Resources   int       `json:"resources"`
+	HourlyCost  float64   `json:"estimatedHourlyCost"`
+	MonthlyCost float64   `json:"estimatedMonthlyCost"`
+	Complete    bool      `json:"complete"`
+}
+
+// NewSnapshot builds a snapshot of resources taken at now, totalling their estimated cost.
+// The timestamp is truncated to the second so listed timestamps can be passed back to At.
+func NewSnapshot(now time.Time, region, profile string, resources []display.ResourceInfo, errs []display.ErrorInfo) Snapshot {
+	s := Snapshot{
+		Timestamp: now.UTC().Truncate(time.Second),
+		Region:    region,
+		Profile:   profile,
+		Resources: resources,
+		Errors:    errs,
+	}
+	if s.Resources == nil {
+		s.Resources = []display.ResourceInfo{}
+	}
+	for _, r := range resources {
+		s.HourlyCost += r.HourlyCost
+		if s.Account == "" {
+			s.Account = accountFromARN(r.Arn)
+		}
+	}
+	s.MonthlyCost = s.HourlyCost * pricing.HoursPerMonth
+	return s
+}
+
+// accountFromARN returns the account ID field of an ARN, or an empty string
+func accountFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[4]
+}
+
+// DefaultPath returns $XDG_DATA_HOME/mohua/history.db, or ~/.local/share/mohua/history.db
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "mohua", "history.db")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "mohua", "history.db")
+}
+
+// Store is a snapshot database; it is safe for use by one process at a time
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the database at path, waiting briefly if another run holds it
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close releases the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append saves a snapshot, replacing one taken at the exact same time in the same region
+// and profile
+func (s *Store) Append(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put(snapshotKey(snapshot), data)
+	})
+}
+
+// List returns a summary of every snapshot, oldest first
+func (s *Store) List() ([]Summary, error) {
+	var summaries []Summary
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEach(func(_, data []byte) error {
+			snapshot, err := decode(data)
+			if err != nil {
+				return err
+			}
+			summaries = append(summaries, Summary{
+				Timestamp:   snapshot.Timestamp,
+				Region:      snapshot.Region,
+				Account:     snapshot.Account,
+				Profile:     snapshot.Profile,
+				Resources:   len(snapshot.Resources),
+				HourlyCost:  snapshot.HourlyCost,
+				MonthlyCost: snapshot.MonthlyCost,
+				Complete:    snapshot.Complete(),
+			})
+			return nil
+		})
+	})
+	return summaries, err
+}
+
+// At returns the latest snapshot of the scope taken at or before t, i.e. what was running
+// there at that time
+func (s *Store) At(t time.Time, scope Scope) (Snapshot, error) {
+	var snapshot Snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+		// Every key of a snapshot taken at or before t sorts before the first nanosecond after it
+		key, data := c.Seek(timestampKey(t.Add(time.Nanosecond)))
+		if key == nil {
+			key, data = c.Last()
+		} else {
+			key, data = c.Prev()
+		}
+		for ; key != nil; key, data = c.Prev() {
+			candidate, err := decode(data)
+			if err != nil {
+				return err
+			}
+			if scope.matches(candidate) {
+				snapshot = candidate
+				return nil
+			}
+		}
+		return fmt.Errorf("%w at or before %s%s", ErrNotFound, t.UTC().Format(time.RFC3339), scope.describe())
+	})
+	return snapshot, err
+}
+
+// Range returns every snapshot taken in [from, to), oldest first
+func (s *Store) Range(from, to time.Time) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+		end := timestampKey(to)
+		for key, data := c.Seek(timestampKey(from)); key != nil && string(key) < string(end); key, data = c.Next() {
+			snapshot, err := decode(data)
+			if err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+		return nil
+	})
+	return snapshots, err
+}
+
+// Prune deletes every snapshot taken before cutoff and returns how many were deleted
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	deleted := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+		end := timestampKey(cutoff)
+		for key, _ := c.First(); key != nil && string(key) < string(end); key, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// snapshotKey is the timestamp key followed by the region and profile
+func snapshotKey(snapshot Snapshot) []byte {
+	key := timestampKey(snapshot.Timestamp)
+	key = append(key, snapshot.Region...)
+	key = append(key, 0)
+	return append(key, snapshot.Profile...)
+}
+
+func timestampKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+func decode(data []byte) (Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return snapshot, nil
+}
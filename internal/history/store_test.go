@@ -0,0 +1,161 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestStore(t *testing.T) *Store {
+	store, err := Open(filepath.Join(t.TempDir(), "nested", "history.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestNewSnapshot(t *testing.T) {
+	now := time.Date(2024, 5, 1, 15, 0, 0, 123, time.UTC)
+	resources := []display.ResourceInfo{
+		{ResourceType: "Studio", Name: "alice/JupyterServer", HourlyCost: 0.05},
+		{ResourceType: "Endpoint", Name: "prod", Arn: "arn:aws:sagemaker:us-east-1:123456789012:endpoint/prod", HourlyCost: 1},
+	}
+
+	s := NewSnapshot(now, "us-east-1", "prod", resources, nil)
+	assert.Equal(t, now.Truncate(time.Second), s.Timestamp)
+	assert.Equal(t, "123456789012", s.Account)
+	assert.InDelta(t, 1.05, s.HourlyCost, 1e-9)
+	assert.InDelta(t, 1.05*730, s.MonthlyCost, 1e-9)
+	assert.True(t, s.Complete())
+
+	s = NewSnapshot(now, "us-east-1", "", nil, []display.ErrorInfo{{ResourceType: "Notebook"}})
+	assert.Empty(t, s.Account)
+	assert.NotNil(t, s.Resources)
+	assert.False(t, s.Complete())
+}
+
+func TestStore(t *testing.T) {
+	store := openTestStore(t)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"a", "b", "c"} {
+		resources := []display.ResourceInfo{{Name: name, HourlyCost: float64(i + 1)}}
+		assert.NoError(t, store.Append(NewSnapshot(base.Add(time.Duration(i)*time.Hour), "us-east-1", "", resources, nil)))
+	}
+
+	summaries, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 3)
+	assert.Equal(t, base, summaries[0].Timestamp)
+	assert.Equal(t, 1, summaries[0].Resources)
+	assert.Equal(t, 3.0, summaries[2].HourlyCost)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		want    string
+		wantErr bool
+	}{
+		{name: "exact", at: base.Add(time.Hour), want: "b"},
+		{name: "between", at: base.Add(90 * time.Minute), want: "b"},
+		{name: "after the last", at: base.Add(24 * time.Hour), want: "c"},
+		{name: "before the first", at: base.Add(-time.Second), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := store.At(tt.at, Scope{})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, snapshot.Resources[0].Name)
+		})
+	}
+
+	snapshots, err := store.Range(base.Add(time.Hour), base.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+	assert.Equal(t, "b", snapshots[0].Resources[0].Name)
+
+	deleted, err := store.Prune(base.Add(90 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	summaries, err = store.List()
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, base.Add(2*time.Hour), summaries[0].Timestamp)
+}
+
+func TestStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(path)
+	assert.NoError(t, err)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.Append(NewSnapshot(now, "eu-west-1", "", nil, nil)))
+	assert.NoError(t, store.Close())
+
+	store, err = Open(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	snapshot, err := store.At(now, Scope{})
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", snapshot.Region)
+}
+
+func TestStoreScopes(t *testing.T) {
+	store := openTestStore(t)
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := func(at time.Time, region, profile string) Snapshot {
+		return NewSnapshot(at, region, profile, []display.ResourceInfo{{Name: region + "/" + profile}}, nil)
+	}
+	// Cron jobs for several regions and profiles save in the same second
+	assert.NoError(t, store.Append(snapshot(base, "us-east-1", "prod")))
+	assert.NoError(t, store.Append(snapshot(base, "eu-west-1", "prod")))
+	assert.NoError(t, store.Append(snapshot(base, "us-east-1", "dev")))
+	assert.NoError(t, store.Append(snapshot(base.Add(time.Hour), "eu-west-1", "prod")))
+	// Saving the same region and profile at the same time again replaces the snapshot
+	assert.NoError(t, store.Append(snapshot(base.Add(time.Hour), "eu-west-1", "prod")))
+
+	summaries, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 4)
+	snapshots, err := store.Range(base, base.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 3)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		scope   Scope
+		want    string
+		wantErr string
+	}{
+		{name: "region and profile", at: base.Add(2 * time.Hour), scope: Scope{Region: "us-east-1", Profile: "prod"}, want: "us-east-1/prod"},
+		{name: "other profile", at: base.Add(2 * time.Hour), scope: Scope{Region: "us-east-1", Profile: "dev"}, want: "us-east-1/dev"},
+		{name: "latest of the region", at: base.Add(2 * time.Hour), scope: Scope{Region: "eu-west-1"}, want: "eu-west-1/prod"},
+		{name: "earlier snapshot of the region", at: base.Add(time.Minute), scope: Scope{Region: "eu-west-1"}, want: "eu-west-1/prod"},
+		{name: "any scope", at: base.Add(2 * time.Hour), want: "eu-west-1/prod"},
+		{name: "unknown region", at: base.Add(2 * time.Hour), scope: Scope{Region: "ap-south-1"}, wantErr: "no snapshot found at or before 2024-05-01T14:00:00Z in region ap-south-1"},
+		{name: "unknown profile", at: base, scope: Scope{Region: "eu-west-1", Profile: "dev"}, wantErr: "in region eu-west-1, profile dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := store.At(tt.at, tt.scope)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrNotFound)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, snapshot.Resources[0].Name)
+		})
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	assert.Equal(t, filepath.Join("/data", "mohua", "history.db"), DefaultPath())
+}
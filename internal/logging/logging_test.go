@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, ValidateFormat(FormatText))
+	assert.NoError(t, ValidateFormat(FormatJSON))
+	assert.Error(t, ValidateFormat("xml"))
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, Level(false, false))
+	assert.Equal(t, slog.LevelInfo, Level(true, false))
+	assert.Equal(t, slog.LevelDebug, Level(false, true))
+	assert.Equal(t, slog.LevelDebug, Level(true, true))
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo, FormatJSON)
+
+	logger.Debug("hidden")
+	logger.Info("listed", "operation", "ListEndpoints")
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "listed", record["msg"])
+	assert.Equal(t, "ListEndpoints", record["operation"])
+
+	buf.Reset()
+	New(&buf, slog.LevelInfo, FormatText).Info("listed", "operation", "ListApps")
+	assert.Contains(t, buf.String(), "msg=listed operation=ListApps")
+}
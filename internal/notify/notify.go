@@ -0,0 +1,232 @@
+// Package notify pushes alerts about a run, such as breached thresholds or new GPU resources,
+// to Slack, Microsoft Teams or generic JSON webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/retry"
+)
+
+// Kinds of alerts, selectable with the --notify-on flag
+const (
+	KindViolation = "violations"
+	KindNewGPU    = "new-gpu"
+)
+
+// DefaultTemplate renders the message text sent to every sink
+const DefaultTemplate = `{{len .Alerts}} mohua alert(s){{if .Region}} in {{.Region}}{{end}}:
+{{range .Alerts}}- {{.Text}}
+{{end}}`
+
+// DefaultRetry is the retry configuration for webhook requests
+var DefaultRetry = retry.Config{
+	MaxAttempts:         3,
+	InitialInterval:     1 * time.Second,
+	MaxInterval:         10 * time.Second,
+	Multiplier:          2.0,
+	RandomizationFactor: 0.1,
+}
+
+// Alert is a single condition worth notifying about
+type Alert struct {
+	// Key identifies the condition across runs for de-duplication
+	Key          string `json:"key"`
+	Kind         string `json:"kind"`
+	ResourceType string `json:"resourceType,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Region       string `json:"region,omitempty"`
+	InstanceType string `json:"instanceType,omitempty"`
+	Text         string `json:"text"`
+	// Once alerts are sent when the condition first appears and never repeated while it lasts
+	Once bool `json:"-"`
+}
+
+// Message is the data the template is rendered with and the generic webhook payload
+type Message struct {
+	Title  string    `json:"title"`
+	Text   string    `json:"text"`
+	Region string    `json:"region,omitempty"`
+	Time   time.Time `json:"time"`
+	Alerts []Alert   `json:"alerts"`
+}
+
+// ValidateKinds checks that every alert kind is supported
+func ValidateKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if kind != KindViolation && kind != KindNewGPU {
+			return fmt.Errorf("invalid alert kind %q: must be violations or new-gpu", kind)
+		}
+	}
+	return nil
+}
+
+// ViolationAlerts converts breached thresholds into alerts
+func ViolationAlerts(violations []display.Violation, region string) []Alert {
+	alerts := make([]Alert, 0, len(violations))
+	for _, v := range violations {
+		alerts = append(alerts, Alert{
+			Key:          strings.Join([]string{KindViolation, v.Threshold, region, v.ResourceType, v.Name}, "/"),
+			Kind:         KindViolation,
+			ResourceType: v.ResourceType,
+			Name:         v.Name,
+			Region:       region,
+			Text:         v.Threshold + ": " + v.Message,
+		})
+	}
+	return alerts
+}
+
+// NewGPUAlerts returns an alert for every billed resource on a GPU instance; de-duplication
+// keeps only the ones that were not running during the previous runs
+func NewGPUAlerts(resources []display.ResourceInfo) []Alert {
+	var alerts []Alert
+	for _, r := range resources {
+		if !isGPU(r.InstanceType) || r.HourlyCost == 0 {
+			continue
+		}
+		key := []string{KindNewGPU, r.Region, r.ResourceType, r.Name}
+		name := r.Name
+		if r.AppName != "" {
+			// A Studio app is named after its user profile and type, shared by the other apps
+			// of the type, so its domain, space and app name tell it apart
+			key = append(key, r.DomainID, r.SpaceName, r.AppName)
+			name = fmt.Sprintf("%s (%s)", r.Name, r.AppName)
+		}
+		alerts = append(alerts, Alert{
+			Key:          strings.Join(key, "/"),
+			Kind:         KindNewGPU,
+			ResourceType: r.ResourceType,
+			Name:         r.Name,
+			Region:       r.Region,
+			InstanceType: r.InstanceType,
+			Text:         fmt.Sprintf("new GPU %s %s on %d x %s ($%.2f/hour)", r.ResourceType, name, max(r.InstanceCount, 1), r.InstanceType, r.HourlyCost),
+			Once:         true,
+		})
+	}
+	return alerts
+}
+
+// isGPU reports whether an instance type belongs to a GPU family, e.g. ml.g5.xlarge or ml.p4d.24xlarge
+func isGPU(instanceType string) bool {
+	family, _, _ := strings.Cut(strings.TrimPrefix(instanceType, "ml."), ".")
+	return strings.HasPrefix(family, "g") || strings.HasPrefix(family, "p")
+}
+
+// Config configures a Notifier
+type Config struct {
+	Sinks []Sink
+	// Template is a text/template rendered with a Message; empty means DefaultTemplate
+	Template string
+	// StatePath is the file remembering sent alerts; empty disables de-duplication
+	StatePath string
+	// Profile is the AWS profile of the runs; with the region, it scopes the state so that
+	// runs for other regions or profiles keep their own
+	Profile string
+	// Repeat resends alerts whose condition still holds after this long; 0 never repeats
+	Repeat time.Duration
+	// Retry configures the retries of each webhook request; zero means DefaultRetry
+	Retry      retry.Config
+	HTTPClient *http.Client
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+}
+
+// Notifier renders alerts and sends them to every sink
+type Notifier struct {
+	config   Config
+	template *template.Template
+}
+
+// New validates the configuration and creates a Notifier
+func New(config Config) (*Notifier, error) {
+	if config.Template == "" {
+		config.Template = DefaultTemplate
+	}
+	tmpl, err := template.New("notification").Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %w", err)
+	}
+	if config.Retry.MaxAttempts == 0 && config.Retry.InitialInterval == 0 {
+		config.Retry = DefaultRetry
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &Notifier{config: config, template: tmpl}, nil
+}
+
+// Notify sends the alerts that are due to every sink and returns how many were sent to at
+// least one of them. Delivery is tracked per sink, so a failing sink doesn't make the others
+// repeat alerts. active tells whether alerts is the complete set of current conditions; only
+// then are conditions missing from it forgotten, so they alert again when they reappear.
+func (n *Notifier) Notify(ctx context.Context, region string, alerts []Alert, active bool) (int, error) {
+	now := n.config.Now()
+	state, err := loadState(n.config.StatePath)
+	if err != nil {
+		return 0, err
+	}
+
+	scope := region + "/" + n.config.Profile
+	sent := make(map[string]bool)
+	var errs []error
+	for _, sink := range n.config.Sinks {
+		due := state.due(scope, sink.id(), alerts, now, n.config.Repeat)
+		if len(due) == 0 {
+			continue
+		}
+		if err := n.send(ctx, sink, region, now, due); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		state.record(scope, sink.id(), due, now)
+		for _, alert := range due {
+			sent[alert.Key] = true
+		}
+	}
+	if active {
+		state.forgetExcept(scope, alerts)
+	}
+	if err := state.saveScope(n.config.StatePath, scope); err != nil {
+		errs = append(errs, err)
+	}
+	return len(sent), errors.Join(errs...)
+}
+
+// send renders the message and posts it to the sink with retries
+func (n *Notifier) send(ctx context.Context, sink Sink, region string, now time.Time, alerts []Alert) error {
+	msg := Message{
+		Title:  fmt.Sprintf("mohua: %d alert(s)", len(alerts)),
+		Region: region,
+		Time:   now.UTC(),
+		Alerts: alerts,
+	}
+	if region != "" {
+		msg.Title += " in " + region
+	}
+	var text bytes.Buffer
+	if err := n.template.Execute(&text, msg); err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+	msg.Text = text.String()
+
+	retrier := retry.NewRetrier(n.config.Retry)
+	err := retrier.Do(ctx, func() error {
+		return sink.post(ctx, n.config.HTTPClient, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %w", sink.Kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/retry"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver is an httptest webhook recording the request bodies and answering with statuses in turn
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []map[string]any
+	statuses []int
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		data, _ := io.ReadAll(req.Body)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) requests() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies
+}
+
+// fastRetry retries without waiting
+var fastRetry = retry.Config{
+	MaxAttempts:     2,
+	InitialInterval: time.Millisecond,
+	MaxInterval:     time.Millisecond,
+	Multiplier:      1,
+}
+
+func newTestNotifier(t *testing.T, sinks []Sink, statePath string, now *time.Time) *Notifier {
+	n, err := New(Config{
+		Sinks:     sinks,
+		StatePath: statePath,
+		Repeat:    24 * time.Hour,
+		Retry:     fastRetry,
+		Now:       func() time.Time { return *now },
+	})
+	assert.NoError(t, err)
+	return n
+}
+
+var testAlerts = []Alert{
+	{Key: "violations/max-hourly-cost/us-west-2//", Kind: KindViolation, Text: "max-hourly-cost: estimated hourly cost $60.00 exceeds $50.00"},
+	{Key: "new-gpu/us-west-2/Endpoint/gpu", Kind: KindNewGPU, Name: "gpu", Text: "new GPU Endpoint gpu", Once: true},
+}
+
+func TestSinkPayloads(t *testing.T) {
+	slack, teams, webhook := newReceiver(t), newReceiver(t), newReceiver(t)
+	sinks := []Sink{{SinkSlack, slack.URL}, {SinkTeams, teams.URL}, {SinkWebhook, webhook.URL}}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	sent, err := newTestNotifier(t, sinks, "", &now).Notify(context.Background(), "us-west-2", testAlerts, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	text := "2 mohua alert(s) in us-west-2:\n" +
+		"- max-hourly-cost: estimated hourly cost $60.00 exceeds $50.00\n" +
+		"- new GPU Endpoint gpu\n"
+	if assert.Len(t, slack.requests(), 1) {
+		assert.Equal(t, map[string]any{"text": text}, slack.requests()[0])
+	}
+	if assert.Len(t, teams.requests(), 1) {
+		card := teams.requests()[0]
+		assert.Equal(t, "MessageCard", card["@type"])
+		assert.Equal(t, "mohua: 2 alert(s) in us-west-2", card["title"])
+		assert.Contains(t, card["text"], "us-west-2:\n\n- max-hourly-cost")
+	}
+	if assert.Len(t, webhook.requests(), 1) {
+		body := webhook.requests()[0]
+		assert.Equal(t, text, body["text"])
+		assert.Equal(t, "us-west-2", body["region"])
+		assert.Equal(t, "2025-03-01T12:00:00Z", body["time"])
+		if alerts, ok := body["alerts"].([]any); assert.True(t, ok) && assert.Len(t, alerts, 2) {
+			assert.Equal(t, "new-gpu", alerts[1].(map[string]any)["kind"])
+			assert.Equal(t, "gpu", alerts[1].(map[string]any)["name"])
+		}
+	}
+}
+
+func TestNotifyTemplate(t *testing.T) {
+	slack := newReceiver(t)
+	n, err := New(Config{
+		Sinks:    []Sink{{SinkSlack, slack.URL}},
+		Template: `{{.Title}}{{range .Alerts}} [{{.Kind}}]{{end}}`,
+		Retry:    fastRetry,
+	})
+	assert.NoError(t, err)
+	_, err = n.Notify(context.Background(), "us-west-2", testAlerts, true)
+	assert.NoError(t, err)
+	if assert.Len(t, slack.requests(), 1) {
+		assert.Equal(t, "mohua: 2 alert(s) in us-west-2 [violations] [new-gpu]", slack.requests()[0]["text"])
+	}
+
+	_, err = New(Config{Template: "{{.Missing"})
+	assert.ErrorContains(t, err, "invalid notification template")
+}
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		wantErr  string
+	}{
+		{name: "server error then success", statuses: []int{500, 502}, requests: 3},
+		{name: "throttled then success", statuses: []int{429}, requests: 2},
+		{name: "retries exhausted", statuses: []int{500, 500, 500}, requests: 3, wantErr: "failed to notify slack: webhook returned 500"},
+		{name: "client error is not retried", statuses: []int{400}, requests: 1, wantErr: "webhook returned 400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack := newReceiver(t, tt.statuses...)
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			now := time.Now()
+			n := newTestNotifier(t, []Sink{{SinkSlack, slack.URL}}, statePath, &now)
+
+			sent, err := n.Notify(context.Background(), "us-west-2", testAlerts[:1], true)
+			assert.Len(t, slack.requests(), tt.requests)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, 0, sent)
+				// Failed alerts are not recorded, so the next run tries again
+				_, err = n.Notify(context.Background(), "us-west-2", testAlerts[:1], true)
+				assert.NoError(t, err)
+				assert.Len(t, slack.requests(), tt.requests+1)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, sent)
+		})
+	}
+}
+
+func TestNotifyDeduplication(t *testing.T) {
+	slack := newReceiver(t)
+	statePath := filepath.Join(t.TempDir(), "mohua", "notify-state.json")
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	n := newTestNotifier(t, []Sink{{SinkSlack, slack.URL}}, statePath, &now)
+	ctx := context.Background()
+
+	run := func(alerts []Alert, active bool) int {
+		sent, err := n.Notify(ctx, "us-west-2", alerts, active)
+		assert.NoError(t, err)
+		return sent
+	}
+
+	assert.Equal(t, 2, run(testAlerts, true))
+	// Every watch tick or cron run reports the same conditions, which were already sent
+	now = now.Add(time.Hour)
+	assert.Equal(t, 0, run(testAlerts, true))
+	assert.Len(t, slack.requests(), 1)
+
+	// After --notify-repeat the violation is sent again, but the new GPU resource is not new anymore
+	now = now.Add(24 * time.Hour)
+	assert.Equal(t, 1, run(testAlerts, true))
+	assert.Contains(t, slack.requests()[1]["text"], "max-hourly-cost")
+
+	// A partial run doesn't forget the GPU resource it missed
+	now = now.Add(time.Hour)
+	assert.Equal(t, 0, run(testAlerts[:1], false))
+	assert.Equal(t, 0, run(testAlerts, true))
+
+	// Once gone, a condition alerts again when it comes back
+	assert.Equal(t, 0, run(testAlerts[:1], true))
+	assert.Equal(t, 1, run(testAlerts, true))
+	assert.Contains(t, slack.requests()[2]["text"], "new GPU Endpoint gpu")
+	assert.Len(t, slack.requests(), 3)
+}
+
+func TestNotifyStateScopes(t *testing.T) {
+	slack := newReceiver(t)
+	statePath := filepath.Join(t.TempDir(), "notify-state.json")
+	now := time.Now()
+	ctx := context.Background()
+	east := []Alert{{Key: "new-gpu/us-east-1/Endpoint/gpu", Kind: KindNewGPU, Text: "new GPU Endpoint gpu", Once: true}}
+	west := []Alert{{Key: "new-gpu/eu-west-1/Notebook/gpu", Kind: KindNewGPU, Text: "new GPU Notebook gpu", Once: true}}
+
+	tests := []struct {
+		name    string
+		profile string
+		region  string
+		alerts  []Alert
+		want    int
+	}{
+		{name: "first region", region: "us-east-1", alerts: east, want: 1},
+		{name: "second region", region: "eu-west-1", alerts: west, want: 1},
+		// Each complete run only forgets the conditions of its own region
+		{name: "first region again", region: "us-east-1", alerts: east, want: 0},
+		{name: "second region again", region: "eu-west-1", alerts: west, want: 0},
+		{name: "other profile", profile: "prod", region: "us-east-1", alerts: east, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(Config{Sinks: []Sink{{SinkSlack, slack.URL}}, StatePath: statePath, Profile: tt.profile, Retry: fastRetry, Now: func() time.Time { return now }})
+			assert.NoError(t, err)
+			sent, err := n.Notify(ctx, tt.region, tt.alerts, true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sent)
+		})
+	}
+}
+
+func TestNotifyConcurrentScopes(t *testing.T) {
+	slack := newReceiver(t)
+	statePath := filepath.Join(t.TempDir(), "notify-state.json")
+	now := time.Now()
+	ctx := context.Background()
+	regions := []string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-south-1", "ap-northeast-1"}
+
+	// Cron runs for several regions share the file at the same time
+	var wg sync.WaitGroup
+	for _, region := range regions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := New(Config{Sinks: []Sink{{SinkSlack, slack.URL}}, StatePath: statePath, Retry: fastRetry, Now: func() time.Time { return now }})
+			assert.NoError(t, err)
+			_, err = n.Notify(ctx, region, []Alert{{Key: "new-gpu/" + region + "/Endpoint/gpu", Kind: KindNewGPU, Once: true}}, true)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	state, err := loadState(statePath)
+	assert.NoError(t, err)
+	assert.Len(t, state.Scopes, len(regions))
+	assert.NoFileExists(t, statePath+".lock")
+}
+
+func TestLockState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "notify-state.json")
+	lockTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = 10 * time.Second })
+
+	unlock, err := lockState(statePath)
+	assert.NoError(t, err)
+	_, err = lockState(statePath)
+	assert.ErrorContains(t, err, "is locked by another run")
+	unlock()
+
+	// A lock left behind by a crashed run is taken over
+	unlock, err = lockState(statePath)
+	assert.NoError(t, err)
+	old := time.Now().Add(-2 * staleLockAge)
+	assert.NoError(t, os.Chtimes(statePath+".lock", old, old))
+	unlock2, err := lockState(statePath)
+	assert.NoError(t, err)
+	unlock2()
+	unlock()
+}
+
+func TestNotifyPerSink(t *testing.T) {
+	slack := newReceiver(t)
+	// Teams fails the first run, retries included, then recovers
+	teams := newReceiver(t, 500, 500, 500)
+	statePath := filepath.Join(t.TempDir(), "notify-state.json")
+	now := time.Now()
+	n := newTestNotifier(t, []Sink{{SinkSlack, slack.URL}, {SinkTeams, teams.URL}}, statePath, &now)
+	ctx := context.Background()
+
+	sent, err := n.Notify(ctx, "us-west-2", testAlerts, true)
+	assert.ErrorContains(t, err, "failed to notify teams: webhook returned 500")
+	assert.Equal(t, 2, sent)
+
+	// Only the sink that failed gets the alerts on the next run
+	now = now.Add(time.Hour)
+	sent, err = n.Notify(ctx, "us-west-2", testAlerts, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Len(t, slack.requests(), 1)
+	assert.Len(t, teams.requests(), 4)
+
+	now = now.Add(time.Hour)
+	sent, err = n.Notify(ctx, "us-west-2", testAlerts, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestNewSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		url     string
+		wantErr string
+	}{
+		{name: "slack", kind: SinkSlack, url: "https://hooks.slack.com/services/T0/B0/x"},
+		{name: "teams", kind: SinkTeams, url: "https://example.webhook.office.com/webhookb2/x"},
+		{name: "webhook over http", kind: SinkWebhook, url: "http://localhost:8080/alerts"},
+		{name: "unknown kind", kind: "email", url: "https://example.com", wantErr: `invalid sink "email"`},
+		{name: "relative URL", kind: SinkSlack, url: "hooks.slack.com/x", wantErr: "invalid slack webhook URL"},
+		{name: "unsupported scheme", kind: SinkWebhook, url: "ftp://example.com", wantErr: "invalid webhook webhook URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewSink(tt.kind, tt.url)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Sink{Kind: tt.kind, URL: tt.url}, sink)
+		})
+	}
+}
+
+func TestAlertBuilders(t *testing.T) {
+	violations := []display.Violation{
+		{Threshold: display.ThresholdMaxAge, ResourceType: "Notebook", Name: "old", Message: "Notebook old has been running for 96h, more than 72h"},
+	}
+	alerts := ViolationAlerts(violations, "us-west-2")
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, "violations/max-age/us-west-2/Notebook/old", alerts[0].Key)
+		assert.Equal(t, "max-age: Notebook old has been running for 96h, more than 72h", alerts[0].Text)
+		assert.False(t, alerts[0].Once)
+	}
+
+	resources := []display.ResourceInfo{
+		{ResourceType: "Endpoint", Name: "gpu", Region: "us-west-2", InstanceType: "ml.p4d.24xlarge", InstanceCount: 2, HourlyCost: 75.54},
+		{ResourceType: "Notebook", Name: "g5", Region: "us-west-2", InstanceType: "ml.g5.xlarge", InstanceCount: 1, HourlyCost: 1.41},
+		{ResourceType: "Notebook", Name: "cpu", Region: "us-west-2", InstanceType: "ml.t3.medium", InstanceCount: 1, HourlyCost: 0.05},
+		{ResourceType: "Notebook", Name: "stopped", Region: "us-west-2", InstanceType: "ml.g4dn.xlarge", InstanceCount: 1},
+		// Two apps of one type share their display name
+		{ResourceType: "Studio", Name: "alice/JupyterLab", Region: "us-west-2", InstanceType: "ml.g5.xlarge", InstanceCount: 1, HourlyCost: 1.41, DomainID: "d-123", SpaceName: "alice-space", AppName: "default"},
+		{ResourceType: "Studio", Name: "alice/JupyterLab", Region: "us-west-2", InstanceType: "ml.g5.xlarge", InstanceCount: 1, HourlyCost: 1.41, DomainID: "d-123", SpaceName: "alice-gpu", AppName: "default"},
+	}
+	alerts = NewGPUAlerts(resources)
+	if assert.Len(t, alerts, 4) {
+		assert.Equal(t, "new-gpu/us-west-2/Endpoint/gpu", alerts[0].Key)
+		assert.Equal(t, "new GPU Endpoint gpu on 2 x ml.p4d.24xlarge ($75.54/hour)", alerts[0].Text)
+		assert.True(t, alerts[0].Once)
+		assert.Equal(t, "g5", alerts[1].Name)
+		assert.Equal(t, "new-gpu/us-west-2/Studio/alice/JupyterLab/d-123/alice-space/default", alerts[2].Key)
+		assert.Equal(t, "new-gpu/us-west-2/Studio/alice/JupyterLab/d-123/alice-gpu/default", alerts[3].Key)
+		assert.Equal(t, "new GPU Studio alice/JupyterLab (default) on 1 x ml.g5.xlarge ($1.41/hour)", alerts[3].Text)
+	}
+
+	assert.NoError(t, ValidateKinds([]string{KindViolation, KindNewGPU}))
+	assert.ErrorContains(t, ValidateKinds([]string{"cost"}), `invalid alert kind "cost"`)
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported sink kinds
+const (
+	SinkSlack   = "slack"
+	SinkTeams   = "teams"
+	SinkWebhook = "webhook"
+)
+
+// Sink is a webhook receiving notifications in the format of its kind
+type Sink struct {
+	Kind string
+	URL  string
+}
+
+// NewSink validates a webhook URL and creates a sink of the given kind
+func NewSink(kind, rawURL string) (Sink, error) {
+	switch kind {
+	case SinkSlack, SinkTeams, SinkWebhook:
+	default:
+		return Sink{}, fmt.Errorf("invalid sink %q: must be slack, teams or webhook", kind)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Sink{}, fmt.Errorf("invalid %s webhook URL: must be an absolute http or https URL", kind)
+	}
+	return Sink{Kind: kind, URL: rawURL}, nil
+}
+
+// id identifies the sink in the state file without storing its URL, which is a secret
+func (s Sink) id() string {
+	sum := sha256.Sum256([]byte(s.URL))
+	return s.Kind + ":" + hex.EncodeToString(sum[:8])
+}
+
+// payload builds the request body for the sink's kind
+func (s Sink) payload(msg Message) any {
+	switch s.Kind {
+	case SinkSlack:
+		// Slack incoming webhooks and compatible receivers, e.g. Mattermost
+		return map[string]string{"text": msg.Text}
+	case SinkTeams:
+		// Teams connector message card; Teams renders the text as markdown, where single
+		// newlines are not line breaks
+		return map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    msg.Title,
+			"title":      msg.Title,
+			"text":       strings.ReplaceAll(strings.TrimSpace(msg.Text), "\n", "\n\n"),
+			"themeColor": "D70000",
+		}
+	}
+	return msg
+}
+
+// post sends one notification; server errors and throttling are retryable
+func (s Sink) post(ctx context.Context, client *http.Client, msg Message) error {
+	body, err := json.Marshal(s.payload(msg))
+	if err != nil {
+		return &httpError{err: fmt.Errorf("failed to encode notification: %w", err)}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return &httpError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mohua")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Connection failures are worth retrying
+		return &httpError{err: err, retryable: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	hErr := &httpError{
+		err:       fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail))),
+		retryable: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		hErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return hErr
+}
+
+// httpError tells the retry package whether a failed request should be retried
+type httpError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// IsRetryable implements the interface checked by the retry package
+func (e *httpError) IsRetryable() bool {
+	return e.retryable
+}
+
+// RetryAfter returns the wait requested by the receiver, if any
+func (e *httpError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultStatePath returns $XDG_DATA_HOME/mohua/notify-state.json, or
+// ~/.local/share/mohua/notify-state.json
+func DefaultStatePath() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "mohua", "notify-state.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "mohua", "notify-state.json")
+}
+
+// state remembers when each alert was last sent to each sink, so runs from cron don't repeat
+// them. Runs for different regions and profiles share the file, each in its own scope.
+type state struct {
+	Scopes map[string]sentAlerts `json:"scopes"`
+}
+
+// sentAlerts maps an alert key to when it was last sent to each sink, by sink ID
+type sentAlerts map[string]map[string]time.Time
+
+// loadState reads the state file; a missing file, or no path, is an empty state
+func loadState(path string) (*state, error) {
+	s := &state{Scopes: make(map[string]sentAlerts)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid notification state %s: %w", path, err)
+	}
+	if s.Scopes == nil {
+		s.Scopes = make(map[string]sentAlerts)
+	}
+	return s, nil
+}
+
+// save writes the state file atomically
+func (s *state) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create notification state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notification state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	return nil
+}
+
+// saveScope writes the scope's records to the state file and keeps the other scopes as the
+// file has them now, so that concurrent runs for other regions or profiles don't drop each
+// other's records. The file is locked from reading to writing.
+func (s *state) saveScope(path, scope string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create notification state directory: %w", err)
+	}
+	unlock, err := lockState(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := loadState(path)
+	if err != nil {
+		return err
+	}
+	if alerts, ok := s.Scopes[scope]; ok {
+		current.Scopes[scope] = alerts
+	} else {
+		delete(current.Scopes, scope)
+	}
+	return current.save(path)
+}
+
+// lockTimeout is how long a run waits for another one to release the state file
+var lockTimeout = 10 * time.Second
+
+// staleLockAge is how old a lock file is when it was left behind by a run that crashed
+const staleLockAge = time.Minute
+
+// lockState takes an exclusive lock on the state file by creating a lock file next to it and
+// returns the function releasing it
+func lockState(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock notification state: %w", err)
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("notification state %s is locked by another run; remove %s if none is running", path, lock)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// due returns the alerts never sent to the sink, or last sent at least repeat ago unless they are Once
+func (s *state) due(scope, sink string, alerts []Alert, now time.Time, repeat time.Duration) []Alert {
+	var due []Alert
+	for _, alert := range alerts {
+		sent, ok := s.Scopes[scope][alert.Key][sink]
+		if ok && (alert.Once || repeat <= 0 || now.Sub(sent) < repeat) {
+			continue
+		}
+		due = append(due, alert)
+	}
+	return due
+}
+
+// record marks alerts as sent to the sink at now
+func (s *state) record(scope, sink string, alerts []Alert, now time.Time) {
+	if s.Scopes[scope] == nil {
+		s.Scopes[scope] = make(sentAlerts)
+	}
+	for _, alert := range alerts {
+		if s.Scopes[scope][alert.Key] == nil {
+			s.Scopes[scope][alert.Key] = make(map[string]time.Time)
+		}
+		s.Scopes[scope][alert.Key][sink] = now.UTC()
+	}
+}
+
+// forgetExcept forgets every alert of the scope whose condition no longer holds
+func (s *state) forgetExcept(scope string, active []Alert) {
+	keep := make(map[string]bool, len(active))
+	for _, alert := range active {
+		keep[alert.Key] = true
+	}
+	for key := range s.Scopes[scope] {
+		if !keep[key] {
+			delete(s.Scopes[scope], key)
+		}
+	}
+	if len(s.Scopes[scope]) == 0 {
+		delete(s.Scopes, scope)
+	}
+}
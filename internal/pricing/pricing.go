@@ -0,0 +1,93 @@
+package pricing
+
+import "strings"
+
+// HoursPerMonth is the number of hours used to project hourly prices to a monthly estimate
+const HoursPerMonth = 730
+
+// Table maps SageMaker instance types to their on-demand hourly price in USD
+type Table map[string]float64
+
+// Default provides approximate on-demand prices (us-east-1) for common SageMaker instance types
+var Default = Table{
+	"ml.t2.medium":    0.0464,
+	"ml.t2.large":     0.0928,
+	"ml.t2.xlarge":    0.1856,
+	"ml.t2.2xlarge":   0.3712,
+	"ml.t3.medium":    0.05,
+	"ml.t3.large":     0.10,
+	"ml.t3.xlarge":    0.20,
+	"ml.t3.2xlarge":   0.399,
+	"ml.m5.large":     0.115,
+	"ml.m5.xlarge":    0.23,
+	"ml.m5.2xlarge":   0.461,
+	"ml.m5.4xlarge":   0.922,
+	"ml.m5.12xlarge":  2.765,
+	"ml.m5.24xlarge":  5.53,
+	"ml.c5.large":     0.102,
+	"ml.c5.xlarge":    0.204,
+	"ml.c5.2xlarge":   0.408,
+	"ml.c5.4xlarge":   0.816,
+	"ml.c5.9xlarge":   1.836,
+	"ml.r5.large":     0.151,
+	"ml.r5.xlarge":    0.302,
+	"ml.r5.2xlarge":   0.605,
+	"ml.g4dn.xlarge":  0.7364,
+	"ml.g4dn.2xlarge": 0.94,
+	"ml.g4dn.4xlarge": 1.505,
+	"ml.g4dn.8xlarge": 2.72,
+	"ml.g5.xlarge":    1.408,
+	"ml.g5.2xlarge":   1.515,
+	"ml.g5.4xlarge":   2.03,
+	"ml.g5.8xlarge":   3.06,
+	"ml.g5.12xlarge":  7.09,
+	"ml.g5.48xlarge":  20.36,
+	"ml.p3.2xlarge":   3.825,
+	"ml.p3.8xlarge":   14.688,
+	"ml.p3.16xlarge":  28.152,
+	"ml.p4d.24xlarge": 37.688,
+}
+
+// unbilledStatuses lists resource statuses for which no instance charges accrue
+var unbilledStatuses = map[string]bool{
+	"Stopped":      true,
+	"Failed":       true,
+	"Deleted":      true,
+	"OutOfService": true,
+}
+
+// IsBilled reports whether a resource in the given status is accruing instance charges
+func IsBilled(status string) bool {
+	return !unbilledStatuses[status]
+}
+
+// HourlyPrice returns the hourly price for the given instance type and whether it is known
+func (t Table) HourlyPrice(instanceType string) (float64, bool) {
+	price, ok := t[strings.ToLower(instanceType)]
+	return price, ok
+}
+
+// HourlyCost returns the estimated hourly cost for a number of instances of the given type.
+// Unknown instance types are treated as zero cost.
+func (t Table) HourlyCost(instanceType string, instanceCount int) float64 {
+	price, ok := t.HourlyPrice(instanceType)
+	if !ok {
+		return 0
+	}
+	if instanceCount < 1 {
+		instanceCount = 1
+	}
+	return price * float64(instanceCount)
+}
+
+// WithOverrides returns a copy of the table with the given hourly prices added or replaced
+func (t Table) WithOverrides(prices map[string]float64) Table {
+	merged := make(Table, len(t)+len(prices))
+	for instanceType, price := range t {
+		merged[instanceType] = price
+	}
+	for instanceType, price := range prices {
+		merged[strings.ToLower(instanceType)] = price
+	}
+	return merged
+}
@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHourlyPrice(t *testing.T) {
+	price, ok := Default.HourlyPrice("ml.t3.medium")
+	assert.True(t, ok)
+	assert.Equal(t, 0.05, price)
+
+	price, ok = Default.HourlyPrice("ML.T3.MEDIUM")
+	assert.True(t, ok)
+	assert.Equal(t, 0.05, price)
+
+	_, ok = Default.HourlyPrice("unknown")
+	assert.False(t, ok)
+}
+
+func TestHourlyCost(t *testing.T) {
+	tests := []struct {
+		name          string
+		instanceType  string
+		instanceCount int
+		expected      float64
+	}{
+		{name: "single instance", instanceType: "ml.t3.large", instanceCount: 1, expected: 0.10},
+		{name: "multiple instances", instanceType: "ml.t3.large", instanceCount: 3, expected: 0.30},
+		{name: "zero count defaults to one", instanceType: "ml.t3.large", instanceCount: 0, expected: 0.10},
+		{name: "unknown instance type", instanceType: "unknown", instanceCount: 2, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, Default.HourlyCost(tt.instanceType, tt.instanceCount), 1e-9)
+		})
+	}
+}
+
+func TestIsBilled(t *testing.T) {
+	assert.True(t, IsBilled("InService"))
+	assert.True(t, IsBilled("Pending"))
+	assert.False(t, IsBilled("Stopped"))
+	assert.False(t, IsBilled("Failed"))
+	assert.False(t, IsBilled("Deleted"))
+}
+
+func TestWithOverrides(t *testing.T) {
+	table := Default.WithOverrides(map[string]float64{"ML.T3.MEDIUM": 0.04, "ml.custom.large": 2})
+
+	price, _ := table.HourlyPrice("ml.t3.medium")
+	assert.Equal(t, 0.04, price)
+	price, ok := table.HourlyPrice("ml.custom.large")
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, price)
+
+	// The default table is left untouched
+	price, _ = Default.HourlyPrice("ml.t3.medium")
+	assert.Equal(t, 0.05, price)
+}
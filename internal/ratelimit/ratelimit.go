@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Config holds the token bucket parameters
+type Config struct {
+	RequestsPerSecond float64 // Sustained request rate; zero or negative disables limiting
+	Burst             int     // Maximum number of requests allowed at once
+}
+
+// DefaultConfig keeps scans comfortably below the SageMaker List API throttling limits
+var DefaultConfig = Config{
+	RequestsPerSecond: 5,
+	Burst:             10,
+}
+
+// Limiter is a token bucket shared by every caller using the same key
+type Limiter struct {
+	key    string
+	config Config
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter with a full bucket
+func NewLimiter(key string, config Config) *Limiter {
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+	return &Limiter{
+		key:    key,
+		config: config,
+		tokens: float64(config.Burst),
+		now:    time.Now,
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Limiter{}
+)
+
+// Shared returns the limiter registered for key, creating it with config on first use.
+// Later calls with the same key share the bucket regardless of the config they pass.
+func Shared(key string, config Config) *Limiter {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if l, ok := registry[key]; ok {
+		return l
+	}
+	l := NewLimiter(key, config)
+	registry[key] = l
+	return l
+}
+
+// Wait blocks until a request may proceed or the context is done.
+// A nil or disabled limiter never blocks.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || l.config.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	slog.Debug("rate limiter wait", "key", l.key, "wait", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait for it to become available
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.config.RequestsPerSecond
+		if l.tokens > float64(l.config.Burst) {
+			l.tokens = float64(l.config.Burst)
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.config.RequestsPerSecond * float64(time.Second))
+}
+
+// cancel returns a reserved token when the caller gave up waiting
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
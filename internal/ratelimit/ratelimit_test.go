@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNow returns a controllable clock for the limiter
+func fakeNow(start time.Time) (func() time.Time, func(time.Duration)) {
+	current := start
+	return func() time.Time { return current }, func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := NewLimiter("test", Config{RequestsPerSecond: 2, Burst: 2})
+	now, advance := fakeNow(time.Unix(0, 0))
+	l.now = now
+
+	// The burst is available immediately
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+
+	// Then requests are spaced at the sustained rate
+	assert.Equal(t, 500*time.Millisecond, l.reserve())
+	assert.Equal(t, time.Second, l.reserve())
+
+	// Tokens refill over time, capped at the burst size
+	advance(10 * time.Second)
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, 500*time.Millisecond, l.reserve())
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := NewLimiter("test", Config{RequestsPerSecond: 50, Burst: 1})
+	ctx := context.Background()
+
+	start := time.Now()
+	assert.NoError(t, l.Wait(ctx))
+	assert.NoError(t, l.Wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	l := NewLimiter("test", Config{RequestsPerSecond: 0.1, Burst: 1})
+	assert.NoError(t, l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+
+	// The canceled reservation is returned to the bucket
+	assert.InDelta(t, 0, l.tokens, 0.01)
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	var nilLimiter *Limiter
+	assert.NoError(t, nilLimiter.Wait(context.Background()))
+
+	l := NewLimiter("test", Config{RequestsPerSecond: 0, Burst: 1})
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, l.Wait(context.Background()))
+	}
+}
+
+func TestShared(t *testing.T) {
+	a := Shared("default/us-east-1", DefaultConfig)
+	b := Shared("default/us-east-1", Config{RequestsPerSecond: 1, Burst: 1})
+	c := Shared("default/us-west-2", DefaultConfig)
+
+	assert.Same(t, a, b)
+	assert.NotSame(t, a, c)
+	assert.Equal(t, DefaultConfig, b.config)
+}
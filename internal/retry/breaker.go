@@ -0,0 +1,173 @@
+package retry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets every operation through
+	StateClosed State = iota
+	// StateOpen rejects operations until the cool-down has elapsed
+	StateOpen
+	// StateHalfOpen lets a single probe through to test whether the endpoint recovered
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig holds the circuit breaker parameters
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	CoolDown         time.Duration // Time the circuit stays open before a probe is allowed
+}
+
+// DefaultBreakerConfig provides reasonable default values for circuit breakers
+var DefaultBreakerConfig = BreakerConfig{
+	FailureThreshold: 5,
+	CoolDown:         30 * time.Second,
+}
+
+// CircuitOpenError is returned for operations rejected by an open circuit breaker
+type CircuitOpenError struct {
+	Name    string        // Name of the breaker, e.g. the region it guards
+	RetryIn time.Duration // Time left until the breaker allows a probe
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is open, retry in %s", e.Name, e.RetryIn.Round(time.Second))
+}
+
+// IsRetryable reports false: retrying against an open circuit is exactly what it prevents
+func (e *CircuitOpenError) IsRetryable() bool {
+	return false
+}
+
+// CircuitBreaker stops calls to an endpoint that keeps failing.
+// It is safe for concurrent use and meant to be shared by all Retriers calling the same endpoint.
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	// probedAt is when the current probe was let through
+	probedAt time.Time
+	now      func() time.Time
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*CircuitBreaker{}
+)
+
+// SharedBreaker returns the circuit breaker registered for key, creating it with name and
+// config on first use. Later calls with the same key share its state regardless of the name
+// and config they pass.
+func SharedBreaker(key, name string, config BreakerConfig) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	if b, ok := breakers[key]; ok {
+		return b
+	}
+	b := NewCircuitBreaker(name, config)
+	breakers[key] = b
+	return b
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	return &CircuitBreaker{
+		name:   name,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// State returns the current state, moving from open to half-open once the cool-down has elapsed
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// Allow returns a *CircuitOpenError if the operation must not be attempted
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+
+	switch b.state {
+	case StateOpen:
+		return &CircuitOpenError{Name: b.name, RetryIn: b.openedAt.Add(b.config.CoolDown).Sub(b.now())}
+	case StateHalfOpen:
+		// A probe that outlived the cool-down is taken as lost and replaced by this call
+		if retryIn := b.probedAt.Add(b.config.CoolDown).Sub(b.now()); b.probing && retryIn > 0 {
+			return &CircuitOpenError{Name: b.name, RetryIn: retryIn}
+		}
+		b.probing = true
+		b.probedAt = b.now()
+	}
+	return nil
+}
+
+// RecordSuccess closes the circuit and resets the failure count
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// RecordFailure counts a failure, opening the circuit at the threshold or when a probe fails
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Release ends a probe without judging the endpoint, for outcomes such as a canceled or
+// invalid request that say nothing about its health. The next call is let through as a new probe.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// advance moves an open circuit to half-open once the cool-down has elapsed; callers hold mu
+func (b *CircuitBreaker) advance() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.config.CoolDown)) {
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+}
@@ -0,0 +1,168 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	current := time.Unix(0, 0)
+	breaker := NewCircuitBreaker("us-east-1", BreakerConfig{FailureThreshold: 2, CoolDown: time.Minute})
+	breaker.now = func() time.Time { return current }
+
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+
+	// Failures below the threshold keep the circuit closed
+	breaker.RecordFailure()
+	assert.Equal(t, StateClosed, breaker.State())
+
+	// Reaching the threshold opens it
+	breaker.RecordFailure()
+	assert.Equal(t, StateOpen, breaker.State())
+	var openErr *CircuitOpenError
+	assert.ErrorAs(t, breaker.Allow(), &openErr)
+	assert.Equal(t, "us-east-1", openErr.Name)
+	assert.Equal(t, time.Minute, openErr.RetryIn)
+	assert.False(t, openErr.IsRetryable())
+
+	// After the cool-down a single probe is allowed
+	current = current.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorAs(t, breaker.Allow(), &openErr, "only one probe at a time")
+	assert.Equal(t, time.Minute, openErr.RetryIn, "rejected calls wait for the probe")
+	current = current.Add(20 * time.Second)
+	assert.ErrorAs(t, breaker.Allow(), &openErr)
+	assert.Equal(t, 40*time.Second, openErr.RetryIn)
+
+	// A failed probe reopens the circuit immediately
+	breaker.RecordFailure()
+	assert.Equal(t, StateOpen, breaker.State())
+
+	// A successful probe closes it
+	current = current.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	breaker.RecordSuccess()
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.NoError(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_LostProbe(t *testing.T) {
+	current := time.Unix(0, 0)
+	breaker := NewCircuitBreaker("us-east-1", BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+	breaker.now = func() time.Time { return current }
+
+	breaker.RecordFailure()
+	current = current.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+
+	// A probe that never reports back doesn't keep the circuit half-open forever
+	current = current.Add(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	var openErr *CircuitOpenError
+	assert.ErrorAs(t, breaker.Allow(), &openErr)
+	assert.Equal(t, time.Minute, openErr.RetryIn)
+}
+
+func TestSharedBreaker(t *testing.T) {
+	a := SharedBreaker("default/us-east-1", "us-east-1", DefaultBreakerConfig)
+	b := SharedBreaker("default/us-east-1", "us-east-1", BreakerConfig{FailureThreshold: 1})
+	c := SharedBreaker("default/us-west-2", "us-west-2", DefaultBreakerConfig)
+
+	assert.Same(t, a, b)
+	assert.NotSame(t, a, c)
+	assert.Equal(t, DefaultBreakerConfig, b.config)
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	breaker := NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 2, CoolDown: time.Minute})
+
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func TestStateString(t *testing.T) {
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "open", StateOpen.String())
+	assert.Equal(t, "half-open", StateHalfOpen.String())
+}
+
+func TestRetrier_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	breaker := NewCircuitBreaker("us-east-1", BreakerConfig{FailureThreshold: 2, CoolDown: time.Hour})
+	config := Config{
+		MaxAttempts:     5,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1.0,
+		Breaker:         breaker,
+	}
+
+	attempts := 0
+	err := NewRetrier(config).Do(ctx, func() error {
+		attempts++
+		return errors.New("service unavailable")
+	})
+
+	// The breaker opens after two failures and stops the remaining attempts
+	var openErr *CircuitOpenError
+	assert.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 2, attempts)
+
+	// Other Retriers sharing the breaker are rejected without calling the operation
+	err = NewRetrier(config).Do(ctx, func() error {
+		attempts++
+		return nil
+	})
+	assert.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetrier_CircuitBreakerIgnoresNonRetryable(t *testing.T) {
+	breaker := NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 1, CoolDown: time.Hour})
+	config := DefaultConfig
+	config.Breaker = breaker
+
+	err := NewRetrier(config).Do(context.Background(), func() error {
+		return &testRetryableError{retryable: false, message: "validation error"}
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, StateClosed, breaker.State())
+}
+
+func TestRetrier_CircuitBreakerReleasesCanceledProbe(t *testing.T) {
+	current := time.Unix(0, 0)
+	breaker := NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+	breaker.now = func() time.Time { return current }
+	breaker.RecordFailure()
+	current = current.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+
+	config := DefaultConfig
+	config.Breaker = breaker
+
+	// The probe is canceled, which says nothing about the endpoint's health
+	err := NewRetrier(config).Do(context.Background(), func() error {
+		return &testRetryableError{retryable: false, message: context.Canceled.Error()}
+	})
+	assert.EqualError(t, err, context.Canceled.Error())
+	assert.Equal(t, StateHalfOpen, breaker.State())
+
+	// The next call is let through as a new probe and closes the circuit
+	attempts := 0
+	err = NewRetrier(config).Do(context.Background(), func() error {
+		attempts++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, StateClosed, breaker.State())
+}
@@ -0,0 +1,66 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrBudgetExhausted is returned, wrapping the last operation error, when the retry budget has no tokens left
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// BudgetConfig holds the retry budget parameters
+type BudgetConfig struct {
+	Capacity      int     // Maximum number of retry tokens
+	SuccessRefund float64 // Tokens returned to the budget for every successful operation
+}
+
+// DefaultBudgetConfig allows bursts of retries while limiting sustained retrying to a fraction of successes
+var DefaultBudgetConfig = BudgetConfig{
+	Capacity:      20,
+	SuccessRefund: 0.1,
+}
+
+// Budget caps the total number of retries across all Retriers sharing it,
+// so a widespread outage does not multiply the request volume
+type Budget struct {
+	config BudgetConfig
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewBudget creates a full retry budget
+func NewBudget(config BudgetConfig) *Budget {
+	return &Budget{
+		config: config,
+		tokens: float64(config.Capacity),
+	}
+}
+
+// Withdraw takes a token for a retry, reporting false when none are left
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Deposit refunds part of a token after a successful operation
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.config.SuccessRefund
+	if b.tokens > float64(b.config.Capacity) {
+		b.tokens = float64(b.config.Capacity)
+	}
+}
+
+// Remaining returns the number of retries currently available
+func (b *Budget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.tokens)
+}
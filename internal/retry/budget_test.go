@@ -0,0 +1,53 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(BudgetConfig{Capacity: 2, SuccessRefund: 0.5})
+
+	assert.True(t, budget.Withdraw())
+	assert.True(t, budget.Withdraw())
+	assert.False(t, budget.Withdraw())
+	assert.Equal(t, 0, budget.Remaining())
+
+	// Two successes earn back one retry
+	budget.Deposit()
+	assert.False(t, budget.Withdraw())
+	budget.Deposit()
+	assert.True(t, budget.Withdraw())
+
+	// Refunds never exceed the capacity
+	for i := 0; i < 10; i++ {
+		budget.Deposit()
+	}
+	assert.Equal(t, 2, budget.Remaining())
+}
+
+func TestRetrier_BudgetExhausted(t *testing.T) {
+	budget := NewBudget(BudgetConfig{Capacity: 1})
+	config := Config{
+		MaxAttempts:     5,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1.0,
+		Budget:          budget,
+	}
+
+	lastErr := errors.New("throttled")
+	attempts := 0
+	err := NewRetrier(config).Do(context.Background(), func() error {
+		attempts++
+		return lastErr
+	})
+
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.ErrorIs(t, err, lastErr)
+	assert.Equal(t, 2, attempts, "one initial attempt plus the single budgeted retry")
+}
@@ -0,0 +1,38 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock is the source of time used by a Retrier; tests substitute a fake to avoid real sleeps
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RandSource provides the random numbers used for jitter; *rand.Rand satisfies it
+type RandSource interface {
+	// Float64 returns a number in [0.0, 1.0)
+	Float64() float64
+}
+
+// globalRand uses the math/rand top-level functions, which are safe for concurrent use
+type globalRand struct{}
+
+func (globalRand) Float64() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,59 @@
+package retry
+
+import "time"
+
+// Jitter selects how randomness is applied to the exponential backoff
+type Jitter string
+
+const (
+	// JitterProportional varies each interval by up to ±RandomizationFactor; the default
+	JitterProportional Jitter = "proportional"
+	// JitterFull waits a uniformly random time between zero and the exponential interval
+	JitterFull Jitter = "full"
+	// JitterDecorrelated waits a random time between InitialInterval and Multiplier times
+	// the previous wait, which spreads out clients that failed at the same moment
+	JitterDecorrelated Jitter = "decorrelated"
+)
+
+// backoff computes successive wait intervals for a single Do call
+type backoff struct {
+	config   Config
+	rand     RandSource
+	interval time.Duration // Current exponential interval
+	previous time.Duration // Previous wait, used by decorrelated jitter
+}
+
+func newBackoff(config Config, rand RandSource) *backoff {
+	return &backoff{
+		config:   config,
+		rand:     rand,
+		interval: config.InitialInterval,
+		previous: config.InitialInterval,
+	}
+}
+
+// next returns the wait before the next attempt, capped at MaxInterval
+func (b *backoff) next() time.Duration {
+	var wait time.Duration
+	switch b.config.Jitter {
+	case JitterFull:
+		wait = time.Duration(b.rand.Float64() * float64(b.interval))
+	case JitterDecorrelated:
+		upper := float64(b.previous) * b.config.Multiplier
+		lower := float64(b.config.InitialInterval)
+		wait = time.Duration(lower + b.rand.Float64()*(upper-lower))
+	default:
+		jitter := 1.0 + (b.rand.Float64()*2-1.0)*b.config.RandomizationFactor
+		wait = time.Duration(float64(b.interval) * jitter)
+	}
+	if wait > b.config.MaxInterval {
+		wait = b.config.MaxInterval
+	}
+
+	b.interval = time.Duration(float64(b.interval) * b.config.Multiplier)
+	if b.interval > b.config.MaxInterval {
+		b.interval = b.config.MaxInterval
+	}
+	b.previous = wait
+	return wait
+}
@@ -2,7 +2,7 @@ package retry
 
 import (
 	"context"
-	"math/rand"
+	"fmt"
 	"time"
 )
 
@@ -12,7 +12,17 @@ type Config struct {
 	InitialInterval     time.Duration // Initial backoff interval
 	MaxInterval         time.Duration // Maximum backoff interval
 	Multiplier         float64       // Backoff multiplier
-	RandomizationFactor float64       // Randomization factor for jitter
+	RandomizationFactor float64       // Randomization factor for proportional jitter
+	Jitter              Jitter        // Jitter strategy; empty means JitterProportional
+
+	Breaker *CircuitBreaker // Optional circuit breaker shared by Retriers calling the same endpoint
+	Budget  *Budget         // Optional retry budget shared by all Retriers
+
+	// OnRetry, if set, is called after a failed attempt, before waiting nextBackoff for the next one
+	OnRetry func(attempt int, err error, nextBackoff time.Duration)
+
+	Clock Clock      // Time source; nil means SystemClock
+	Rand  RandSource // Random source for jitter; nil means the math/rand global source
 }
 
 // DefaultConfig provides reasonable default values for retry configuration
@@ -24,63 +34,171 @@ var DefaultConfig = Config{
 	RandomizationFactor: 0.1,
 }
 
+// Classification describes how an operation executed by a Retrier ended
+type Classification string
+
+const (
+	ClassSuccess          Classification = "success"
+	ClassNonRetryable     Classification = "non-retryable"
+	ClassRetriesExhausted Classification = "retries-exhausted"
+	ClassCircuitOpen      Classification = "circuit-open"
+	ClassBudgetExhausted  Classification = "budget-exhausted"
+	ClassCanceled         Classification = "canceled"
+)
+
+// Result records the attempts made by DoWithResult
+type Result struct {
+	Attempts       int            // Number of times the operation was executed
+	Elapsed        time.Duration  // Total time from the first attempt until Do returned
+	Backoff        time.Duration  // Time spent waiting between attempts
+	Classification Classification // How the operation ended
+}
+
+// Error is returned by DoWithResult when the operation ultimately failed
+type Error struct {
+	Result
+	Err error // The last error returned by the operation, or the rejection reason
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // Retrier handles the retry logic with exponential backoff
 type Retrier struct {
 	config Config
+	clock  Clock
+	rand   RandSource
 }
 
 // NewRetrier creates a new Retrier with the given configuration
 func NewRetrier(config Config) *Retrier {
-	return &Retrier{config: config}
+	r := &Retrier{
+		config: config,
+		clock:  config.Clock,
+		rand:   config.Rand,
+	}
+	if r.clock == nil {
+		r.clock = SystemClock
+	}
+	if r.rand == nil {
+		r.rand = globalRand{}
+	}
+	return r
 }
 
 // Do executes the given operation with retry logic
 func (r *Retrier) Do(ctx context.Context, operation func() error) error {
-	var err error
-	currentInterval := r.config.InitialInterval
+	_, err := r.run(ctx, operation)
+	return err
+}
+
+// DoWithResult executes the given operation with retry logic like Do, and additionally
+// reports the attempts made. On failure the error is an *Error carrying the same Result.
+func (r *Retrier) DoWithResult(ctx context.Context, operation func() error) (Result, error) {
+	result, err := r.run(ctx, operation)
+	if err != nil {
+		return result, &Error{Result: result, Err: err}
+	}
+	return result, nil
+}
+
+func (r *Retrier) run(ctx context.Context, operation func() error) (result Result, err error) {
+	start := r.clock.Now()
+	defer func() {
+		result.Elapsed = r.clock.Now().Sub(start)
+	}()
+
+	backoffs := newBackoff(r.config, r.rand)
 
 	for attempt := 0; attempt <= r.config.MaxAttempts; attempt++ {
+		// Reject the operation while the endpoint is known to be failing
+		if r.config.Breaker != nil {
+			if openErr := r.config.Breaker.Allow(); openErr != nil {
+				result.Classification = ClassCircuitOpen
+				return result, openErr
+			}
+		}
+
 		// Execute the operation
+		result.Attempts++
 		err = operation()
 		if err == nil {
-			return nil
+			if r.config.Breaker != nil {
+				r.config.Breaker.RecordSuccess()
+			}
+			if r.config.Budget != nil {
+				r.config.Budget.Deposit()
+			}
+			result.Classification = ClassSuccess
+			return result, nil
 		}
 
-		// Check if error is retryable
+		// Check if error is retryable; non-retryable errors say nothing about the endpoint's health
 		if retryable, ok := err.(interface{ IsRetryable() bool }); ok && !retryable.IsRetryable() {
-			return err
+			if r.config.Breaker != nil {
+				r.config.Breaker.Release()
+			}
+			result.Classification = ClassNonRetryable
+			return result, err
+		}
+		if r.config.Breaker != nil {
+			r.config.Breaker.RecordFailure()
+			// Don't back off just to be rejected when this failure opened the circuit
+			if openErr := r.config.Breaker.Allow(); openErr != nil {
+				result.Classification = ClassCircuitOpen
+				return result, openErr
+			}
 		}
 
 		// Check if we've exhausted all attempts
 		if attempt == r.config.MaxAttempts {
-			return err
+			result.Classification = ClassRetriesExhausted
+			return result, err
+		}
+
+		// Check the shared budget before spending another attempt
+		if r.config.Budget != nil && !r.config.Budget.Withdraw() {
+			result.Classification = ClassBudgetExhausted
+			return result, fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
 		}
 
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			result.Classification = ClassCanceled
+			return result, ctx.Err()
 		default:
 			// Calculate next backoff duration with jitter
-			jitter := 1.0 + (rand.Float64()*2-1.0)*r.config.RandomizationFactor
-			backoff := time.Duration(float64(currentInterval) * jitter)
+			backoff := backoffs.next()
+			// Honor a server-provided Retry-After hint when it asks for a longer wait
+			if hinted, ok := err.(interface{ RetryAfter() time.Duration }); ok && hinted.RetryAfter() > backoff {
+				backoff = hinted.RetryAfter()
+			}
 			if backoff > r.config.MaxInterval {
 				backoff = r.config.MaxInterval
 			}
 
+			if r.config.OnRetry != nil {
+				r.config.OnRetry(result.Attempts, err, backoff)
+			}
+
 			// Wait for backoff duration
-			timer := time.NewTimer(backoff)
+			waitStart := r.clock.Now()
 			select {
 			case <-ctx.Done():
-				timer.Stop()
-				return ctx.Err()
-			case <-timer.C:
+				result.Backoff += r.clock.Now().Sub(waitStart)
+				result.Classification = ClassCanceled
+				return result, ctx.Err()
+			case <-r.clock.After(backoff):
 			}
-
-			// Update interval for next iteration
-			currentInterval = time.Duration(float64(currentInterval) * r.config.Multiplier)
+			result.Backoff += r.clock.Now().Sub(waitStart)
 		}
 	}
 
-	return err
+	return result, err
 }
@@ -3,9 +3,7 @@ package retry
 import (
 	"context"
 	"errors"
-	"fmt"
-	"math"
-	"sync/atomic"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -26,35 +24,81 @@ func (e *testRetryableError) IsRetryable() bool {
 	return e.retryable
 }
 
+// fakeClock advances instantly whenever the retrier waits and records every wait
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// fixedRand returns the given values in order, repeating the last one
+type fixedRand struct {
+	values []float64
+}
+
+func (r *fixedRand) Float64() float64 {
+	v := r.values[0]
+	if len(r.values) > 1 {
+		r.values = r.values[1:]
+	}
+	return v
+}
+
+// newFakeClockRetrier creates a retrier that never sleeps, returning the clock to inspect its waits
+func newFakeClockRetrier(config Config) (*Retrier, *fakeClock) {
+	clock := newFakeClock()
+	config.Clock = clock
+	return NewRetrier(config), clock
+}
+
+// failTimes returns an operation failing with a retryable error n times before succeeding
+func failTimes(n int, attempts *int) func() error {
+	return func() error {
+		*attempts++
+		if *attempts <= n {
+			return errors.New("temporary error")
+		}
+		return nil
+	}
+}
+
 func TestRetrier_SuccessOnFirstAttempt(t *testing.T) {
 	ctx := context.Background()
-	retrier := NewRetrier(DefaultConfig)
+	retrier, clock := newFakeClockRetrier(DefaultConfig)
 
 	attempts := 0
-	err := retrier.Do(ctx, func() error {
-		attempts++
-		return nil
-	})
+	err := retrier.Do(ctx, failTimes(0, &attempts))
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, attempts)
+	assert.Empty(t, clock.waits)
 }
 
 func TestRetrier_SuccessOnSubsequentAttempt(t *testing.T) {
 	ctx := context.Background()
-	retrier := NewRetrier(DefaultConfig)
+	retrier, clock := newFakeClockRetrier(DefaultConfig)
 
 	attempts := 0
-	err := retrier.Do(ctx, func() error {
-		attempts++
-		if attempts < 3 {
-			return errors.New("temporary error")
-		}
-		return nil
-	})
+	err := retrier.Do(ctx, failTimes(2, &attempts))
 
 	assert.NoError(t, err)
 	assert.Equal(t, 3, attempts)
+	assert.Len(t, clock.waits, 2)
 }
 
 func TestRetrier_MaxAttemptsExceeded(t *testing.T) {
@@ -66,7 +110,7 @@ func TestRetrier_MaxAttemptsExceeded(t *testing.T) {
 		Multiplier:          2.0,
 		RandomizationFactor: 0.1,
 	}
-	retrier := NewRetrier(config)
+	retrier, _ := newFakeClockRetrier(config)
 
 	attempts := 0
 	err := retrier.Do(ctx, func() error {
@@ -80,7 +124,7 @@ func TestRetrier_MaxAttemptsExceeded(t *testing.T) {
 
 func TestRetrier_NonRetryableError(t *testing.T) {
 	ctx := context.Background()
-	retrier := NewRetrier(DefaultConfig)
+	retrier, _ := newFakeClockRetrier(DefaultConfig)
 
 	attempts := 0
 	nonRetryableErr := &testRetryableError{retryable: false, message: "non-retryable error"}
@@ -96,109 +140,293 @@ func TestRetrier_NonRetryableError(t *testing.T) {
 
 func TestRetrier_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
-	retrier := NewRetrier(DefaultConfig)
-
-	var attempts int32 = 0
-	errChan := make(chan error, 1)
-
-	go func() {
-		errChan <- retrier.Do(ctx, func() error {
-			atomic.AddInt32(&attempts, 1)
-			time.Sleep(100 * time.Millisecond)
-			return errors.New("temporary error")
-		})
-	}()
+	retrier, clock := newFakeClockRetrier(DefaultConfig)
 
-	// Cancel context quickly
-	time.Sleep(10 * time.Millisecond)
-	cancel()
+	attempts := 0
+	err := retrier.Do(ctx, func() error {
+		attempts++
+		// Cancel while the operation is in flight
+		cancel()
+		return errors.New("temporary error")
+	})
 
-	err := <-errChan
 	assert.Error(t, err)
 	assert.Equal(t, context.Canceled, err)
-	assert.LessOrEqual(t, atomic.LoadInt32(&attempts), int32(2))
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, clock.waits)
 }
 
 func TestRetrier_BackoffTiming(t *testing.T) {
 	ctx := context.Background()
 	config := Config{
-		MaxAttempts:         3,
+		MaxAttempts:         5,
 		InitialInterval:     50 * time.Millisecond,
-		MaxInterval:         500 * time.Millisecond,
+		MaxInterval:         300 * time.Millisecond,
 		Multiplier:          2.0,
 		RandomizationFactor: 0.0,
 	}
-	retrier := NewRetrier(config)
+	retrier, clock := newFakeClockRetrier(config)
 
-	var attempts []time.Time
-	err := retrier.Do(ctx, func() error {
-		attempts = append(attempts, time.Now())
-		if len(attempts) < 3 {
-			return errors.New("temporary error")
-		}
-		return nil
-	})
+	attempts := 0
+	err := retrier.Do(ctx, failTimes(5, &attempts))
 
 	assert.NoError(t, err)
-	assert.Len(t, attempts, 3)
-
-	// Check intervals between attempts
-	for i := 1; i < len(attempts); i++ {
-		interval := attempts[i].Sub(attempts[i-1])
-		
-		// First interval should be close to initial interval (with jitter)
-		if i == 1 {
-			assert.InDelta(t, config.InitialInterval.Seconds(), interval.Seconds(), 0.03, 
-				"First retry interval should be close to initial interval")
-		} else {
-			// Subsequent intervals should increase exponentially
-			expectedMinInterval := time.Duration(float64(config.InitialInterval) * math.Pow(config.Multiplier, float64(i-1)))
-			expectedMinIntervalMs := expectedMinInterval.Milliseconds()
-			
-			// Add a small buffer to account for potential slight variations
-			assert.GreaterOrEqual(t, interval.Milliseconds(), expectedMinIntervalMs, 
-				fmt.Sprintf("Retry interval should increase exponentially. Expected at least %d ms, got %d ms", 
-					expectedMinIntervalMs, interval.Milliseconds()))
-		}
+	assert.Equal(t, 6, attempts)
+	// Intervals grow exponentially and are capped at MaxInterval
+	assert.Equal(t, []time.Duration{
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		300 * time.Millisecond,
+	}, clock.waits)
+}
+
+func TestRetrier_Jitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		jitter   Jitter
+		rand     []float64
+		expected []time.Duration
+	}{
+		{
+			// Proportional jitter varies each interval by up to ±50%
+			name:     "proportional",
+			jitter:   JitterProportional,
+			rand:     []float64{0.0, 0.75, 0.5},
+			expected: []time.Duration{50 * time.Millisecond, 250 * time.Millisecond, 400 * time.Millisecond},
+		},
+		{
+			name:     "default is proportional",
+			jitter:   "",
+			rand:     []float64{1.0},
+			expected: []time.Duration{150 * time.Millisecond, 300 * time.Millisecond, 600 * time.Millisecond},
+		},
+		{
+			// Full jitter waits a fraction of the exponential interval
+			name:     "full",
+			jitter:   JitterFull,
+			rand:     []float64{0.5, 0.25, 0.0},
+			expected: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 0},
+		},
+		{
+			// Decorrelated jitter waits between InitialInterval and Multiplier times the previous wait
+			name:     "decorrelated",
+			jitter:   JitterDecorrelated,
+			rand:     []float64{1.0, 0.5, 0.0},
+			expected: []time.Duration{200 * time.Millisecond, 250 * time.Millisecond, 100 * time.Millisecond},
+		},
+		{
+			name:     "decorrelated upper bound grows by Multiplier",
+			jitter:   JitterDecorrelated,
+			rand:     []float64{1.0},
+			expected: []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+	}
 
-		// Ensure no interval exceeds max interval
-		assert.LessOrEqual(t, interval.Milliseconds(), config.MaxInterval.Milliseconds(), 
-			"Retry interval should not exceed max interval")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				MaxAttempts:         3,
+				InitialInterval:     100 * time.Millisecond,
+				MaxInterval:         1 * time.Second,
+				Multiplier:          2.0,
+				RandomizationFactor: 0.5,
+				Jitter:              tt.jitter,
+				Rand:                &fixedRand{values: tt.rand},
+			}
+			retrier, clock := newFakeClockRetrier(config)
+
+			attempts := 0
+			err := retrier.Do(context.Background(), failTimes(3, &attempts))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, clock.waits)
+		})
 	}
 }
 
-func TestRetrier_Jitter(t *testing.T) {
+func TestRetrier_SeededRandIsDeterministic(t *testing.T) {
+	run := func() []time.Duration {
+		config := DefaultConfig
+		config.Jitter = JitterFull
+		config.Rand = rand.New(rand.NewSource(42))
+		retrier, clock := newFakeClockRetrier(config)
+		attempts := 0
+		_ = retrier.Do(context.Background(), failTimes(3, &attempts))
+		return clock.waits
+	}
+
+	assert.Equal(t, run(), run())
+}
+
+// testRetryAfterError is a retryable error carrying a server-provided delay hint
+type testRetryAfterError struct {
+	after time.Duration
+}
+
+func (e *testRetryAfterError) Error() string {
+	return "throttled"
+}
+
+func (e *testRetryAfterError) IsRetryable() bool {
+	return true
+}
+
+func (e *testRetryAfterError) RetryAfter() time.Duration {
+	return e.after
+}
+
+func TestRetrier_HonorsRetryAfter(t *testing.T) {
 	ctx := context.Background()
 	config := Config{
-		MaxAttempts:         5,
-		InitialInterval:     50 * time.Millisecond,
-		MaxInterval:         500 * time.Millisecond,
+		MaxAttempts:         2,
+		InitialInterval:     1 * time.Millisecond,
+		MaxInterval:         200 * time.Millisecond,
 		Multiplier:          2.0,
-		RandomizationFactor: 0.5, // High jitter for more noticeable variation
+		RandomizationFactor: 0.0,
 	}
-	retrier := NewRetrier(config)
+	retrier, clock := newFakeClockRetrier(config)
 
-	var attempts []time.Time
+	attempts := 0
 	err := retrier.Do(ctx, func() error {
-		attempts = append(attempts, time.Now())
-		if len(attempts) < 3 {
-			return errors.New("temporary error")
+		attempts++
+		switch attempts {
+		case 1:
+			return &testRetryAfterError{after: 50 * time.Millisecond}
+		case 2:
+			// Hints beyond MaxInterval are capped
+			return &testRetryAfterError{after: time.Minute}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 200 * time.Millisecond}, clock.waits)
+}
+
+func TestRetrier_OnRetryAndResult(t *testing.T) {
+	type retryCall struct {
+		attempt int
+		err     error
+		backoff time.Duration
+	}
+	var calls []retryCall
+	config := Config{
+		MaxAttempts:         3,
+		InitialInterval:     1 * time.Second,
+		MaxInterval:         30 * time.Second,
+		Multiplier:          2.0,
+		RandomizationFactor: 0.0,
+		OnRetry: func(attempt int, err error, nextBackoff time.Duration) {
+			calls = append(calls, retryCall{attempt, err, nextBackoff})
+		},
+	}
+	retrier, _ := newFakeClockRetrier(config)
+
+	temporary := errors.New("temporary error")
+	attempts := 0
+	result, err := retrier.DoWithResult(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return temporary
 		}
 		return nil
 	})
 
 	assert.NoError(t, err)
-	assert.Len(t, attempts, 3)
-
-	// Check jitter variation
-	for i := 1; i < len(attempts); i++ {
-		interval := attempts[i].Sub(attempts[i-1])
-		
-		// Calculate expected base interval
-		baseInterval := time.Duration(float64(config.InitialInterval) * math.Pow(config.Multiplier, float64(i-1)))
-		
-		// Jitter should create variation around the base interval
-		assert.InDelta(t, baseInterval.Seconds(), interval.Seconds(), baseInterval.Seconds()*config.RandomizationFactor, 
-			"Retry interval should have jitter")
+	assert.Equal(t, Result{
+		Attempts:       3,
+		Elapsed:        3 * time.Second,
+		Backoff:        3 * time.Second,
+		Classification: ClassSuccess,
+	}, result)
+	assert.Equal(t, []retryCall{
+		{attempt: 1, err: temporary, backoff: 1 * time.Second},
+		{attempt: 2, err: temporary, backoff: 2 * time.Second},
+	}, calls)
+}
+
+func TestRetrier_DoWithResultErrors(t *testing.T) {
+	config := Config{
+		MaxAttempts:         2,
+		InitialInterval:     1 * time.Second,
+		MaxInterval:         30 * time.Second,
+		Multiplier:          2.0,
+		RandomizationFactor: 0.0,
+	}
+	nonRetryable := &testRetryableError{retryable: false, message: "bad request"}
+
+	tests := []struct {
+		name     string
+		config   func(Config) Config
+		opErr    error
+		expected Result
+		is       error
+	}{
+		{
+			name:     "retries exhausted",
+			config:   func(c Config) Config { return c },
+			opErr:    errors.New("temporary error"),
+			expected: Result{Attempts: 3, Elapsed: 3 * time.Second, Backoff: 3 * time.Second, Classification: ClassRetriesExhausted},
+		},
+		{
+			name:     "non-retryable",
+			config:   func(c Config) Config { return c },
+			opErr:    nonRetryable,
+			expected: Result{Attempts: 1, Classification: ClassNonRetryable},
+			is:       nonRetryable,
+		},
+		{
+			name: "budget exhausted",
+			config: func(c Config) Config {
+				c.Budget = NewBudget(BudgetConfig{Capacity: 1})
+				return c
+			},
+			opErr:    errors.New("temporary error"),
+			expected: Result{Attempts: 2, Elapsed: 1 * time.Second, Backoff: 1 * time.Second, Classification: ClassBudgetExhausted},
+			is:       ErrBudgetExhausted,
+		},
+		{
+			name: "circuit open",
+			config: func(c Config) Config {
+				c.Breaker = NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+				return c
+			},
+			opErr:    errors.New("temporary error"),
+			expected: Result{Attempts: 1, Classification: ClassCircuitOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrier, _ := newFakeClockRetrier(tt.config(config))
+			result, err := retrier.DoWithResult(context.Background(), func() error {
+				return tt.opErr
+			})
+
+			assert.Equal(t, tt.expected, result)
+			var retryErr *Error
+			assert.True(t, errors.As(err, &retryErr))
+			assert.Equal(t, tt.expected, retryErr.Result)
+			if tt.is != nil {
+				assert.ErrorIs(t, err, tt.is)
+			}
+		})
 	}
 }
+
+func TestRetrier_DoWithResultCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	retrier, _ := newFakeClockRetrier(DefaultConfig)
+
+	result, err := retrier.DoWithResult(ctx, func() error {
+		cancel()
+		return errors.New("temporary error")
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ClassCanceled, result.Classification)
+	assert.Equal(t, 1, result.Attempts)
+}
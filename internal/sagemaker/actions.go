@@ -0,0 +1,79 @@
+package sagemaker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+)
+
+// StopNotebook stops a notebook instance; it keeps its storage and can be started again
+func (c *clientImpl) StopNotebook(ctx context.Context, name string) error {
+	return c.mutate(ctx, "StopNotebookInstance", isStopping, func(ctx context.Context) error {
+		_, err := c.client.StopNotebookInstance(ctx, &sagemaker.StopNotebookInstanceInput{
+			NotebookInstanceName: aws.String(name),
+		})
+		return err
+	})
+}
+
+// DeleteNotebook deletes a notebook instance and its storage; SageMaker only accepts it once
+// the notebook is stopped
+func (c *clientImpl) DeleteNotebook(ctx context.Context, name string) error {
+	return c.mutate(ctx, "DeleteNotebookInstance", isDeleted, func(ctx context.Context) error {
+		_, err := c.client.DeleteNotebookInstance(ctx, &sagemaker.DeleteNotebookInstanceInput{
+			NotebookInstanceName: aws.String(name),
+		})
+		return err
+	})
+}
+
+// DeleteEndpoint deletes an endpoint; its endpoint configuration and model are kept
+func (c *clientImpl) DeleteEndpoint(ctx context.Context, name string) error {
+	return c.mutate(ctx, "DeleteEndpoint", isDeleted, func(ctx context.Context) error {
+		_, err := c.client.DeleteEndpoint(ctx, &sagemaker.DeleteEndpointInput{
+			EndpointName: aws.String(name),
+		})
+		return err
+	})
+}
+
+// DeleteStudioApp deletes a Studio app as listed by ListStudioApps, which is how Studio shuts
+// apps down. Apps in a space are addressed by the space, others by the user profile.
+func (c *clientImpl) DeleteStudioApp(ctx context.Context, app ResourceInfo) error {
+	if app.DomainID == "" || app.AppType == "" || app.Name == "" {
+		return fmt.Errorf("cannot delete Studio app %q: domain, app type and name are required", app.Name)
+	}
+	input := &sagemaker.DeleteAppInput{
+		DomainId: aws.String(app.DomainID),
+		AppType:  types.AppType(app.AppType),
+		AppName:  aws.String(app.Name),
+	}
+	if app.SpaceName != "" {
+		input.SpaceName = aws.String(app.SpaceName)
+	} else {
+		input.UserProfileName = aws.String(app.UserProfile)
+	}
+
+	return c.mutate(ctx, "DeleteApp", isDeleted, func(ctx context.Context) error {
+		_, err := c.client.DeleteApp(ctx, input)
+		return err
+	})
+}
+
+// mutate runs a request that changes a resource, with retries. An attempt that failed, e.g.
+// timed out, may still have been carried out, so when a later attempt fails with an error
+// for which done reports that the change was already made, the action succeeded.
+func (c *clientImpl) mutate(ctx context.Context, operation string, done func(error) bool, request func(ctx context.Context) error) error {
+	attempt := 0
+	return c.newRetrier(operation).Do(ctx, func() error {
+		attempt++
+		err := c.call(ctx, request)
+		if err != nil && attempt > 1 && done(err) {
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,102 @@
+package sagemaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestActions(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("StopNotebookInstance", ctx, &sagemaker.StopNotebookInstanceInput{NotebookInstanceName: aws.String("dev")}, mock.Anything).
+		Return(&sagemaker.StopNotebookInstanceOutput{}, nil)
+	mockClient.On("DeleteNotebookInstance", ctx, &sagemaker.DeleteNotebookInstanceInput{NotebookInstanceName: aws.String("dev")}, mock.Anything).
+		Return(&sagemaker.DeleteNotebookInstanceOutput{}, nil)
+	mockClient.On("DeleteEndpoint", ctx, &sagemaker.DeleteEndpointInput{EndpointName: aws.String("prod")}, mock.Anything).
+		Return(&sagemaker.DeleteEndpointOutput{}, nil)
+	mockClient.On("DeleteApp", ctx, &sagemaker.DeleteAppInput{DomainId: aws.String("d-1"), UserProfileName: aws.String("alice"), AppType: types.AppTypeJupyterServer, AppName: aws.String("default")}, mock.Anything).
+		Return(&sagemaker.DeleteAppOutput{}, nil)
+	mockClient.On("DeleteApp", ctx, &sagemaker.DeleteAppInput{DomainId: aws.String("d-1"), SpaceName: aws.String("bob-space"), AppType: types.AppTypeJupyterLab, AppName: aws.String("lab")}, mock.Anything).
+		Return(&sagemaker.DeleteAppOutput{}, nil)
+
+	client := &clientImpl{client: mockClient}
+
+	assert.NoError(t, client.StopNotebook(ctx, "dev"))
+	assert.NoError(t, client.DeleteNotebook(ctx, "dev"))
+	assert.NoError(t, client.DeleteEndpoint(ctx, "prod"))
+	assert.NoError(t, client.DeleteStudioApp(ctx, ResourceInfo{Name: "default", DomainID: "d-1", UserProfile: "alice", AppType: "JupyterServer"}))
+	// Apps in a space are addressed by the space rather than the user profile
+	assert.NoError(t, client.DeleteStudioApp(ctx, ResourceInfo{Name: "lab", DomainID: "d-1", UserProfile: "bob", SpaceName: "bob-space", AppType: "JupyterLab"}))
+	assert.ErrorContains(t, client.DeleteStudioApp(ctx, ResourceInfo{Name: "default", AppType: "JupyterServer"}), "domain, app type and name are required")
+	mockClient.AssertExpectations(t)
+}
+
+func TestActionsAfterAmbiguousFailure(t *testing.T) {
+	ctx := context.Background()
+	timeout := &smithy.GenericAPIError{Code: "RequestTimeout", Message: "request timed out"}
+	notFound := &smithy.GenericAPIError{Code: "ValidationException", Message: "Could not find endpoint \"prod\"."}
+	inUse := &smithy.GenericAPIError{Code: "ValidationException", Message: "Cannot update in-progress endpoint"}
+
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr string
+	}{
+		{name: "deleted by the attempt that timed out", errs: []error{timeout, notFound}},
+		{name: "missing from the start", errs: []error{notFound}, wantErr: "Could not find endpoint"},
+		{name: "other error on retry", errs: []error{timeout, inUse}, wantErr: "Cannot update in-progress endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSageMakerClient)
+			for _, err := range tt.errs {
+				mockClient.On("DeleteEndpoint", ctx, mock.Anything, mock.Anything).Return(nil, err).Once()
+			}
+			client := &clientImpl{client: mockClient, clock: instantClock{}}
+
+			err := client.DeleteEndpoint(ctx, "prod")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockClient.AssertNumberOfCalls(t, "DeleteEndpoint", len(tt.errs))
+		})
+	}
+}
+
+func TestAlreadyDone(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantDeleted  bool
+		wantStopping bool
+	}{
+		{name: "missing endpoint", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "Could not find endpoint \"prod\"."}, wantDeleted: true},
+		{name: "missing notebook", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "RecordNotFound"}, wantDeleted: true},
+		{name: "missing app", err: &smithy.GenericAPIError{Code: "ResourceNotFound", Message: "App does not exist."}, wantDeleted: true},
+		{name: "notebook being deleted", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "Status (Deleting) not in ([Stopped, Failed]). Unable to transition to (Deleting) for Notebook Instance (dev)."}, wantDeleted: true},
+		{name: "notebook not stopped", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "Status (InService) not in ([Stopped, Failed]). Unable to transition to (Deleting) for Notebook Instance (dev)."}},
+		{name: "notebook stopping", err: &NonRetryableError{Err: &smithy.GenericAPIError{Code: "ValidationException", Message: "Status (Stopping) not in ([InService]). Unable to transition to (Stopping) for Notebook Instance (dev)."}}, wantStopping: true},
+		{name: "notebook failed", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "Status (Failed) not in ([InService]). Unable to transition to (Stopping) for Notebook Instance (dev)."}},
+		{name: "access denied", err: &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not found in policy"}},
+		{name: "not an API error", err: errors.New("endpoint not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantDeleted, isDeleted(tt.err))
+			assert.Equal(t, tt.wantStopping, isStopping(tt.err))
+		})
+	}
+}
@@ -4,22 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
 	"github.com/aws/smithy-go"
+	"mohua/internal/awsconfig"
+	"mohua/internal/ratelimit"
 	"mohua/internal/retry"
 )
 
 // Client interface defines the methods that consumers of this package can use
 type Client interface {
 	ValidateConfiguration(ctx context.Context) (bool, error)
-	ListEndpoints(ctx context.Context) ([]ResourceInfo, error)
-	ListNotebooks(ctx context.Context) ([]ResourceInfo, error)
-	ListStudioApps(ctx context.Context) ([]ResourceInfo, error)
+	ListEndpoints(ctx context.Context, filter Filter) ([]ResourceInfo, error)
+	ListNotebooks(ctx context.Context, filter Filter) ([]ResourceInfo, error)
+	ListStudioApps(ctx context.Context, filter Filter) ([]ResourceInfo, error)
+	ListTags(ctx context.Context, arn string) (map[string]string, error)
+	StopNotebook(ctx context.Context, name string) error
+	DeleteNotebook(ctx context.Context, name string) error
+	DeleteEndpoint(ctx context.Context, name string) error
+	DeleteStudioApp(ctx context.Context, app ResourceInfo) error
+	DescribeEndpoint(ctx context.Context, name string) (Description, error)
+	DescribeNotebook(ctx context.Context, name string) (Description, error)
+	DescribeStudioApp(ctx context.Context, app ResourceInfo) (Description, error)
+	StudioAppArn(ctx context.Context, app ResourceInfo) (string, error)
 	GetRegion() string
 }
 
@@ -29,47 +41,281 @@ type SageMakerClientInterface interface {
 	ListEndpoints(ctx context.Context, params *sagemaker.ListEndpointsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListEndpointsOutput, error)
 	ListNotebookInstances(ctx context.Context, params *sagemaker.ListNotebookInstancesInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListNotebookInstancesOutput, error)
 	ListDomains(ctx context.Context, params *sagemaker.ListDomainsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListDomainsOutput, error)
+	ListTags(ctx context.Context, params *sagemaker.ListTagsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListTagsOutput, error)
+	StopNotebookInstance(ctx context.Context, params *sagemaker.StopNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.StopNotebookInstanceOutput, error)
+	DeleteNotebookInstance(ctx context.Context, params *sagemaker.DeleteNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteNotebookInstanceOutput, error)
+	DeleteEndpoint(ctx context.Context, params *sagemaker.DeleteEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteEndpointOutput, error)
+	DeleteApp(ctx context.Context, params *sagemaker.DeleteAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteAppOutput, error)
+	DescribeEndpoint(ctx context.Context, params *sagemaker.DescribeEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointOutput, error)
+	DescribeEndpointConfig(ctx context.Context, params *sagemaker.DescribeEndpointConfigInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointConfigOutput, error)
+	DescribeModel(ctx context.Context, params *sagemaker.DescribeModelInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeModelOutput, error)
+	DescribeNotebookInstance(ctx context.Context, params *sagemaker.DescribeNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error)
+	DescribeApp(ctx context.Context, params *sagemaker.DescribeAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeAppOutput, error)
+	DescribeDomain(ctx context.Context, params *sagemaker.DescribeDomainInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error)
+	DescribeUserProfile(ctx context.Context, params *sagemaker.DescribeUserProfileInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeUserProfileOutput, error)
+	DescribeSpace(ctx context.Context, params *sagemaker.DescribeSpaceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeSpaceOutput, error)
 }
 
 // clientImpl implements only the necessary SageMaker API operations
 type clientImpl struct {
 	client SageMakerClientInterface
 	region string
+	// limiter throttles every SDK call; nil means unlimited
+	limiter *ratelimit.Limiter
+	// breaker and budget are shared by every retrier of this client; nil disables them
+	breaker *retry.CircuitBreaker
+	budget  *retry.Budget
+	// onRetry is notified before every backoff; nil disables it
+	onRetry RetryHook
+	// clock is used by the retriers to wait between attempts; nil means the system clock
+	clock retry.Clock
+	// callTimeout bounds each SDK request; zero means only the caller's context applies
+	callTimeout time.Duration
+}
+
+// retryBudget caps retries across every client in the process
+var retryBudget = retry.NewBudget(retry.DefaultBudgetConfig)
+
+// listPageSize is the page size requested from the List APIs, which default to 10
+const listPageSize = 100
+
+// Option configures optional client behavior
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	rateLimit   ratelimit.Config
+	onRetry     RetryHook
+	callTimeout time.Duration
+	sdkLog      bool
+	aws         awsconfig.Options
+	recordDir   string
+	anonymize   bool
+	replayDir   string
+}
+
+// RetryHook is called when a SageMaker API operation failed and is about to be retried
+type RetryHook func(operation string, attempt int, err error, nextBackoff time.Duration)
+
+// WithRateLimit sets the client-side rate limit shared by all clients for the same profile and region
+func WithRateLimit(config ratelimit.Config) Option {
+	return func(o *clientOptions) {
+		o.rateLimit = config
+	}
+}
+
+// WithRetryHook registers a hook notified before every retry of an API operation
+func WithRetryHook(hook RetryHook) Option {
+	return func(o *clientOptions) {
+		o.onRetry = hook
+	}
+}
+
+// WithCallTimeout bounds each SDK request; a request that times out is retried
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.callTimeout = timeout
+	}
+}
+
+// WithSDKLog turns on the SDK's request, response and retry logging, written to slog at
+// debug level with signed headers redacted
+func WithSDKLog(enabled bool) Option {
+	return func(o *clientOptions) {
+		o.sdkLog = enabled
+	}
+}
+
+// WithEndpointURL sends requests to endpointURL instead of AWS, e.g. a local stand-in service
+func WithEndpointURL(endpointURL string) Option {
+	return func(o *clientOptions) {
+		o.aws.EndpointURL = endpointURL
+	}
+}
+
+// WithProfile selects the shared config profile; empty keeps AWS_PROFILE or the default profile
+func WithProfile(profile string) Option {
+	return func(o *clientOptions) {
+		o.aws.Profile = profile
+	}
+}
+
+// WithStaticCredentials replaces the default credential chain; nil keeps the default chain
+func WithStaticCredentials(creds *awsconfig.StaticCredentials) Option {
+	return func(o *clientOptions) {
+		o.aws.Credentials = creds
+	}
+}
+
+// WithRecording writes every SDK request and response to dir, replacing the account IDs in
+// ARNs with fake ones when anonymize is set
+func WithRecording(dir string, anonymize bool) Option {
+	return func(o *clientOptions) {
+		o.recordDir = dir
+		o.anonymize = anonymize
+	}
+}
+
+// WithReplay serves the responses recorded in dir instead of calling AWS; the region
+// defaults to the recorded one
+func WithReplay(dir string) Option {
+	return func(o *clientOptions) {
+		o.replayDir = dir
+	}
 }
 
 // NewClientFunc is the type for the client creation function
-type NewClientFunc func(region string) (Client, error)
+type NewClientFunc func(region string, options ...Option) (Client, error)
 
 // NewClient is the function used to create a new SageMaker client
 var NewClient NewClientFunc = newClient
 
 // newClient creates a new SageMaker client
-func newClient(region string) (Client, error) {
-	var opts []func(*config.LoadOptions) error
-	
-	// If region is provided, use it; otherwise, let AWS SDK handle region selection
-	if region != "" {
-		opts = append(opts, config.WithRegion(region))
+func newClient(region string, options ...Option) (Client, error) {
+	clientOpts := clientOptions{rateLimit: ratelimit.DefaultConfig}
+	for _, option := range options {
+		option(&clientOpts)
+	}
+	if clientOpts.replayDir != "" {
+		return newReplayClient(region, clientOpts)
 	}
-	
-	cfg, err := config.LoadDefaultConfig(context.Background(), opts...)
+
+	// If region is provided, use it; otherwise, let AWS SDK handle region selection
+	clientOpts.aws.Region = region
+	cfg, err := awsconfig.Load(context.Background(), clientOpts.aws)
 	if err != nil {
 		return nil, fmt.Errorf("unable to load AWS SDK configuration: %w", err)
 	}
 
-	// Log the effective region for debugging
+	// Log the effective configuration for debugging
 	effectiveRegion := cfg.Region
 	if effectiveRegion == "" {
 		effectiveRegion = "No region configured"
 	}
-	// fmt.Fprintf(os.Stderr, "Using AWS region: %s\n", effectiveRegion)
+	regionSource := "flag"
+	if region == "" {
+		regionSource = "aws config"
+	}
+	endpoint := aws.ToString(cfg.BaseEndpoint)
+	if endpoint == "" {
+		endpoint = "default"
+	}
+	slog.Info("resolved AWS configuration",
+		"region", effectiveRegion,
+		"regionSource", regionSource,
+		"profile", profileName(clientOpts.aws.Profile),
+		"endpoint", endpoint,
+	)
+
+	sdkClient := sagemaker.NewFromConfig(cfg, func(o *sagemaker.Options) {
+		// Retries are left to retry.Retrier, so every HTTP request goes through the rate
+		// limiter, circuit breaker and retry budget
+		o.Retryer = aws.NopRetryer{}
+		o.APIOptions = append(o.APIOptions, addTraceMiddleware)
+		if clientOpts.sdkLog {
+			o.ClientLogMode = aws.LogRequest | aws.LogResponse | aws.LogRetries
+			o.Logger = sdkLogger{}
+		}
+	})
+	var api SageMakerClientInterface = sdkClient
+	if clientOpts.recordDir != "" {
+		if api, err = newRecorder(sdkClient, clientOpts.recordDir, cfg.Region, clientOpts.anonymize); err != nil {
+			return nil, err
+		}
+	}
 
 	return &clientImpl{
-		client: sagemaker.NewFromConfig(cfg),
-		region: cfg.Region,
+		client:      api,
+		region:      cfg.Region,
+		limiter:     ratelimit.Shared(limiterKey(clientOpts.aws.Profile, cfg.Region), clientOpts.rateLimit),
+		breaker:     retry.SharedBreaker(breakerKey(clientOpts.aws.Profile, cfg.Region, aws.ToString(cfg.BaseEndpoint)), cfg.Region, retry.DefaultBreakerConfig),
+		budget:      retryBudget,
+		onRetry:     clientOpts.onRetry,
+		callTimeout: clientOpts.callTimeout,
 	}, nil
 }
 
+// newReplayClient creates a client answering from a recording. AWS configuration, rate
+// limiting and the circuit breaker are skipped since no request leaves the process.
+func newReplayClient(region string, clientOpts clientOptions) (Client, error) {
+	replay, err := newReplayer(clientOpts.replayDir)
+	if err != nil {
+		return nil, err
+	}
+	if region == "" {
+		region = replay.region
+	}
+	slog.Info("replaying recorded responses", "dir", clientOpts.replayDir, "region", region)
+
+	return &clientImpl{
+		client:      replay,
+		region:      region,
+		onRetry:     clientOpts.onRetry,
+		callTimeout: clientOpts.callTimeout,
+	}, nil
+}
+
+// newRetrier creates a retrier for the named operation sharing the client's circuit breaker and retry budget
+func (c *clientImpl) newRetrier(operation string) *retry.Retrier {
+	config := retry.DefaultConfig
+	config.Breaker = c.breaker
+	config.Budget = c.budget
+	config.Clock = c.clock
+	if c.onRetry != nil {
+		config.OnRetry = func(attempt int, err error, nextBackoff time.Duration) {
+			c.onRetry(operation, attempt, err, nextBackoff)
+		}
+	}
+	return retry.NewRetrier(config)
+}
+
+// call waits for the rate limiter, then runs a single SDK request bounded by the call timeout
+func (c *clientImpl) call(ctx context.Context, request func(ctx context.Context) error) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return WrapError(err)
+	}
+
+	callCtx := ctx
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+
+	err := request(callCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		// Only this request timed out, not the run, so another attempt may succeed
+		return &RetryableError{Err: fmt.Errorf("request timed out after %s: %w", c.callTimeout, err)}
+	}
+	return WrapError(err)
+}
+
+// limiterKey identifies the rate limit bucket; profiles stand in for accounts since
+// resolving the account ID would require an extra STS call
+func limiterKey(profile, region string) string {
+	return profileName(profile) + "/" + region
+}
+
+// breakerKey identifies the circuit breaker: clients of a profile and region share their
+// limiter's key, unless they call a custom endpoint, which fails independently of AWS
+func breakerKey(profile, region, endpoint string) string {
+	key := limiterKey(profile, region)
+	if endpoint != "" {
+		key += "@" + endpoint
+	}
+	return key
+}
+
+// profileName returns the shared config profile the SDK uses, given the selected one if any
+func profileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
 // ValidateConfiguration checks if the AWS configuration is valid and resources are likely to exist
 func (c *clientImpl) ValidateConfiguration(ctx context.Context) (bool, error) {
 	// Check if we can list domains as a lightweight way to validate configuration
@@ -77,7 +323,10 @@ func (c *clientImpl) ValidateConfiguration(ctx context.Context) (bool, error) {
 		MaxResults: aws.Int32(1), // We only need to check if we can list
 	}
 
-	_, err := c.client.ListDomains(ctx, input)
+	err := c.call(ctx, func(ctx context.Context) error {
+		_, err := c.client.ListDomains(ctx, input)
+		return err
+	})
 	if err != nil {
 		// If it's an authorization or configuration error, return false
 		var apiErr smithy.APIError
@@ -97,148 +346,293 @@ func (c *clientImpl) ValidateConfiguration(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-// ListEndpoints returns only active endpoints
-func (c *clientImpl) ListEndpoints(ctx context.Context) ([]ResourceInfo, error) {
+// ListEndpoints returns endpoints matching the filter (InService only by default)
+func (c *clientImpl) ListEndpoints(ctx context.Context, filter Filter) ([]ResourceInfo, error) {
 	var resources []ResourceInfo
-	
-	retrier := retry.NewRetrier(retry.DefaultConfig)
-	err := retrier.Do(ctx, func() error {
-		input := &sagemaker.ListEndpointsInput{}
-		output, err := c.client.ListEndpoints(ctx, input)
+
+	m, err := filter.compile(endpointStatuses(), false)
+	if err != nil || m.empty() {
+		return resources, err
+	}
+
+	untyped := m.withoutInstanceType()
+	var (
+		listed  []ResourceInfo
+		listErr error
+	)
+	retrier := c.newRetrier("ListEndpoints")
+	input := &sagemaker.ListEndpointsInput{
+		MaxResults:         aws.Int32(listPageSize),
+		StatusEquals:       types.EndpointStatus(m.statuses.serverSide()),
+		NameContains:       m.nameContains(),
+		CreationTimeBefore: m.createdBefore(),
+		CreationTimeAfter:  m.createdAfter(),
+	}
+	for {
+		var output *sagemaker.ListEndpointsOutput
+		_, err := retrier.DoWithResult(ctx, func() error {
+			return c.call(ctx, func(ctx context.Context) error {
+				var err error
+				output, err = c.client.ListEndpoints(ctx, input)
+				return err
+			})
+		})
 		if err != nil {
-			return WrapError(err)
+			// Endpoints of the earlier pages are still described and returned
+			listErr = err
+			break
 		}
 
-		resources = make([]ResourceInfo, 0, len(output.Endpoints))
 		for _, endpoint := range output.Endpoints {
-			if endpoint.EndpointStatus == types.EndpointStatusInService {
-				// we'll skip detailed endpoint config
-				resources = append(resources, ResourceInfo{
-					Name:         *endpoint.EndpointName,
-					Status:       string(endpoint.EndpointStatus),
-					InstanceType: "unknown", // Simplified version doesn't fetch detailed config
-					InstanceCount: 1,        // Default to 1 for simplified version
-					CreationTime: *endpoint.CreationTime,
-				})
+			resource := ResourceInfo{
+				Name:         aws.ToString(endpoint.EndpointName),
+				Arn:          aws.ToString(endpoint.EndpointArn),
+				Status:       string(endpoint.EndpointStatus),
+				CreationTime: aws.ToTime(endpoint.CreationTime),
+			}
+			// ListEndpoints doesn't return the instances, so only endpoints matching every
+			// other criterion are described
+			if untyped.matches(resource) {
+				listed = append(listed, resource)
 			}
 		}
 
-		return nil
-	})
+		if output.NextToken == nil {
+			break
+		}
+		next := *input
+		next.NextToken = output.NextToken
+		input = &next
+	}
 
-	return resources, err
+	errs := c.describeEndpoints(ctx, listed)
+	var failed []error
+	for i, resource := range listed {
+		if errs[i] != nil {
+			if ctx.Err() != nil {
+				return resources, errs[i]
+			}
+			failed = append(failed, fmt.Errorf("%s: %w", resource.Name, errs[i]))
+			resource.InstanceType = "unknown"
+			resource.InstanceCount = 1
+		}
+		if m.matches(resource) {
+			resources = append(resources, resource)
+		}
+	}
+	if listErr != nil {
+		return resources, listErr
+	}
+	if len(failed) > 0 {
+		// Still listed, but priced at nothing, so the failure is reported rather than logged
+		return resources, fmt.Errorf("failed to describe %d endpoint(s), their instances and cost are unknown: %w", len(failed), errors.Join(failed...))
+	}
+	return resources, nil
 }
 
-// ListNotebooks returns only running notebook instances
-func (c *clientImpl) ListNotebooks(ctx context.Context) ([]ResourceInfo, error) {
+// ListNotebooks returns notebook instances matching the filter (InService only by default)
+func (c *clientImpl) ListNotebooks(ctx context.Context, filter Filter) ([]ResourceInfo, error) {
 	var resources []ResourceInfo
 
-	retrier := retry.NewRetrier(retry.DefaultConfig)
-	err := retrier.Do(ctx, func() error {
-		input := &sagemaker.ListNotebookInstancesInput{}
-		output, err := c.client.ListNotebookInstances(ctx, input)
+	m, err := filter.compile(notebookStatuses(), false)
+	if err != nil || m.empty() {
+		return resources, err
+	}
+
+	retrier := c.newRetrier("ListNotebookInstances")
+	input := &sagemaker.ListNotebookInstancesInput{
+		MaxResults:         aws.Int32(listPageSize),
+		StatusEquals:       types.NotebookInstanceStatus(m.statuses.serverSide()),
+		NameContains:       m.nameContains(),
+		CreationTimeBefore: m.createdBefore(),
+		CreationTimeAfter:  m.createdAfter(),
+	}
+	for {
+		var output *sagemaker.ListNotebookInstancesOutput
+		_, err := retrier.DoWithResult(ctx, func() error {
+			return c.call(ctx, func(ctx context.Context) error {
+				var err error
+				output, err = c.client.ListNotebookInstances(ctx, input)
+				return err
+			})
+		})
 		if err != nil {
-			return WrapError(err)
+			return resources, err
 		}
 
-		resources = make([]ResourceInfo, 0, len(output.NotebookInstances))
 		for _, notebook := range output.NotebookInstances {
-			if notebook.NotebookInstanceStatus == types.NotebookInstanceStatusInService {
-				resources = append(resources, ResourceInfo{
-					Name:         *notebook.NotebookInstanceName,
-					Status:       string(notebook.NotebookInstanceStatus),
-					InstanceType: string(notebook.InstanceType),
-					CreationTime: *notebook.CreationTime,
-					VolumeSize:   0, // Simplified version doesn't fetch volume size
-				})
+			resource := ResourceInfo{
+				Name:         aws.ToString(notebook.NotebookInstanceName),
+				Arn:          aws.ToString(notebook.NotebookInstanceArn),
+				Status:       string(notebook.NotebookInstanceStatus),
+				InstanceType: string(notebook.InstanceType),
+				CreationTime: aws.ToTime(notebook.CreationTime),
+				VolumeSize:   0, // Simplified version doesn't fetch volume size
+			}
+			if m.matches(resource) {
+				resources = append(resources, resource)
 			}
 		}
 
-		return nil
-	})
-
-	return resources, err
+		if output.NextToken == nil {
+			return resources, nil
+		}
+		next := *input
+		next.NextToken = output.NextToken
+		input = &next
+	}
 }
 
-// ListStudioApps returns only running studio applications
 // GetRegion returns the configured region for the client
 func (c *clientImpl) GetRegion() string {
 	return c.region
 }
 
-func (c *clientImpl) ListStudioApps(ctx context.Context) ([]ResourceInfo, error) {
+// ListStudioApps returns studio applications matching the filter (InService only by default).
+// ListApps has no status, name or creation time filters, so those are always matched client-side.
+func (c *clientImpl) ListStudioApps(ctx context.Context, filter Filter) ([]ResourceInfo, error) {
 	var resources []ResourceInfo
 
-	retrier := retry.NewRetrier(retry.DefaultConfig)
-	err := retrier.Do(ctx, func() error {
-		input := &sagemaker.ListAppsInput{}
-		output, err := c.client.ListApps(ctx, input)
+	m, err := filter.compile(appStatuses(), true)
+	if err != nil || m.empty() {
+		return resources, err
+	}
+
+	retrier := c.newRetrier("ListApps")
+	input := &sagemaker.ListAppsInput{
+		MaxResults:            aws.Int32(listPageSize),
+		UserProfileNameEquals: optionalString(filter.UserProfile),
+		DomainIdEquals:        optionalString(filter.DomainID),
+	}
+	for {
+		var output *sagemaker.ListAppsOutput
+		_, err := retrier.DoWithResult(ctx, func() error {
+			return c.call(ctx, func(ctx context.Context) error {
+				var err error
+				output, err = c.client.ListApps(ctx, input)
+				return err
+			})
+		})
 		if err != nil {
-			return WrapError(err)
+			return resources, err
 		}
 
-		resources = make([]ResourceInfo, 0, len(output.Apps))
 		for _, app := range output.Apps {
-			// Only include InService status apps
-			if app.Status == types.AppStatusInService {
-				// Defensive nil checks
-				var name, userProfile, appType, instanceType, spaceName, studioType string
-				var creationTime time.Time
-
-				if app.AppName != nil {
-					name = *app.AppName
-				}
+			// Only add resource if we have a meaningful name
+			if resource := toStudioResource(app); resource.Name != "" && m.matches(resource) {
+				resources = append(resources, resource)
+			}
+		}
 
-				if app.UserProfileName != nil {
-					userProfile = *app.UserProfileName
-				}
+		if output.NextToken == nil {
+			return resources, nil
+		}
+		next := *input
+		next.NextToken = output.NextToken
+		input = &next
+	}
+}
 
-				if app.CreationTime != nil {
-					creationTime = *app.CreationTime
-				}
+// toStudioResource converts a Studio app summary, tolerating missing fields
+func toStudioResource(app types.AppDetails) ResourceInfo {
+	// Defensive nil checks
+	var name, userProfile, appType, instanceType, spaceName, studioType string
+	var creationTime time.Time
 
-				// Handle potential nil ResourceSpec
-				if app.ResourceSpec != nil {
-					instanceType = string(app.ResourceSpec.InstanceType)
-				}
+	if app.AppName != nil {
+		name = *app.AppName
+	}
 
-				// Determine Studio type and space name
-				appType = string(app.AppType)
-				
-				switch app.AppType {
-				case types.AppTypeJupyterServer:
-					studioType = "Old Studio (JupyterServer)"
-				case types.AppTypeJupyterLab:
-					studioType = "New Studio (JupyterLab)"
-				default:
-					studioType = "Unknown Studio"
-				}
+	if app.UserProfileName != nil {
+		userProfile = *app.UserProfileName
+	}
 
-				// Add SpaceName for new Studio apps
-				if app.SpaceName != nil {
-					spaceName = *app.SpaceName
-				}
+	if app.CreationTime != nil {
+		creationTime = *app.CreationTime
+	}
+
+	// Handle potential nil ResourceSpec
+	if app.ResourceSpec != nil {
+		instanceType = string(app.ResourceSpec.InstanceType)
+	}
+
+	// Determine Studio type and space name
+	appType = string(app.AppType)
+
+	studioType = studioTypeOf(app.AppType)
+
+	// Add SpaceName for new Studio apps
+	if app.SpaceName != nil {
+		spaceName = *app.SpaceName
+	}
 
-				// Only add resource if we have a meaningful name
-				if name != "" {
-					resources = append(resources, ResourceInfo{
-						Name:         name,
-						Status:       string(app.Status),
-						InstanceType: instanceType,
-						CreationTime: creationTime,
-						UserProfile:  userProfile,
-						AppType:      appType,
-						SpaceName:    spaceName,
-						StudioType:   studioType,
-					})
+	return ResourceInfo{
+		Name:         name,
+		Status:       string(app.Status),
+		InstanceType: instanceType,
+		CreationTime: creationTime,
+		UserProfile:  userProfile,
+		AppType:      appType,
+		SpaceName:    spaceName,
+		StudioType:   studioType,
+		DomainID:     aws.ToString(app.DomainId),
+	}
+}
+
+// studioTypeOf tells the Studio generation an app type belongs to
+func studioTypeOf(appType types.AppType) string {
+	switch appType {
+	case types.AppTypeJupyterServer:
+		return "Old Studio (JupyterServer)"
+	case types.AppTypeJupyterLab:
+		return "New Studio (JupyterLab)"
+	default:
+		return "Unknown Studio"
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// ListTags returns the tags attached to the resource with the given ARN
+func (c *clientImpl) ListTags(ctx context.Context, arn string) (map[string]string, error) {
+	tags := make(map[string]string)
+
+	retrier := c.newRetrier("ListTags")
+	_, err := retrier.DoWithResult(ctx, func() error {
+		var nextToken *string
+		for {
+			var output *sagemaker.ListTagsOutput
+			err := c.call(ctx, func(ctx context.Context) error {
+				var err error
+				output, err = c.client.ListTags(ctx, &sagemaker.ListTagsInput{
+					ResourceArn: aws.String(arn),
+					NextToken:   nextToken,
+				})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, tag := range output.Tags {
+				if tag.Key != nil {
+					tags[*tag.Key] = aws.ToString(tag.Value)
 				}
 			}
-		}
 
-		return nil
+			if output.NextToken == nil {
+				return nil
+			}
+			nextToken = output.NextToken
+		}
 	})
 
-	return resources, err
+	return tags, err
 }
 
 // ResourceInfo contains common fields for SageMaker resources
@@ -253,4 +647,7 @@ type ResourceInfo struct {
 	AppType       string
 	SpaceName     string    // New field for Studio spaces
 	StudioType    string    // New field for JupyterServer/JupyterLab
+	Arn           string            // Empty for Studio apps, which are not listed with an ARN; see StudioAppArn
+	DomainID      string            // Studio domain the app belongs to
+	Tags          map[string]string // Only populated when tags are requested
 }
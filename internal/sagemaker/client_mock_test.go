@@ -49,6 +49,125 @@ func (m *MockSageMakerClient) ListDomains(ctx context.Context, params *sagemaker
 	return args.Get(0).(*sagemaker.ListDomainsOutput), args.Error(1)
 }
 
+func (m *MockSageMakerClient) ListTags(ctx context.Context, params *sagemaker.ListTagsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListTagsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.ListTagsOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) StopNotebookInstance(ctx context.Context, params *sagemaker.StopNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.StopNotebookInstanceOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.StopNotebookInstanceOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DeleteNotebookInstance(ctx context.Context, params *sagemaker.DeleteNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteNotebookInstanceOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DeleteNotebookInstanceOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DeleteEndpoint(ctx context.Context, params *sagemaker.DeleteEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteEndpointOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DeleteEndpointOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DeleteApp(ctx context.Context, params *sagemaker.DeleteAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteAppOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DeleteAppOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeEndpoint(ctx context.Context, params *sagemaker.DescribeEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeEndpointOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeEndpointConfig(ctx context.Context, params *sagemaker.DescribeEndpointConfigInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointConfigOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeEndpointConfigOutput), args.Error(1)
+}
+
+// onEndpointInstances expects the endpoint to be described, as ListEndpoints does for every
+// endpoint it lists, and answers with a single variant of count instances
+func (m *MockSageMakerClient) onEndpointInstances(name, instanceType string, count int32) {
+	m.On("DescribeEndpoint", mock.Anything, &sagemaker.DescribeEndpointInput{EndpointName: aws.String(name)}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointOutput{
+			EndpointName:       aws.String(name),
+			EndpointConfigName: aws.String(name + "-config"),
+			ProductionVariants: []types.ProductionVariantSummary{{VariantName: aws.String("AllTraffic"), CurrentInstanceCount: aws.Int32(count)}},
+		}, nil)
+	m.On("DescribeEndpointConfig", mock.Anything, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String(name + "-config")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointConfigOutput{
+			ProductionVariants: []types.ProductionVariant{{VariantName: aws.String("AllTraffic"), InstanceType: types.ProductionVariantInstanceType(instanceType)}},
+		}, nil)
+}
+
+func (m *MockSageMakerClient) DescribeModel(ctx context.Context, params *sagemaker.DescribeModelInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeModelOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeModelOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeNotebookInstance(ctx context.Context, params *sagemaker.DescribeNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeNotebookInstanceOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeApp(ctx context.Context, params *sagemaker.DescribeAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeAppOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeAppOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeDomain(ctx context.Context, params *sagemaker.DescribeDomainInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeDomainOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeUserProfile(ctx context.Context, params *sagemaker.DescribeUserProfileInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeUserProfileOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeUserProfileOutput), args.Error(1)
+}
+
+func (m *MockSageMakerClient) DescribeSpace(ctx context.Context, params *sagemaker.DescribeSpaceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeSpaceOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sagemaker.DescribeSpaceOutput), args.Error(1)
+}
+
 // TestMockSageMakerClientBasic verifies that the mock client implements the interface correctly
 func TestMockSageMakerClientBasic(t *testing.T) {
 	mockClient := new(MockSageMakerClient)
@@ -65,7 +184,7 @@ func TestMockSageMakerClientBasic(t *testing.T) {
 		Return(&sagemaker.ListDomainsOutput{}, nil)
 
 	// Test ListApps
-	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{}, mock.Anything).
+	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{MaxResults: aws.Int32(listPageSize)}, mock.Anything).
 		Return(&sagemaker.ListAppsOutput{
 			Apps: []types.AppDetails{
 				{
@@ -87,7 +206,7 @@ func TestMockSageMakerClientBasic(t *testing.T) {
 	assert.True(t, hasResources)
 
 	// Test ListStudioApps
-	apps, err := client.ListStudioApps(ctx)
+	apps, err := client.ListStudioApps(ctx, Filter{})
 	assert.NoError(t, err)
 	assert.Len(t, apps, 1)
 	assert.Equal(t, "TestApp", apps[0].Name)
@@ -2,6 +2,9 @@ package sagemaker
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"sync"
 	"testing"
@@ -13,6 +16,10 @@ import (
 	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"mohua/internal/awsconfig"
+	"mohua/internal/fakesagemaker"
+	"mohua/internal/ratelimit"
+	"mohua/internal/retry"
 )
 
 func TestNewClient(t *testing.T) {
@@ -57,7 +64,7 @@ func TestListStudioApps_NilFields(t *testing.T) {
 	now := time.Now()
 
 	// Setup mock expectations
-	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{}, mock.Anything).
+	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{MaxResults: aws.Int32(listPageSize)}, mock.Anything).
 		Return(&sagemaker.ListAppsOutput{
 			Apps: []types.AppDetails{
 				{
@@ -101,7 +108,7 @@ func TestListStudioApps_NilFields(t *testing.T) {
 	}
 
 	// Call the method
-	resources, err := client.ListStudioApps(ctx)
+	resources, err := client.ListStudioApps(ctx, Filter{})
 
 	// Assert expectations
 	assert.NoError(t, err)
@@ -135,7 +142,7 @@ func TestListStudioApps_StatusHandling(t *testing.T) {
 	now := time.Now()
 
 	// Setup mock expectations
-	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{}, mock.Anything).
+	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{MaxResults: aws.Int32(listPageSize)}, mock.Anything).
 		Return(&sagemaker.ListAppsOutput{
 			Apps: []types.AppDetails{
 				{
@@ -164,7 +171,7 @@ func TestListStudioApps_StatusHandling(t *testing.T) {
 	}
 
 	// Call the method
-	resources, err := client.ListStudioApps(ctx)
+	resources, err := client.ListStudioApps(ctx, Filter{})
 
 	// Assert expectations
 	assert.NoError(t, err)
@@ -186,7 +193,7 @@ func TestConcurrentResourceListing(t *testing.T) {
 	now := time.Now()
 
 	// Setup mock expectations with delays
-	mockClient.On("ListEndpoints", ctx, &sagemaker.ListEndpointsInput{}, mock.Anything).
+	mockClient.On("ListEndpoints", ctx, &sagemaker.ListEndpointsInput{MaxResults: aws.Int32(listPageSize), StatusEquals: types.EndpointStatusInService}, mock.Anything).
 		Run(func(args mock.Arguments) {
 			time.Sleep(100 * time.Millisecond) // Simulate some delay
 		}).
@@ -200,7 +207,9 @@ func TestConcurrentResourceListing(t *testing.T) {
 			},
 		}, nil)
 
-	mockClient.On("ListNotebookInstances", ctx, &sagemaker.ListNotebookInstancesInput{}, mock.Anything).
+	mockClient.onEndpointInstances("Endpoint1", "ml.m5.large", 1)
+
+	mockClient.On("ListNotebookInstances", ctx, &sagemaker.ListNotebookInstancesInput{MaxResults: aws.Int32(listPageSize), StatusEquals: types.NotebookInstanceStatusInService}, mock.Anything).
 		Run(func(args mock.Arguments) {
 			time.Sleep(50 * time.Millisecond) // Simulate some delay
 		}).
@@ -214,7 +223,7 @@ func TestConcurrentResourceListing(t *testing.T) {
 			},
 		}, nil)
 
-	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{}, mock.Anything).
+	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{MaxResults: aws.Int32(listPageSize)}, mock.Anything).
 		Run(func(args mock.Arguments) {
 			time.Sleep(75 * time.Millisecond) // Simulate some delay
 		}).
@@ -245,17 +254,17 @@ func TestConcurrentResourceListing(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		endpointResults, endpointErr = client.ListEndpoints(ctx)
+		endpointResults, endpointErr = client.ListEndpoints(ctx, Filter{})
 	}()
 
 	go func() {
 		defer wg.Done()
-		notebookResults, notebookErr = client.ListNotebooks(ctx)
+		notebookResults, notebookErr = client.ListNotebooks(ctx, Filter{})
 	}()
 
 	go func() {
 		defer wg.Done()
-		appResults, appErr = client.ListStudioApps(ctx)
+		appResults, appErr = client.ListStudioApps(ctx, Filter{})
 	}()
 
 	wg.Wait()
@@ -314,3 +323,347 @@ func TestValidateConfiguration(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, hasResources)
 }
+
+func TestListTags(t *testing.T) {
+	ctx := context.Background()
+	arn := "arn:aws:sagemaker:us-east-1:123456789012:endpoint/test"
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListTags", ctx, &sagemaker.ListTagsInput{ResourceArn: aws.String(arn)}, mock.Anything).
+		Return(&sagemaker.ListTagsOutput{
+			Tags: []types.Tag{
+				{Key: aws.String("team"), Value: aws.String("ml-platform")},
+			},
+			NextToken: aws.String("page2"),
+		}, nil)
+	mockClient.On("ListTags", ctx, &sagemaker.ListTagsInput{ResourceArn: aws.String(arn), NextToken: aws.String("page2")}, mock.Anything).
+		Return(&sagemaker.ListTagsOutput{
+			Tags: []types.Tag{
+				{Key: aws.String("owner"), Value: aws.String("alice")},
+				{Key: nil, Value: aws.String("ignored")},
+			},
+		}, nil)
+
+	client := &clientImpl{client: mockClient}
+
+	tags, err := client.ListTags(ctx, arn)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "ml-platform", "owner": "alice"}, tags)
+	mockClient.AssertExpectations(t)
+}
+
+func TestClientRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListDomains", ctx, &sagemaker.ListDomainsInput{MaxResults: aws.Int32(1)}, mock.Anything).
+		Return(&sagemaker.ListDomainsOutput{}, nil)
+
+	client := &clientImpl{
+		client:  mockClient,
+		limiter: ratelimit.NewLimiter("test", ratelimit.Config{RequestsPerSecond: 20, Burst: 1}),
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.ValidateConfiguration(ctx)
+		assert.NoError(t, err)
+	}
+
+	// The first call uses the burst, the next two wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	mockClient.AssertNumberOfCalls(t, "ListDomains", 3)
+}
+
+func TestLimiterKey(t *testing.T) {
+	t.Setenv("AWS_PROFILE", "")
+	assert.Equal(t, "default/us-east-1", limiterKey("", "us-east-1"))
+
+	t.Setenv("AWS_PROFILE", "dev")
+	assert.Equal(t, "dev/us-east-1", limiterKey("", "us-east-1"))
+
+	// An explicitly selected profile wins over AWS_PROFILE
+	assert.Equal(t, "prod/us-east-1", limiterKey("prod", "us-east-1"))
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListApps", ctx, mock.Anything, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ServiceUnavailable", Fault: smithy.FaultServer})
+
+	client := &clientImpl{
+		client:  mockClient,
+		breaker: retry.NewCircuitBreaker("us-east-1", retry.BreakerConfig{FailureThreshold: 1, CoolDown: time.Hour}),
+	}
+
+	_, err := client.ListStudioApps(ctx, Filter{})
+	var openErr *retry.CircuitOpenError
+	assert.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "us-east-1", openErr.Name)
+	mockClient.AssertNumberOfCalls(t, "ListApps", 1)
+}
+
+func TestClientRetryMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListApps", ctx, mock.Anything, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ServiceUnavailable", Fault: smithy.FaultServer})
+
+	var operations []string
+	client := &clientImpl{
+		client:  mockClient,
+		clock:   instantClock{},
+		breaker: retry.NewCircuitBreaker("us-east-1", retry.BreakerConfig{FailureThreshold: 2, CoolDown: time.Hour}),
+		onRetry: func(operation string, attempt int, err error, nextBackoff time.Duration) {
+			operations = append(operations, operation)
+			assert.Equal(t, 1, attempt)
+			assert.Greater(t, nextBackoff, time.Duration(0))
+		},
+	}
+
+	_, err := client.ListStudioApps(ctx, Filter{})
+	var retryErr *retry.Error
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 2, retryErr.Attempts)
+	assert.Equal(t, retry.ClassCircuitOpen, retryErr.Classification)
+	assert.Equal(t, []string{"ListApps"}, operations)
+}
+
+// instantClock never blocks, so tests exercising retries don't sleep
+type instantClock struct{}
+
+func (instantClock) Now() time.Time {
+	return time.Unix(0, 0)
+}
+
+func (instantClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- time.Unix(0, 0)
+	return ch
+}
+
+func TestClientCallTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	// The first request hangs until its own deadline, the retry succeeds
+	mockClient.On("ListApps", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, context.DeadlineExceeded).Once()
+	mockClient.On("ListApps", mock.Anything, mock.Anything, mock.Anything).
+		Return(&sagemaker.ListAppsOutput{}, nil).Once()
+
+	client := &clientImpl{
+		client:      mockClient,
+		clock:       instantClock{},
+		callTimeout: 10 * time.Millisecond,
+	}
+
+	_, err := client.ListStudioApps(ctx, Filter{})
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "ListApps", 2)
+}
+
+func TestClientRunDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListApps", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, context.Canceled)
+
+	client := &clientImpl{
+		client:      mockClient,
+		clock:       instantClock{},
+		callTimeout: time.Minute,
+	}
+
+	// Cancellation of the run itself is not retried
+	_, err := client.ListStudioApps(ctx, Filter{})
+	assert.ErrorIs(t, err, context.Canceled)
+	mockClient.AssertNumberOfCalls(t, "ListApps", 1)
+}
+
+func TestNewClientEndpointURL(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+	t.Setenv("AWS_PROFILE", "")
+
+	var targets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets = append(targets, r.Header.Get("X-Amz-Target"))
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=test/")
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprint(w, `{"Domains":[]}`)
+	}))
+	defer server.Close()
+
+	client, err := newClient("us-east-1",
+		WithEndpointURL(server.URL),
+		WithStaticCredentials(&awsconfig.StaticCredentials{AccessKeyID: "test", SecretAccessKey: "test"}),
+	)
+	assert.NoError(t, err)
+
+	hasResources, err := client.ValidateConfiguration(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, hasResources)
+	assert.Equal(t, []string{"SageMaker.ListDomains"}, targets)
+}
+
+func TestNewClient_SharedBreaker(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+	t.Setenv("AWS_PROFILE", "")
+
+	first, err := newClient("eu-west-3")
+	assert.NoError(t, err)
+	second, err := newClient("eu-west-3")
+	assert.NoError(t, err)
+	other, err := newClient("eu-north-1")
+	assert.NoError(t, err)
+	custom, err := newClient("eu-west-3", WithEndpointURL("http://127.0.0.1:1"))
+	assert.NoError(t, err)
+
+	// Clients of one region stop calling it together, as they share its rate limit
+	assert.Same(t, first.(*clientImpl).breaker, second.(*clientImpl).breaker)
+	assert.NotSame(t, first.(*clientImpl).breaker, other.(*clientImpl).breaker)
+	assert.NotSame(t, first.(*clientImpl).breaker, custom.(*clientImpl).breaker)
+}
+
+func TestNewClient_OneTokenPerRequest(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+	t.Setenv("AWS_PROFILE", "")
+
+	server := fakesagemaker.New(fakesagemaker.Fixtures{
+		Notebooks: []fakesagemaker.NotebookInstance{{Name: "dev", Status: "InService", InstanceType: "ml.t3.medium"}},
+	})
+	defer server.Close()
+	server.InjectFault("ListNotebookInstances", fakesagemaker.Fault{Status: http.StatusServiceUnavailable, Code: "ServiceUnavailable", Message: "down"}, 1)
+
+	created, err := newClient("us-east-1",
+		WithEndpointURL(server.URL()),
+		WithStaticCredentials(&awsconfig.StaticCredentials{AccessKeyID: "test", SecretAccessKey: "test"}),
+	)
+	assert.NoError(t, err)
+	client := created.(*clientImpl)
+	// The bucket barely refills, so it holds one token for each of the two expected requests
+	client.limiter = ratelimit.NewLimiter("test", ratelimit.Config{RequestsPerSecond: 0.001, Burst: 2})
+	client.clock = instantClock{}
+
+	notebooks, err := client.ListNotebooks(context.Background(), Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, notebooks, 1)
+
+	// The failed request was retried by the retrier rather than the SDK, so both HTTP
+	// requests took a token and the bucket is now empty
+	assert.Equal(t, 2, server.Calls("ListNotebookInstances"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestListEndpoints_Instances(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListEndpoints", ctx, mock.Anything, mock.Anything).
+		Return(&sagemaker.ListEndpointsOutput{
+			Endpoints: []types.EndpointSummary{
+				{EndpointName: aws.String("canary"), EndpointStatus: types.EndpointStatusInService},
+				{EndpointName: aws.String("creating"), EndpointStatus: types.EndpointStatusInService},
+				{EndpointName: aws.String("cpu"), EndpointStatus: types.EndpointStatusInService},
+				{EndpointName: aws.String("gone"), EndpointStatus: types.EndpointStatusInService},
+			},
+		}, nil)
+	// Variants are summed under the first instance type; serverless ones have no instances
+	mockClient.On("DescribeEndpoint", ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("canary")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointOutput{
+			EndpointConfigName: aws.String("canary-config"),
+			ProductionVariants: []types.ProductionVariantSummary{
+				{VariantName: aws.String("blue"), CurrentInstanceCount: aws.Int32(2)},
+				{VariantName: aws.String("green"), CurrentInstanceCount: aws.Int32(1)},
+				{VariantName: aws.String("batch")},
+			},
+		}, nil)
+	mockClient.On("DescribeEndpointConfig", ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String("canary-config")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointConfigOutput{
+			ProductionVariants: []types.ProductionVariant{
+				{VariantName: aws.String("blue"), InstanceType: types.ProductionVariantInstanceTypeMlG5Xlarge},
+				{VariantName: aws.String("green"), InstanceType: types.ProductionVariantInstanceTypeMlG52xlarge},
+				{VariantName: aws.String("batch"), ServerlessConfig: &types.ProductionVariantServerlessConfig{MemorySizeInMB: aws.Int32(2048)}},
+			},
+		}, nil)
+	// An endpoint being created has no current count yet
+	mockClient.On("DescribeEndpoint", ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("creating")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointOutput{EndpointConfigName: aws.String("creating-config")}, nil)
+	mockClient.On("DescribeEndpointConfig", ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String("creating-config")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointConfigOutput{
+			ProductionVariants: []types.ProductionVariant{
+				{VariantName: aws.String("AllTraffic"), InstanceType: types.ProductionVariantInstanceTypeMlG5Xlarge, InitialInstanceCount: aws.Int32(4)},
+			},
+		}, nil)
+	mockClient.onEndpointInstances("cpu", "ml.m5.large", 1)
+	mockClient.On("DescribeEndpoint", ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("gone")}, mock.Anything).
+		Return(nil, &smithy.GenericAPIError{Code: "ValidationException", Message: "Could not find endpoint"})
+
+	client := &clientImpl{client: mockClient}
+
+	// Endpoints that cannot be described are still listed, but their cost is unknown
+	resources, err := client.ListEndpoints(ctx, Filter{})
+	assert.ErrorContains(t, err, "failed to describe 1 endpoint(s)")
+	assert.ErrorContains(t, err, "gone: api error ValidationException")
+	if assert.Len(t, resources, 4) {
+		assert.Equal(t, ResourceInfo{Name: "canary", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 3}, resources[0])
+		assert.Equal(t, ResourceInfo{Name: "creating", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 4}, resources[1])
+		assert.Equal(t, ResourceInfo{Name: "cpu", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 1}, resources[2])
+		assert.Equal(t, ResourceInfo{Name: "gone", Status: "InService", InstanceType: "unknown", InstanceCount: 1}, resources[3])
+	}
+
+	// The instance type filter applies to the described type
+	resources, err = client.ListEndpoints(ctx, Filter{InstanceType: "ml.g5.*"})
+	assert.Error(t, err)
+	assert.Len(t, resources, 2)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListEndpoints_SharedConfig(t *testing.T) {
+	ctx := context.Background()
+
+	var summaries []types.EndpointSummary
+	mockClient := new(MockSageMakerClient)
+	for i := range 10 {
+		name := fmt.Sprintf("endpoint-%d", i)
+		summaries = append(summaries, types.EndpointSummary{EndpointName: aws.String(name), EndpointStatus: types.EndpointStatusInService})
+		mockClient.On("DescribeEndpoint", ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String(name)}, mock.Anything).
+			Return(&sagemaker.DescribeEndpointOutput{
+				EndpointConfigName: aws.String("shared-config"),
+				ProductionVariants: []types.ProductionVariantSummary{{VariantName: aws.String("AllTraffic"), CurrentInstanceCount: aws.Int32(1)}},
+			}, nil)
+	}
+	mockClient.On("ListEndpoints", ctx, mock.Anything, mock.Anything).
+		Return(&sagemaker.ListEndpointsOutput{Endpoints: summaries}, nil)
+	// Endpoints deployed from one configuration describe it once
+	mockClient.On("DescribeEndpointConfig", ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String("shared-config")}, mock.Anything).
+		Return(&sagemaker.DescribeEndpointConfigOutput{
+			ProductionVariants: []types.ProductionVariant{{VariantName: aws.String("AllTraffic"), InstanceType: types.ProductionVariantInstanceTypeMlG5Xlarge}},
+		}, nil).Once()
+
+	client := &clientImpl{client: mockClient}
+
+	resources, err := client.ListEndpoints(ctx, Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, resources, 10) {
+		// Listing order is kept although the endpoints are described concurrently
+		for i, resource := range resources {
+			assert.Equal(t, fmt.Sprintf("endpoint-%d", i), resource.Name)
+			assert.Equal(t, "ml.g5.xlarge", resource.InstanceType)
+		}
+	}
+	mockClient.AssertExpectations(t)
+}
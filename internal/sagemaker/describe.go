@@ -0,0 +1,545 @@
+package sagemaker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+)
+
+// Description is the full configuration of a single resource, as returned by its Describe API.
+// Tags are not included; they are listed separately with ListTags.
+type Description struct {
+	ResourceInfo
+	FailureReason    string
+	LastModifiedTime time.Time
+	RoleArn          string
+	KmsKeyID         string
+	LifecycleConfig  string
+	Network          Network
+
+	// Endpoints
+	EndpointConfigName string
+	Variants           []Variant
+	DataCapture        bool
+
+	// Notebook instances
+	URL                   string
+	RootAccess            string
+	PlatformIdentifier    string
+	DefaultCodeRepository string
+
+	// Studio apps
+	Image            string
+	LastHealthCheck  time.Time
+	LastUserActivity time.Time
+
+	// Timeline lists the known status changes and activity, oldest first
+	Timeline []Event
+	// Warnings lists related resources that could not be described, e.g. a deleted model
+	Warnings []string
+}
+
+// Network holds the network settings of a resource; fields that don't apply are empty
+type Network struct {
+	VpcID            string
+	SubnetIDs        []string
+	SecurityGroupIDs []string
+	// DirectInternetAccess is Enabled or Disabled for notebook instances
+	DirectInternetAccess string
+	// AppNetworkAccess is PublicInternetOnly or VpcOnly for Studio domains
+	AppNetworkAccess string
+	// NetworkIsolation is set when an endpoint's containers have no network access
+	NetworkIsolation bool
+}
+
+// Variant is a production variant of an endpoint
+type Variant struct {
+	Name                 string
+	ModelName            string
+	InstanceType         string
+	CurrentInstanceCount int
+	DesiredInstanceCount int
+	Weight               float64
+	// Serverless describes the serverless configuration; empty for instance-backed variants
+	Serverless string
+}
+
+// Event is a point in a resource's timeline
+type Event struct {
+	Time        time.Time
+	Description string
+}
+
+// do runs a single SDK request with the client's retries and rate limiting
+func (c *clientImpl) do(ctx context.Context, operation string, request func(ctx context.Context) error) error {
+	return c.newRetrier(operation).Do(ctx, func() error {
+		return c.call(ctx, request)
+	})
+}
+
+// DescribeEndpoint describes an endpoint along with its endpoint configuration. The execution
+// role and VPC settings come from the configuration, or else from the model of the first
+// variant; failures to describe those are reported as warnings.
+func (c *clientImpl) DescribeEndpoint(ctx context.Context, name string) (Description, error) {
+	var endpoint *sagemaker.DescribeEndpointOutput
+	err := c.do(ctx, "DescribeEndpoint", func(ctx context.Context) error {
+		var err error
+		endpoint, err = c.client.DescribeEndpoint(ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String(name)})
+		return err
+	})
+	if err != nil {
+		return Description{}, err
+	}
+
+	d := Description{
+		ResourceInfo: ResourceInfo{
+			Name:         aws.ToString(endpoint.EndpointName),
+			Arn:          aws.ToString(endpoint.EndpointArn),
+			Status:       string(endpoint.EndpointStatus),
+			CreationTime: aws.ToTime(endpoint.CreationTime),
+		},
+		FailureReason:      aws.ToString(endpoint.FailureReason),
+		LastModifiedTime:   aws.ToTime(endpoint.LastModifiedTime),
+		EndpointConfigName: aws.ToString(endpoint.EndpointConfigName),
+	}
+	if endpoint.DataCaptureConfig != nil {
+		d.DataCapture = aws.ToBool(endpoint.DataCaptureConfig.EnableCapture)
+	}
+	d.addEvent(d.CreationTime, "Created")
+	for _, summary := range endpoint.ProductionVariants {
+		variant := Variant{
+			Name:                 aws.ToString(summary.VariantName),
+			CurrentInstanceCount: int(aws.ToInt32(summary.CurrentInstanceCount)),
+			DesiredInstanceCount: int(aws.ToInt32(summary.DesiredInstanceCount)),
+			Weight:               float64(aws.ToFloat32(summary.CurrentWeight)),
+			Serverless:           serverlessText(summary.CurrentServerlessConfig),
+		}
+		for _, status := range summary.VariantStatus {
+			event := fmt.Sprintf("Variant %s: %s", variant.Name, status.Status)
+			if message := aws.ToString(status.StatusMessage); message != "" {
+				event += " (" + message + ")"
+			}
+			d.addEvent(aws.ToTime(status.StartTime), event)
+		}
+		d.Variants = append(d.Variants, variant)
+	}
+	d.addEvent(d.LastModifiedTime, "Last modified, status "+d.Status)
+
+	if d.EndpointConfigName != "" {
+		c.describeEndpointConfig(ctx, &d)
+	}
+	for _, v := range d.Variants {
+		d.InstanceCount += v.CurrentInstanceCount
+		if d.InstanceType == "" {
+			d.InstanceType = v.InstanceType
+		}
+	}
+	d.sortTimeline()
+	return d, nil
+}
+
+// describeConcurrency bounds the endpoints ListEndpoints describes at the same time
+const describeConcurrency = 4
+
+// describeEndpoints sets the instances of the listed endpoints, describing a few at a time
+// and each endpoint configuration once. It returns the error of each endpoint, by index.
+func (c *clientImpl) describeEndpoints(ctx context.Context, endpoints []ResourceInfo) []error {
+	errs := make([]error, len(endpoints))
+	configs := &endpointConfigCache{configs: make(map[string]*cachedEndpointConfig)}
+	slots := make(chan struct{}, describeConcurrency)
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-slots }()
+			errs[i] = c.describeInstances(ctx, &endpoints[i], configs)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// endpointConfigCache describes every endpoint configuration once, as endpoints often share one
+type endpointConfigCache struct {
+	mu      sync.Mutex
+	configs map[string]*cachedEndpointConfig
+}
+
+type cachedEndpointConfig struct {
+	once   sync.Once
+	output *sagemaker.DescribeEndpointConfigOutput
+	err    error
+}
+
+// get describes the endpoint configuration, or returns the result of the first call for it
+func (cache *endpointConfigCache) get(ctx context.Context, c *clientImpl, name string) (*sagemaker.DescribeEndpointConfigOutput, error) {
+	cache.mu.Lock()
+	entry, ok := cache.configs[name]
+	if !ok {
+		entry = &cachedEndpointConfig{}
+		cache.configs[name] = entry
+	}
+	cache.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.err = c.do(ctx, "DescribeEndpointConfig", func(ctx context.Context) error {
+			var err error
+			entry.output, err = c.client.DescribeEndpointConfig(ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String(name)})
+			return err
+		})
+	})
+	return entry.output, entry.err
+}
+
+// describeInstances sets the instance type and count of a listed endpoint, which ListEndpoints
+// doesn't return: the current count of every variant comes from DescribeEndpoint and its
+// instance type from the endpoint configuration. Serverless variants have no instances, and
+// variants of different types are counted under the type of the first one.
+func (c *clientImpl) describeInstances(ctx context.Context, r *ResourceInfo, configs *endpointConfigCache) error {
+	var endpoint *sagemaker.DescribeEndpointOutput
+	err := c.do(ctx, "DescribeEndpoint", func(ctx context.Context) error {
+		var err error
+		endpoint, err = c.client.DescribeEndpoint(ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String(r.Name)})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	config, err := configs.get(ctx, c, aws.ToString(endpoint.EndpointConfigName))
+	if err != nil {
+		return err
+	}
+	current := make(map[string]int, len(endpoint.ProductionVariants))
+	for _, summary := range endpoint.ProductionVariants {
+		current[aws.ToString(summary.VariantName)] = int(aws.ToInt32(summary.CurrentInstanceCount))
+	}
+	r.InstanceType, r.InstanceCount = "", 0
+	for _, pv := range config.ProductionVariants {
+		if pv.InstanceType == "" {
+			continue
+		}
+		if r.InstanceType == "" {
+			r.InstanceType = string(pv.InstanceType)
+		}
+		count, ok := current[aws.ToString(pv.VariantName)]
+		if !ok {
+			// An endpoint being created has no current count yet
+			count = int(aws.ToInt32(pv.InitialInstanceCount))
+		}
+		r.InstanceCount += count
+	}
+	return nil
+}
+
+// describeEndpointConfig adds the KMS key, variant configuration, role and network settings
+func (c *clientImpl) describeEndpointConfig(ctx context.Context, d *Description) {
+	var config *sagemaker.DescribeEndpointConfigOutput
+	err := c.do(ctx, "DescribeEndpointConfig", func(ctx context.Context) error {
+		var err error
+		config, err = c.client.DescribeEndpointConfig(ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String(d.EndpointConfigName)})
+		return err
+	})
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("failed to describe endpoint configuration %s: %v", d.EndpointConfigName, err))
+		return
+	}
+
+	d.KmsKeyID = aws.ToString(config.KmsKeyId)
+	d.RoleArn = aws.ToString(config.ExecutionRoleArn)
+	d.Network.NetworkIsolation = aws.ToBool(config.EnableNetworkIsolation)
+	if config.VpcConfig != nil {
+		d.Network.SubnetIDs = config.VpcConfig.Subnets
+		d.Network.SecurityGroupIDs = config.VpcConfig.SecurityGroupIds
+	}
+	for _, pv := range config.ProductionVariants {
+		i := d.variant(aws.ToString(pv.VariantName))
+		v := &d.Variants[i]
+		v.ModelName = aws.ToString(pv.ModelName)
+		v.InstanceType = string(pv.InstanceType)
+		if v.Serverless == "" {
+			v.Serverless = serverlessText(pv.ServerlessConfig)
+		}
+		// An endpoint being created has no current count yet
+		if v.DesiredInstanceCount == 0 && pv.InitialInstanceCount != nil {
+			v.DesiredInstanceCount = int(*pv.InitialInstanceCount)
+		}
+	}
+
+	if d.RoleArn == "" && config.VpcConfig == nil && len(d.Variants) > 0 && d.Variants[0].ModelName != "" {
+		c.describeModel(ctx, d, d.Variants[0].ModelName)
+	}
+}
+
+// describeModel adds the role and network settings of the endpoint's model
+func (c *clientImpl) describeModel(ctx context.Context, d *Description, name string) {
+	var model *sagemaker.DescribeModelOutput
+	err := c.do(ctx, "DescribeModel", func(ctx context.Context) error {
+		var err error
+		model, err = c.client.DescribeModel(ctx, &sagemaker.DescribeModelInput{ModelName: aws.String(name)})
+		return err
+	})
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("failed to describe model %s: %v", name, err))
+		return
+	}
+
+	d.RoleArn = aws.ToString(model.ExecutionRoleArn)
+	d.Network.NetworkIsolation = d.Network.NetworkIsolation || aws.ToBool(model.EnableNetworkIsolation)
+	if model.VpcConfig != nil {
+		d.Network.SubnetIDs = model.VpcConfig.Subnets
+		d.Network.SecurityGroupIDs = model.VpcConfig.SecurityGroupIds
+	}
+}
+
+// variant returns the index of the named variant, adding it when the endpoint didn't list it
+func (d *Description) variant(name string) int {
+	for i, v := range d.Variants {
+		if v.Name == name {
+			return i
+		}
+	}
+	d.Variants = append(d.Variants, Variant{Name: name})
+	return len(d.Variants) - 1
+}
+
+// DescribeNotebook describes a notebook instance
+func (c *clientImpl) DescribeNotebook(ctx context.Context, name string) (Description, error) {
+	var notebook *sagemaker.DescribeNotebookInstanceOutput
+	err := c.do(ctx, "DescribeNotebookInstance", func(ctx context.Context) error {
+		var err error
+		notebook, err = c.client.DescribeNotebookInstance(ctx, &sagemaker.DescribeNotebookInstanceInput{NotebookInstanceName: aws.String(name)})
+		return err
+	})
+	if err != nil {
+		return Description{}, err
+	}
+
+	d := Description{
+		ResourceInfo: ResourceInfo{
+			Name:          aws.ToString(notebook.NotebookInstanceName),
+			Arn:           aws.ToString(notebook.NotebookInstanceArn),
+			Status:        string(notebook.NotebookInstanceStatus),
+			InstanceType:  string(notebook.InstanceType),
+			InstanceCount: 1,
+			CreationTime:  aws.ToTime(notebook.CreationTime),
+			VolumeSize:    int(aws.ToInt32(notebook.VolumeSizeInGB)),
+		},
+		FailureReason:    aws.ToString(notebook.FailureReason),
+		LastModifiedTime: aws.ToTime(notebook.LastModifiedTime),
+		RoleArn:          aws.ToString(notebook.RoleArn),
+		KmsKeyID:         aws.ToString(notebook.KmsKeyId),
+		LifecycleConfig:  aws.ToString(notebook.NotebookInstanceLifecycleConfigName),
+		Network: Network{
+			SecurityGroupIDs:     notebook.SecurityGroups,
+			DirectInternetAccess: string(notebook.DirectInternetAccess),
+		},
+		URL:                   aws.ToString(notebook.Url),
+		RootAccess:            string(notebook.RootAccess),
+		PlatformIdentifier:    aws.ToString(notebook.PlatformIdentifier),
+		DefaultCodeRepository: aws.ToString(notebook.DefaultCodeRepository),
+	}
+	if subnet := aws.ToString(notebook.SubnetId); subnet != "" {
+		d.Network.SubnetIDs = []string{subnet}
+	}
+	d.addEvent(d.CreationTime, "Created")
+	d.addEvent(d.LastModifiedTime, "Last modified, status "+d.Status)
+	d.sortTimeline()
+	return d, nil
+}
+
+// DescribeStudioApp describes a Studio app as listed by ListStudioApps, with the KMS key and
+// network settings of its domain and the execution role of its user profile or space;
+// failing to describe them is reported as a warning
+func (c *clientImpl) DescribeStudioApp(ctx context.Context, app ResourceInfo) (Description, error) {
+	output, err := c.describeApp(ctx, app)
+	if err != nil {
+		return Description{}, err
+	}
+
+	d := Description{
+		ResourceInfo: ResourceInfo{
+			Name:          aws.ToString(output.AppName),
+			Arn:           aws.ToString(output.AppArn),
+			Status:        string(output.Status),
+			InstanceCount: 1,
+			CreationTime:  aws.ToTime(output.CreationTime),
+			UserProfile:   aws.ToString(output.UserProfileName),
+			AppType:       string(output.AppType),
+			SpaceName:     aws.ToString(output.SpaceName),
+			StudioType:    studioTypeOf(output.AppType),
+			DomainID:      aws.ToString(output.DomainId),
+		},
+		FailureReason:    aws.ToString(output.FailureReason),
+		LastHealthCheck:  aws.ToTime(output.LastHealthCheckTimestamp),
+		LastUserActivity: aws.ToTime(output.LastUserActivityTimestamp),
+	}
+	// Apps in a space are not always described with the user profile that listed them
+	if d.UserProfile == "" {
+		d.UserProfile = app.UserProfile
+	}
+	if spec := output.ResourceSpec; spec != nil {
+		d.InstanceType = string(spec.InstanceType)
+		d.Image = aws.ToString(spec.SageMakerImageArn)
+		d.LifecycleConfig = aws.ToString(spec.LifecycleConfigArn)
+	}
+	if d.LifecycleConfig == "" {
+		d.LifecycleConfig = aws.ToString(output.BuiltInLifecycleConfigArn)
+	}
+	d.addEvent(d.CreationTime, "Created")
+	d.addEvent(d.LastHealthCheck, "Last health check, status "+d.Status)
+	d.addEvent(d.LastUserActivity, "Last user activity")
+	d.sortTimeline()
+
+	c.describeDomain(ctx, &d)
+	return d, nil
+}
+
+// StudioAppArn returns the ARN of a Studio app as listed by ListStudioApps, which ListApps
+// doesn't return
+func (c *clientImpl) StudioAppArn(ctx context.Context, app ResourceInfo) (string, error) {
+	output, err := c.describeApp(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(output.AppArn), nil
+}
+
+// describeApp describes a Studio app, addressed by its space or else its user profile
+func (c *clientImpl) describeApp(ctx context.Context, app ResourceInfo) (*sagemaker.DescribeAppOutput, error) {
+	if app.DomainID == "" || app.AppType == "" || app.Name == "" {
+		return nil, fmt.Errorf("cannot describe Studio app %q: domain, app type and name are required", app.Name)
+	}
+	input := &sagemaker.DescribeAppInput{
+		DomainId: aws.String(app.DomainID),
+		AppType:  types.AppType(app.AppType),
+		AppName:  aws.String(app.Name),
+	}
+	if app.SpaceName != "" {
+		input.SpaceName = aws.String(app.SpaceName)
+	} else {
+		input.UserProfileName = aws.String(app.UserProfile)
+	}
+
+	var output *sagemaker.DescribeAppOutput
+	err := c.do(ctx, "DescribeApp", func(ctx context.Context) error {
+		var err error
+		output, err = c.client.DescribeApp(ctx, input)
+		return err
+	})
+	return output, err
+}
+
+// describeDomain adds the settings Studio apps inherit from their domain, then the execution
+// role and security groups of their user profile or space, which override the domain's
+func (c *clientImpl) describeDomain(ctx context.Context, d *Description) {
+	var domain *sagemaker.DescribeDomainOutput
+	err := c.do(ctx, "DescribeDomain", func(ctx context.Context) error {
+		var err error
+		domain, err = c.client.DescribeDomain(ctx, &sagemaker.DescribeDomainInput{DomainId: aws.String(d.DomainID)})
+		return err
+	})
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("failed to describe domain %s: %v", d.DomainID, err))
+	} else {
+		d.KmsKeyID = aws.ToString(domain.KmsKeyId)
+		d.Network.VpcID = aws.ToString(domain.VpcId)
+		d.Network.SubnetIDs = domain.SubnetIds
+		d.Network.AppNetworkAccess = string(domain.AppNetworkAccessType)
+		if settings := domain.DefaultUserSettings; settings != nil {
+			d.setExecution(settings.ExecutionRole, settings.SecurityGroups)
+		}
+	}
+
+	c.describeExecution(ctx, d, domain)
+}
+
+// describeExecution sets the execution role and security groups of a Studio app from its user
+// profile. Apps in a private space run with the settings of the space's owner, apps in a
+// shared space with the domain's default space settings.
+func (c *clientImpl) describeExecution(ctx context.Context, d *Description, domain *sagemaker.DescribeDomainOutput) {
+	profile := d.UserProfile
+	if d.SpaceName != "" {
+		var space *sagemaker.DescribeSpaceOutput
+		err := c.do(ctx, "DescribeSpace", func(ctx context.Context) error {
+			var err error
+			space, err = c.client.DescribeSpace(ctx, &sagemaker.DescribeSpaceInput{DomainId: aws.String(d.DomainID), SpaceName: aws.String(d.SpaceName)})
+			return err
+		})
+		if err != nil {
+			d.Warnings = append(d.Warnings, fmt.Sprintf("failed to describe space %s: %v", d.SpaceName, err))
+			return
+		}
+		if space.SpaceSharingSettings != nil && space.SpaceSharingSettings.SharingType == types.SharingTypeShared {
+			if domain != nil && domain.DefaultSpaceSettings != nil {
+				d.setExecution(domain.DefaultSpaceSettings.ExecutionRole, domain.DefaultSpaceSettings.SecurityGroups)
+			}
+			return
+		}
+		profile = ""
+		if space.OwnershipSettings != nil {
+			profile = aws.ToString(space.OwnershipSettings.OwnerUserProfileName)
+		}
+	}
+	if profile == "" {
+		return
+	}
+
+	var user *sagemaker.DescribeUserProfileOutput
+	err := c.do(ctx, "DescribeUserProfile", func(ctx context.Context) error {
+		var err error
+		user, err = c.client.DescribeUserProfile(ctx, &sagemaker.DescribeUserProfileInput{DomainId: aws.String(d.DomainID), UserProfileName: aws.String(profile)})
+		return err
+	})
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("failed to describe user profile %s: %v", profile, err))
+		return
+	}
+	if settings := user.UserSettings; settings != nil {
+		d.setExecution(settings.ExecutionRole, settings.SecurityGroups)
+	}
+}
+
+// setExecution sets the execution role and security groups that are set, keeping the
+// inherited ones otherwise
+func (d *Description) setExecution(role *string, securityGroups []string) {
+	if role := aws.ToString(role); role != "" {
+		d.RoleArn = role
+	}
+	if len(securityGroups) > 0 {
+		d.Network.SecurityGroupIDs = securityGroups
+	}
+}
+
+// addEvent adds an event to the timeline unless its time is unknown
+func (d *Description) addEvent(t time.Time, description string) {
+	if !t.IsZero() {
+		d.Timeline = append(d.Timeline, Event{Time: t, Description: description})
+	}
+}
+
+func (d *Description) sortTimeline() {
+	sort.SliceStable(d.Timeline, func(i, j int) bool {
+		return d.Timeline[i].Time.Before(d.Timeline[j].Time)
+	})
+}
+
+// serverlessText summarizes a serverless configuration, e.g. "2048 MB, max concurrency 5"
+func serverlessText(config *types.ProductionVariantServerlessConfig) string {
+	if config == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d MB, max concurrency %d", aws.ToInt32(config.MemorySizeInMB), aws.ToInt32(config.MaxConcurrency))
+}
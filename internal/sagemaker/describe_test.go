@@ -0,0 +1,295 @@
+package sagemaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDescribeEndpoint(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		config       *sagemaker.DescribeEndpointConfigOutput
+		configErr    error
+		model        *sagemaker.DescribeModelOutput
+		modelErr     error
+		wantRole     string
+		wantSubnets  []string
+		wantWarnings []string
+	}{
+		{
+			name: "role and network from the configuration",
+			config: &sagemaker.DescribeEndpointConfigOutput{
+				KmsKeyId:         aws.String("key-1"),
+				ExecutionRoleArn: aws.String("arn:role/config"),
+				VpcConfig:        &types.VpcConfig{Subnets: []string{"subnet-1"}, SecurityGroupIds: []string{"sg-1"}},
+				ProductionVariants: []types.ProductionVariant{
+					{VariantName: aws.String("AllTraffic"), ModelName: aws.String("model"), InstanceType: types.ProductionVariantInstanceTypeMlM5Large},
+				},
+			},
+			wantRole:    "arn:role/config",
+			wantSubnets: []string{"subnet-1"},
+		},
+		{
+			name: "role and network from the model",
+			config: &sagemaker.DescribeEndpointConfigOutput{
+				KmsKeyId: aws.String("key-1"),
+				ProductionVariants: []types.ProductionVariant{
+					{VariantName: aws.String("AllTraffic"), ModelName: aws.String("model"), InstanceType: types.ProductionVariantInstanceTypeMlM5Large},
+				},
+			},
+			model: &sagemaker.DescribeModelOutput{
+				ExecutionRoleArn: aws.String("arn:role/model"),
+				VpcConfig:        &types.VpcConfig{Subnets: []string{"subnet-2"}},
+			},
+			wantRole:    "arn:role/model",
+			wantSubnets: []string{"subnet-2"},
+		},
+		{
+			name: "deleted model",
+			config: &sagemaker.DescribeEndpointConfigOutput{
+				ProductionVariants: []types.ProductionVariant{
+					{VariantName: aws.String("AllTraffic"), ModelName: aws.String("model"), InstanceType: types.ProductionVariantInstanceTypeMlM5Large},
+				},
+			},
+			modelErr:     errors.New("Could not find model"),
+			wantWarnings: []string{"failed to describe model model: Could not find model"},
+		},
+		{
+			name:         "deleted configuration",
+			configErr:    errors.New("Could not find endpoint configuration"),
+			wantWarnings: []string{"failed to describe endpoint configuration prod-config: Could not find endpoint configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSageMakerClient)
+			mockClient.On("DescribeEndpoint", ctx, &sagemaker.DescribeEndpointInput{EndpointName: aws.String("prod")}, mock.Anything).
+				Return(&sagemaker.DescribeEndpointOutput{
+					EndpointName:       aws.String("prod"),
+					EndpointArn:        aws.String("arn:endpoint/prod"),
+					EndpointStatus:     types.EndpointStatusInService,
+					EndpointConfigName: aws.String("prod-config"),
+					CreationTime:       aws.Time(created),
+					LastModifiedTime:   aws.Time(created.Add(2 * time.Hour)),
+					ProductionVariants: []types.ProductionVariantSummary{{
+						VariantName:          aws.String("AllTraffic"),
+						CurrentInstanceCount: aws.Int32(2),
+						DesiredInstanceCount: aws.Int32(2),
+						CurrentWeight:        aws.Float32(1),
+						VariantStatus: []types.ProductionVariantStatus{
+							{Status: types.VariantStatusCreating, StartTime: aws.Time(created.Add(time.Minute)), StatusMessage: aws.String("pulling image")},
+						},
+					}},
+				}, nil)
+			if tt.config != nil || tt.configErr != nil {
+				mockClient.On("DescribeEndpointConfig", ctx, &sagemaker.DescribeEndpointConfigInput{EndpointConfigName: aws.String("prod-config")}, mock.Anything).
+					Return(tt.config, tt.configErr)
+			}
+			if tt.model != nil || tt.modelErr != nil {
+				mockClient.On("DescribeModel", ctx, &sagemaker.DescribeModelInput{ModelName: aws.String("model")}, mock.Anything).
+					Return(tt.model, tt.modelErr)
+			}
+
+			client := &clientImpl{client: mockClient}
+			d, err := client.DescribeEndpoint(ctx, "prod")
+			assert.NoError(t, err)
+			assert.Equal(t, "prod", d.Name)
+			assert.Equal(t, "InService", d.Status)
+			assert.Equal(t, 2, d.InstanceCount)
+			assert.Equal(t, tt.wantRole, d.RoleArn)
+			assert.Equal(t, tt.wantSubnets, d.Network.SubnetIDs)
+			assert.Equal(t, tt.wantWarnings, d.Warnings)
+			assert.Equal(t, []Event{
+				{Time: created, Description: "Created"},
+				{Time: created.Add(time.Minute), Description: "Variant AllTraffic: Creating (pulling image)"},
+				{Time: created.Add(2 * time.Hour), Description: "Last modified, status InService"},
+			}, d.Timeline)
+			if tt.config != nil {
+				assert.Equal(t, "ml.m5.large", d.InstanceType)
+				assert.Equal(t, []Variant{{Name: "AllTraffic", ModelName: "model", InstanceType: "ml.m5.large", CurrentInstanceCount: 2, DesiredInstanceCount: 2, Weight: 1}}, d.Variants)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDescribeEndpoint_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("DescribeEndpoint", ctx, mock.Anything, mock.Anything).Return(nil, errors.New("Could not find endpoint"))
+
+	client := &clientImpl{client: mockClient}
+	_, err := client.DescribeEndpoint(ctx, "missing")
+	assert.ErrorContains(t, err, "Could not find endpoint")
+}
+
+func TestDescribeNotebook(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("DescribeNotebookInstance", ctx, &sagemaker.DescribeNotebookInstanceInput{NotebookInstanceName: aws.String("dev")}, mock.Anything).
+		Return(&sagemaker.DescribeNotebookInstanceOutput{
+			NotebookInstanceName:                aws.String("dev"),
+			NotebookInstanceArn:                 aws.String("arn:notebook/dev"),
+			NotebookInstanceStatus:              types.NotebookInstanceStatusInService,
+			InstanceType:                        types.InstanceTypeMlT3Medium,
+			VolumeSizeInGB:                      aws.Int32(20),
+			RoleArn:                             aws.String("arn:role/notebook"),
+			KmsKeyId:                            aws.String("key-1"),
+			NotebookInstanceLifecycleConfigName: aws.String("install-extensions"),
+			SubnetId:                            aws.String("subnet-1"),
+			SecurityGroups:                      []string{"sg-1"},
+			DirectInternetAccess:                types.DirectInternetAccessDisabled,
+			RootAccess:                          types.RootAccessEnabled,
+			CreationTime:                        aws.Time(created),
+			LastModifiedTime:                    aws.Time(created.Add(time.Hour)),
+		}, nil)
+
+	client := &clientImpl{client: mockClient}
+	d, err := client.DescribeNotebook(ctx, "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, ResourceInfo{Name: "dev", Arn: "arn:notebook/dev", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, CreationTime: created, VolumeSize: 20}, d.ResourceInfo)
+	assert.Equal(t, "arn:role/notebook", d.RoleArn)
+	assert.Equal(t, "key-1", d.KmsKeyID)
+	assert.Equal(t, "install-extensions", d.LifecycleConfig)
+	assert.Equal(t, Network{SubnetIDs: []string{"subnet-1"}, SecurityGroupIDs: []string{"sg-1"}, DirectInternetAccess: "Disabled"}, d.Network)
+	assert.Equal(t, "Enabled", d.RootAccess)
+	assert.Len(t, d.Timeline, 2)
+}
+
+func TestDescribeStudioApp(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("DescribeApp", ctx, &sagemaker.DescribeAppInput{DomainId: aws.String("d-1"), SpaceName: aws.String("alice-space"), AppType: types.AppTypeJupyterLab, AppName: aws.String("default")}, mock.Anything).
+		Return(&sagemaker.DescribeAppOutput{
+			AppName:                   aws.String("default"),
+			AppArn:                    aws.String("arn:app/default"),
+			AppType:                   types.AppTypeJupyterLab,
+			DomainId:                  aws.String("d-1"),
+			SpaceName:                 aws.String("alice-space"),
+			Status:                    types.AppStatusInService,
+			CreationTime:              aws.Time(created),
+			LastUserActivityTimestamp: aws.Time(created.Add(3 * time.Hour)),
+			LastHealthCheckTimestamp:  aws.Time(created.Add(4 * time.Hour)),
+			ResourceSpec:              &types.ResourceSpec{InstanceType: types.AppInstanceTypeMlG5Xlarge, SageMakerImageArn: aws.String("arn:image/distribution")},
+		}, nil)
+	mockClient.On("DescribeDomain", ctx, &sagemaker.DescribeDomainInput{DomainId: aws.String("d-1")}, mock.Anything).
+		Return(&sagemaker.DescribeDomainOutput{
+			VpcId:                aws.String("vpc-1"),
+			SubnetIds:            []string{"subnet-1"},
+			AppNetworkAccessType: types.AppNetworkAccessTypeVpcOnly,
+			KmsKeyId:             aws.String("key-1"),
+			DefaultUserSettings:  &types.UserSettings{ExecutionRole: aws.String("arn:role/studio"), SecurityGroups: []string{"sg-1"}},
+		}, nil)
+	mockClient.On("DescribeSpace", ctx, &sagemaker.DescribeSpaceInput{DomainId: aws.String("d-1"), SpaceName: aws.String("alice-space")}, mock.Anything).
+		Return(&sagemaker.DescribeSpaceOutput{OwnershipSettings: &types.OwnershipSettings{OwnerUserProfileName: aws.String("alice")}}, nil)
+	mockClient.On("DescribeUserProfile", ctx, &sagemaker.DescribeUserProfileInput{DomainId: aws.String("d-1"), UserProfileName: aws.String("alice")}, mock.Anything).
+		Return(&sagemaker.DescribeUserProfileOutput{}, nil)
+
+	client := &clientImpl{client: mockClient}
+	d, err := client.DescribeStudioApp(ctx, ResourceInfo{Name: "default", DomainID: "d-1", UserProfile: "alice", SpaceName: "alice-space", AppType: "JupyterLab"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ml.g5.xlarge", d.InstanceType)
+	assert.Equal(t, "alice", d.UserProfile)
+	assert.Equal(t, "New Studio (JupyterLab)", d.StudioType)
+	assert.Equal(t, "arn:image/distribution", d.Image)
+	assert.Equal(t, "arn:role/studio", d.RoleArn)
+	assert.Equal(t, "key-1", d.KmsKeyID)
+	assert.Equal(t, Network{VpcID: "vpc-1", SubnetIDs: []string{"subnet-1"}, SecurityGroupIDs: []string{"sg-1"}, AppNetworkAccess: "VpcOnly"}, d.Network)
+	assert.Equal(t, []Event{
+		{Time: created, Description: "Created"},
+		{Time: created.Add(3 * time.Hour), Description: "Last user activity"},
+		{Time: created.Add(4 * time.Hour), Description: "Last health check, status InService"},
+	}, d.Timeline)
+	assert.Empty(t, d.Warnings)
+
+	// The domain is optional
+	mockClient = new(MockSageMakerClient)
+	mockClient.On("DescribeApp", ctx, mock.Anything, mock.Anything).Return(&sagemaker.DescribeAppOutput{AppName: aws.String("default"), DomainId: aws.String("d-1")}, nil)
+	mockClient.On("DescribeDomain", ctx, mock.Anything, mock.Anything).Return(nil, errors.New("AccessDenied"))
+	mockClient.On("DescribeUserProfile", ctx, mock.Anything, mock.Anything).Return(&sagemaker.DescribeUserProfileOutput{}, nil)
+	client = &clientImpl{client: mockClient}
+	d, err = client.DescribeStudioApp(ctx, ResourceInfo{Name: "default", DomainID: "d-1", UserProfile: "alice", AppType: "JupyterServer"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"failed to describe domain d-1: AccessDenied"}, d.Warnings)
+
+	_, err = client.DescribeStudioApp(ctx, ResourceInfo{Name: "default"})
+	assert.ErrorContains(t, err, "domain, app type and name are required")
+}
+
+func TestDescribeStudioAppExecution(t *testing.T) {
+	ctx := context.Background()
+	domain := &sagemaker.DescribeDomainOutput{
+		DefaultUserSettings:  &types.UserSettings{ExecutionRole: aws.String("arn:role/domain"), SecurityGroups: []string{"sg-domain"}},
+		DefaultSpaceSettings: &types.DefaultSpaceSettings{ExecutionRole: aws.String("arn:role/shared"), SecurityGroups: []string{"sg-shared"}},
+	}
+	private := &sagemaker.DescribeSpaceOutput{
+		OwnershipSettings:    &types.OwnershipSettings{OwnerUserProfileName: aws.String("bob")},
+		SpaceSharingSettings: &types.SpaceSharingSettings{SharingType: types.SharingTypePrivate},
+	}
+	shared := &sagemaker.DescribeSpaceOutput{SpaceSharingSettings: &types.SpaceSharingSettings{SharingType: types.SharingTypeShared}}
+	override := &sagemaker.DescribeUserProfileOutput{UserSettings: &types.UserSettings{ExecutionRole: aws.String("arn:role/user")}}
+
+	tests := []struct {
+		name         string
+		space        string
+		spaceOutput  *sagemaker.DescribeSpaceOutput
+		wantProfile  string
+		user         *sagemaker.DescribeUserProfileOutput
+		userErr      error
+		wantRole     string
+		wantGroups   []string
+		wantWarnings []string
+	}{
+		{name: "user profile overrides the role", wantProfile: "alice", user: override, wantRole: "arn:role/user", wantGroups: []string{"sg-domain"}},
+		{name: "user profile without settings", wantProfile: "alice", user: &sagemaker.DescribeUserProfileOutput{}, wantRole: "arn:role/domain", wantGroups: []string{"sg-domain"}},
+		{name: "private space runs as its owner", space: "bob-space", spaceOutput: private, wantProfile: "bob", user: override, wantRole: "arn:role/user", wantGroups: []string{"sg-domain"}},
+		{name: "shared space uses the space defaults", space: "team", spaceOutput: shared, wantRole: "arn:role/shared", wantGroups: []string{"sg-shared"}},
+		{
+			name:         "user profile not described",
+			wantProfile:  "alice",
+			userErr:      errors.New("AccessDenied"),
+			wantRole:     "arn:role/domain",
+			wantGroups:   []string{"sg-domain"},
+			wantWarnings: []string{"failed to describe user profile alice: AccessDenied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSageMakerClient)
+			mockClient.On("DescribeApp", ctx, mock.Anything, mock.Anything).
+				Return(&sagemaker.DescribeAppOutput{AppName: aws.String("default"), DomainId: aws.String("d-1"), SpaceName: aws.String(tt.space)}, nil)
+			mockClient.On("DescribeDomain", ctx, mock.Anything, mock.Anything).Return(domain, nil)
+			if tt.space != "" {
+				mockClient.On("DescribeSpace", ctx, &sagemaker.DescribeSpaceInput{DomainId: aws.String("d-1"), SpaceName: aws.String(tt.space)}, mock.Anything).Return(tt.spaceOutput, nil)
+			}
+			if tt.wantProfile != "" {
+				mockClient.On("DescribeUserProfile", ctx, &sagemaker.DescribeUserProfileInput{DomainId: aws.String("d-1"), UserProfileName: aws.String(tt.wantProfile)}, mock.Anything).Return(tt.user, tt.userErr)
+			}
+			client := &clientImpl{client: mockClient, clock: instantClock{}}
+
+			d, err := client.DescribeStudioApp(ctx, ResourceInfo{Name: "default", DomainID: "d-1", UserProfile: "alice", SpaceName: tt.space, AppType: "JupyterLab"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRole, d.RoleArn)
+			assert.Equal(t, tt.wantGroups, d.Network.SecurityGroupIDs)
+			assert.Equal(t, tt.wantWarnings, d.Warnings)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
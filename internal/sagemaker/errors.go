@@ -1,26 +1,45 @@
 package sagemaker
 
 import (
+	"context"
 	"errors"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsretry "github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // RetryableError represents an error that can be retried
 type RetryableError struct {
 	Err error
+	// After is the server-provided Retry-After hint, zero when none was sent
+	After time.Duration
 }
 
 func (e *RetryableError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
 func (e *RetryableError) IsRetryable() bool {
 	return true
 }
 
+// RetryAfter returns the minimum delay the server asked for before the next attempt
+func (e *RetryableError) RetryAfter() time.Duration {
+	return e.After
+}
+
 // NonRetryableError represents an error that should not be retried
 type NonRetryableError struct {
 	Err error
@@ -30,41 +49,196 @@ func (e *NonRetryableError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
 func (e *NonRetryableError) IsRetryable() bool {
 	return false
 }
 
+// errorCodes holds API error codes with an explicit classification. They take
+// precedence over the SDK's retryable and throttle detection and the error fault.
+var errorCodes = struct {
+	sync.RWMutex
+	retryable map[string]bool
+}{
+	retryable: map[string]bool{
+		// Retryable errors
+		"RequestTimeout":                         true,
+		"RequestTimeoutException":                true,
+		"ThrottlingException":                    true,
+		"Throttling":                             true,
+		"ThrottledException":                     true,
+		"TooManyRequestsException":               true,
+		"RequestLimitExceeded":                   true,
+		"RequestThrottled":                       true,
+		"RequestThrottledException":              true,
+		"ProvisionedThroughputExceededException": true,
+		"TransactionInProgressException":         true,
+		"ServiceUnavailable":                     true,
+		"ServiceUnavailableException":            true,
+		"InternalFailure":                        true,
+		"InternalServerError":                    true,
+		"InternalServerException":                true,
+
+		// Non-retryable errors
+		"ValidationError":             false,
+		"ValidationException":         false,
+		"AccessDeniedException":       false,
+		"InvalidParameterException":   false,
+		"InvalidParameterValue":       false,
+		"ResourceNotFound":            false,
+		"ResourceNotFoundException":   false,
+		"ResourceInUse":               false,
+		"ResourceLimitExceeded":       false,
+		"UnrecognizedClientException": false,
+		"InvalidClientTokenId":        false,
+		"SignatureDoesNotMatch":       false,
+		"IncompleteSignature":         false,
+		"MissingAuthenticationToken":  false,
+		"ExpiredToken":                false,
+		"ExpiredTokenException":       false,
+	},
+}
+
+// RegisterErrorCode adds or overrides the classification of an API error code
+func RegisterErrorCode(code string, retryable bool) {
+	errorCodes.Lock()
+	defer errorCodes.Unlock()
+	errorCodes.retryable[code] = retryable
+}
+
+func lookupErrorCode(code string) (retryable, known bool) {
+	errorCodes.RLock()
+	defer errorCodes.RUnlock()
+	retryable, known = errorCodes.retryable[code]
+	return retryable, known
+}
+
 // WrapError wraps AWS errors and determines if they are retryable
 func WrapError(err error) error {
 	if err == nil {
 		return nil
 	}
 
+	// Already classified
+	if _, ok := err.(interface{ IsRetryable() bool }); ok {
+		return err
+	}
+
+	if isRetryable(err) {
+		return &RetryableError{Err: err, After: retryAfter(err)}
+	}
+	return &NonRetryableError{Err: err}
+}
+
+// isRetryable classifies an error, from the most to the least specific signal
+func isRetryable(err error) bool {
+	// Cancellations and deadlines are decided by the caller's context, not by retrying
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
 	var ae smithy.APIError
-	if errors.As(err, &ae) {
-		switch ae.ErrorCode() {
-		// Retryable errors
-		case "RequestTimeout", 
-			 "ThrottlingException", 
-			 "ProvisionedThroughputExceededException", 
-			 "TransactionInProgressException":
-			return &RetryableError{Err: err}
+	hasAPIError := errors.As(err, &ae)
+	if hasAPIError {
+		if retryable, known := lookupErrorCode(ae.ErrorCode()); known {
+			return retryable
+		}
+	}
 
-		// Non-retryable errors
-		case "ValidationError", 
-			 "AccessDeniedException", 
-			 "InvalidParameterException":
-			return &NonRetryableError{Err: err}
+	if awsretry.IsErrorThrottles(awsretry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		return true
+	}
+	switch awsretry.IsErrorRetryables(awsretry.DefaultRetryables).IsErrorRetryable(err) {
+	case aws.TrueTernary:
+		return true
+	case aws.FalseTernary:
+		return false
+	}
+
+	if status := httpStatusCode(err); status != 0 {
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	}
+
+	if hasAPIError {
+		switch ae.ErrorFault() {
+		case smithy.FaultServer:
+			return true
+		case smithy.FaultClient:
+			return false
 		}
 	}
 
-	// Network errors or context cancellations are typically retryable
-	if isNetworkError(err) {
-		return &RetryableError{Err: err}
+	// Network errors are typically retryable
+	return isNetworkError(err)
+}
+
+// httpStatusCode returns the HTTP status code of the response that caused err, or 0
+func httpStatusCode(err error) int {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+// retryAfter returns the delay requested by the server's Retry-After header, or 0
+func retryAfter(err error) time.Duration {
+	var respErr *smithyhttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return 0
 	}
 
-	// Default to non-retryable for unknown errors
-	return &NonRetryableError{Err: err}
+	header := respErr.Response.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// isDeleted reports whether err says the resource is gone or already being deleted. SageMaker
+// reports missing resources as ValidationException, e.g. "Could not find endpoint", and
+// missing Studio apps as ResourceNotFound. Refused transitions name the current status
+// first, e.g. "Status (InService) not in ([Stopped, Failed]). Unable to transition to
+// (Deleting)", so only the current status counts.
+func isDeleted(err error) bool {
+	return apiErrorMentions(err, "resourcenotfound", "recordnotfound", "could not find", "not found", "does not exist", "status (deleting)")
+}
+
+// isStopping reports whether err says the notebook instance is already stopping or stopped
+func isStopping(err error) bool {
+	return apiErrorMentions(err, "status (stopping)", "status (stopped)")
+}
+
+// apiErrorMentions reports whether err is a not-found, validation or in-use API error whose
+// code or message contains one of the lowercase phrases
+func apiErrorMentions(err error, phrases ...string) bool {
+	var ae smithy.APIError
+	if !errors.As(err, &ae) {
+		return false
+	}
+	switch ae.ErrorCode() {
+	case "ValidationException", "ValidationError", "ResourceNotFound", "ResourceNotFoundException", "ResourceInUse":
+	default:
+		return false
+	}
+	text := strings.ToLower(ae.ErrorCode() + " " + ae.ErrorMessage())
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
 }
 
 // isNetworkError checks if the error is a network-related error
@@ -84,13 +258,12 @@ func isNetworkError(err error) bool {
 		"connection refused",
 		"connection reset",
 		"network is unreachable",
-		"timeout",
 		"i/o timeout",
 	}
 
-	errStr := err.Error()
+	errStr := strings.ToLower(err.Error())
 	for _, msg := range networkErrorMessages {
-		if strings.Contains(strings.ToLower(errStr), msg) {
+		if strings.Contains(errStr, msg) {
 			return true
 		}
 	}
@@ -1,13 +1,18 @@
 package sagemaker
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"syscall"
 	"testing"
+	"time"
 
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -113,3 +118,162 @@ func TestIsNetworkError(t *testing.T) {
 		})
 	}
 }
+
+// responseError builds an SDK-style HTTP response error with the given status and headers
+func responseError(status int, header http.Header, err error) error {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status, Header: header}},
+			Err:      err,
+		},
+		RequestID: "req-1",
+	}
+}
+
+func TestWrapError_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		code      string
+		retryable bool
+	}{
+		{code: "RequestTimeout", retryable: true},
+		{code: "RequestTimeoutException", retryable: true},
+		{code: "ThrottlingException", retryable: true},
+		{code: "Throttling", retryable: true},
+		{code: "ThrottledException", retryable: true},
+		{code: "TooManyRequestsException", retryable: true},
+		{code: "RequestLimitExceeded", retryable: true},
+		{code: "RequestThrottled", retryable: true},
+		{code: "RequestThrottledException", retryable: true},
+		{code: "ProvisionedThroughputExceededException", retryable: true},
+		{code: "TransactionInProgressException", retryable: true},
+		{code: "ServiceUnavailable", retryable: true},
+		{code: "ServiceUnavailableException", retryable: true},
+		{code: "InternalFailure", retryable: true},
+		{code: "InternalServerError", retryable: true},
+		{code: "InternalServerException", retryable: true},
+		{code: "ValidationError", retryable: false},
+		{code: "ValidationException", retryable: false},
+		{code: "AccessDeniedException", retryable: false},
+		{code: "InvalidParameterException", retryable: false},
+		{code: "InvalidParameterValue", retryable: false},
+		{code: "ResourceNotFound", retryable: false},
+		{code: "ResourceNotFoundException", retryable: false},
+		{code: "ResourceInUse", retryable: false},
+		{code: "ResourceLimitExceeded", retryable: false},
+		{code: "UnrecognizedClientException", retryable: false},
+		{code: "InvalidClientTokenId", retryable: false},
+		{code: "SignatureDoesNotMatch", retryable: false},
+		{code: "IncompleteSignature", retryable: false},
+		{code: "MissingAuthenticationToken", retryable: false},
+		{code: "ExpiredToken", retryable: false},
+		{code: "ExpiredTokenException", retryable: false},
+		// Codes only known to the SDK's throttle detection
+		{code: "SlowDown", retryable: true},
+		{code: "BandwidthLimitExceeded", retryable: true},
+		{code: "EC2ThrottledException", retryable: true},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		covered[tt.code] = true
+		t.Run(tt.code, func(t *testing.T) {
+			// A client fault must not override the code classification, and vice versa
+			fault := smithy.FaultClient
+			if !tt.retryable {
+				fault = smithy.FaultServer
+			}
+			wrapped := WrapError(&smithy.GenericAPIError{Code: tt.code, Fault: fault})
+
+			var classified interface{ IsRetryable() bool }
+			assert.True(t, errors.As(wrapped, &classified))
+			assert.Equal(t, tt.retryable, classified.IsRetryable())
+		})
+	}
+
+	errorCodes.RLock()
+	defer errorCodes.RUnlock()
+	for code := range errorCodes.retryable {
+		assert.True(t, covered[code], "error code %s is not covered by the table", code)
+	}
+}
+
+func TestWrapError_Classification(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "server fault", err: &smithy.GenericAPIError{Code: "SomethingBroke", Fault: smithy.FaultServer}, retryable: true},
+		{name: "client fault", err: &smithy.GenericAPIError{Code: "BadInput", Fault: smithy.FaultClient}, retryable: false},
+		{name: "unknown fault", err: &smithy.GenericAPIError{Code: "Mystery"}, retryable: false},
+		{name: "HTTP 500", err: responseError(500, nil, errors.New("boom")), retryable: true},
+		{name: "HTTP 503 with unknown code", err: responseError(503, nil, &smithy.GenericAPIError{Code: "Mystery"}), retryable: true},
+		{name: "HTTP 429", err: responseError(429, nil, errors.New("slow down")), retryable: true},
+		{name: "HTTP 400", err: responseError(400, nil, errors.New("bad request")), retryable: false},
+		{name: "HTTP 404 with explicit code", err: responseError(404, nil, &smithy.GenericAPIError{Code: "ResourceNotFound"}), retryable: false},
+		{name: "context canceled", err: fmt.Errorf("request failed: %w", context.Canceled), retryable: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, retryable: false},
+		{name: "timeout word in message", err: errors.New("invalid timeout parameter"), retryable: false},
+		{name: "i/o timeout", err: errors.New("read tcp: i/o timeout"), retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := WrapError(tt.err)
+			var classified interface{ IsRetryable() bool }
+			assert.True(t, errors.As(wrapped, &classified))
+			assert.Equal(t, tt.retryable, classified.IsRetryable())
+			assert.ErrorIs(t, wrapped, tt.err)
+		})
+	}
+}
+
+func TestWrapError_AlreadyClassified(t *testing.T) {
+	original := &NonRetryableError{Err: errors.New("done")}
+	assert.Same(t, original, WrapError(original))
+	assert.Nil(t, WrapError(nil))
+}
+
+func TestRegisterErrorCode(t *testing.T) {
+	code := "CustomTransientException"
+	err := &smithy.GenericAPIError{Code: code, Fault: smithy.FaultClient}
+	assert.IsType(t, &NonRetryableError{}, WrapError(err))
+
+	RegisterErrorCode(code, true)
+	defer func() {
+		errorCodes.Lock()
+		delete(errorCodes.retryable, code)
+		errorCodes.Unlock()
+	}()
+	assert.IsType(t, &RetryableError{}, WrapError(err))
+}
+
+func TestWrapError_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected time.Duration
+	}{
+		{name: "seconds", header: "5", expected: 5 * time.Second},
+		{name: "no header", header: "", expected: 0},
+		{name: "invalid header", header: "soon", expected: 0},
+		{name: "past date", header: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("Retry-After", tt.header)
+			}
+			wrapped := WrapError(responseError(429, header, &smithy.GenericAPIError{Code: "ThrottlingException"}))
+
+			retryable, ok := wrapped.(*RetryableError)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, retryable.RetryAfter())
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package sagemaker
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+)
+
+// regexPrefix marks a name pattern as a regular expression rather than a glob
+const regexPrefix = "re:"
+
+// Filter narrows down the resources returned by the List methods.
+// The zero value keeps the default behavior of listing only InService resources.
+type Filter struct {
+	// Statuses restricts results to the given statuses (case-insensitive); empty means InService only
+	Statuses []string
+	// AllStatuses disables status filtering entirely
+	AllStatuses bool
+	// Name matches resource names as a glob, or as a regular expression when prefixed with "re:"
+	Name string
+	// InstanceType matches instance types as a glob, e.g. "ml.p*"
+	InstanceType string
+	// CreatedBefore and CreatedAfter bound the creation time; zero values are ignored
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	// UserProfile and DomainID restrict results to Studio apps owned by the given profile or domain
+	UserProfile string
+	DomainID    string
+}
+
+// Validate checks that the name and instance type patterns are well-formed
+func (f Filter) Validate() error {
+	_, err := f.compile(nil, true)
+	return err
+}
+
+// matcher is a Filter compiled for one resource type
+type matcher struct {
+	statuses statusFilter
+	// excluded is true when the filter cannot match any resource of this type
+	excluded     bool
+	name         func(string) bool
+	instanceType string
+	filter       Filter
+}
+
+// compile prepares the filter for a resource type with the given valid statuses.
+// studio indicates whether the resource type is owned by a user profile and domain.
+func (f Filter) compile(validStatuses []string, studio bool) (*matcher, error) {
+	m := &matcher{
+		statuses:     f.resolveStatuses(validStatuses),
+		instanceType: f.InstanceType,
+		filter:       f,
+	}
+	m.excluded = !studio && (f.UserProfile != "" || f.DomainID != "")
+
+	if f.InstanceType != "" {
+		if _, err := path.Match(f.InstanceType, ""); err != nil {
+			return nil, fmt.Errorf("invalid instance type pattern %q: %w", f.InstanceType, err)
+		}
+	}
+
+	switch {
+	case f.Name == "":
+	case strings.HasPrefix(f.Name, regexPrefix):
+		re, err := regexp.Compile(strings.TrimPrefix(f.Name, regexPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", f.Name, err)
+		}
+		m.name = re.MatchString
+	default:
+		if _, err := path.Match(f.Name, ""); err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", f.Name, err)
+		}
+		m.name = func(name string) bool {
+			matched, _ := path.Match(f.Name, name)
+			return matched
+		}
+	}
+
+	return m, nil
+}
+
+// empty reports whether no resource of this type can match, so the API call can be skipped
+func (m *matcher) empty() bool {
+	return m.excluded || m.statuses.empty()
+}
+
+// nameContains returns a literal substring every matching name must contain, for the
+// NameContains API parameter, or nil when the pattern has no usable literal part
+func (m *matcher) nameContains() *string {
+	if m.filter.Name == "" || strings.HasPrefix(m.filter.Name, regexPrefix) {
+		return nil
+	}
+	literal := longestLiteral(m.filter.Name)
+	if literal == "" {
+		return nil
+	}
+	return &literal
+}
+
+// createdBefore and createdAfter return the creation time bounds for the API, or nil when unset
+func (m *matcher) createdBefore() *time.Time {
+	return optionalTime(m.filter.CreatedBefore)
+}
+
+func (m *matcher) createdAfter() *time.Time {
+	return optionalTime(m.filter.CreatedAfter)
+}
+
+// withoutInstanceType returns a copy of the matcher that accepts any instance type, for
+// resources whose instances are only known once described
+func (m *matcher) withoutInstanceType() *matcher {
+	untyped := *m
+	untyped.instanceType = ""
+	return &untyped
+}
+
+// matches applies every criterion client-side, including those already sent to the API
+func (m *matcher) matches(r ResourceInfo) bool {
+	if !m.statuses.matches(r.Status) {
+		return false
+	}
+	if m.name != nil && !m.name(r.Name) {
+		return false
+	}
+	if m.instanceType != "" {
+		if matched, _ := path.Match(m.instanceType, r.InstanceType); !matched {
+			return false
+		}
+	}
+	// A resource of unknown age is neither older nor newer than a cutoff
+	if !m.filter.CreatedBefore.IsZero() && (r.CreationTime.IsZero() || !r.CreationTime.Before(m.filter.CreatedBefore)) {
+		return false
+	}
+	if !m.filter.CreatedAfter.IsZero() && (r.CreationTime.IsZero() || !r.CreationTime.After(m.filter.CreatedAfter)) {
+		return false
+	}
+	if m.filter.UserProfile != "" && r.UserProfile != m.filter.UserProfile {
+		return false
+	}
+	if m.filter.DomainID != "" && r.DomainID != m.filter.DomainID {
+		return false
+	}
+	return true
+}
+
+// longestLiteral returns the longest run of non-wildcard characters in a glob pattern
+func longestLiteral(glob string) string {
+	var longest, current strings.Builder
+	flush := func() {
+		if current.Len() > longest.Len() {
+			longest.Reset()
+			longest.WriteString(current.String())
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*', '?':
+			flush()
+		case '[':
+			flush()
+			// Skip the character class
+			for i < len(glob) && glob[i] != ']' {
+				i++
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				current.WriteByte(glob[i])
+			}
+		default:
+			current.WriteByte(glob[i])
+		}
+	}
+	flush()
+
+	return longest.String()
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// statusFilter is the status filter resolved against the valid statuses of one resource type
+type statusFilter struct {
+	// all is true when no status filtering should be applied
+	all bool
+	// statuses holds the requested statuses that are valid for the resource type, in API casing
+	statuses []string
+}
+
+// resolveStatuses matches the requested statuses against the valid values for a resource type
+func (f Filter) resolveStatuses(valid []string) statusFilter {
+	if f.AllStatuses {
+		return statusFilter{all: true}
+	}
+
+	requested := f.Statuses
+	if len(requested) == 0 {
+		requested = []string{string(types.EndpointStatusInService)}
+	}
+
+	var resolved statusFilter
+	for _, status := range requested {
+		for _, v := range valid {
+			if strings.EqualFold(status, v) && !resolved.contains(v) {
+				resolved.statuses = append(resolved.statuses, v)
+			}
+		}
+	}
+	return resolved
+}
+
+// empty reports whether no resource of this type can match the filter
+func (s statusFilter) empty() bool {
+	return !s.all && len(s.statuses) == 0
+}
+
+// serverSide returns the status to send as StatusEquals, or an empty string when the
+// filter has to be applied client-side because zero or several statuses were requested
+func (s statusFilter) serverSide() string {
+	if s.all || len(s.statuses) != 1 {
+		return ""
+	}
+	return s.statuses[0]
+}
+
+// matches reports whether a resource with the given status passes the filter
+func (s statusFilter) matches(status string) bool {
+	return s.all || s.contains(status)
+}
+
+func (s statusFilter) contains(status string) bool {
+	for _, v := range s.statuses {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
+func endpointStatuses() []string {
+	return enumStrings(types.EndpointStatus("").Values())
+}
+
+func notebookStatuses() []string {
+	return enumStrings(types.NotebookInstanceStatus("").Values())
+}
+
+func appStatuses() []string {
+	return enumStrings(types.AppStatus("").Values())
+}
+
+func enumStrings[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
@@ -0,0 +1,274 @@
+package sagemaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFilterResolveStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		filter     Filter
+		valid      []string
+		empty      bool
+		serverSide string
+		matches    map[string]bool
+	}{
+		{
+			name:       "default is InService",
+			filter:     Filter{},
+			valid:      endpointStatuses(),
+			serverSide: "InService",
+			matches:    map[string]bool{"InService": true, "Failed": false},
+		},
+		{
+			name:       "case-insensitive single status",
+			filter:     Filter{Statuses: []string{"failed"}},
+			valid:      endpointStatuses(),
+			serverSide: "Failed",
+			matches:    map[string]bool{"Failed": true, "InService": false},
+		},
+		{
+			name:       "multiple statuses are matched client-side",
+			filter:     Filter{Statuses: []string{"Pending", "Stopped"}},
+			valid:      notebookStatuses(),
+			serverSide: "",
+			matches:    map[string]bool{"Pending": true, "Stopped": true, "InService": false},
+		},
+		{
+			name:       "statuses only partially valid for the type",
+			filter:     Filter{Statuses: []string{"Stopped", "Failed"}},
+			valid:      endpointStatuses(),
+			serverSide: "Failed",
+			matches:    map[string]bool{"Failed": true},
+		},
+		{
+			name:   "no valid statuses for the type",
+			filter: Filter{Statuses: []string{"Stopped"}},
+			valid:  appStatuses(),
+			empty:  true,
+		},
+		{
+			name:       "all statuses",
+			filter:     Filter{AllStatuses: true},
+			valid:      appStatuses(),
+			serverSide: "",
+			matches:    map[string]bool{"Deleted": true, "InService": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := tt.filter.resolveStatuses(tt.valid)
+			assert.Equal(t, tt.empty, resolved.empty())
+			assert.Equal(t, tt.serverSide, resolved.serverSide())
+			for status, expected := range tt.matches {
+				assert.Equal(t, expected, resolved.matches(status), status)
+			}
+		})
+	}
+}
+
+func TestListNotebooks_StatusFilter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListNotebookInstances", ctx, &sagemaker.ListNotebookInstancesInput{MaxResults: aws.Int32(listPageSize)}, mock.Anything).
+		Return(&sagemaker.ListNotebookInstancesOutput{
+			NotebookInstances: []types.NotebookInstanceSummary{
+				{NotebookInstanceName: aws.String("running"), NotebookInstanceStatus: types.NotebookInstanceStatusInService, CreationTime: aws.Time(now)},
+				{NotebookInstanceName: aws.String("stopped"), NotebookInstanceStatus: types.NotebookInstanceStatusStopped, CreationTime: aws.Time(now)},
+				{NotebookInstanceName: aws.String("failed"), NotebookInstanceStatus: types.NotebookInstanceStatusFailed, CreationTime: aws.Time(now)},
+			},
+		}, nil)
+
+	client := &clientImpl{client: mockClient}
+
+	resources, err := client.ListNotebooks(ctx, Filter{Statuses: []string{"Stopped", "Failed"}})
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+	assert.Equal(t, "stopped", resources[0].Name)
+	assert.Equal(t, "failed", resources[1].Name)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListStudioApps_NoMatchingStatusSkipsCall(t *testing.T) {
+	mockClient := new(MockSageMakerClient)
+	client := &clientImpl{client: mockClient}
+
+	resources, err := client.ListStudioApps(context.Background(), Filter{Statuses: []string{"Stopped"}})
+	assert.NoError(t, err)
+	assert.Empty(t, resources)
+	mockClient.AssertNotCalled(t, "ListApps", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLongestLiteral(t *testing.T) {
+	tests := map[string]string{
+		"exp-*":         "exp-",
+		"*-prod-*-v2":   "-prod-",
+		"ml.p*":         "ml.p",
+		"*":             "",
+		"a[0-9]bcd?e":   "bcd",
+		`literal\*star`: "literal*star",
+	}
+	for glob, expected := range tests {
+		assert.Equal(t, expected, longestLiteral(glob), glob)
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	assert.NoError(t, Filter{Name: "exp-*", InstanceType: "ml.p*"}.Validate())
+	assert.NoError(t, Filter{Name: "re:^exp-[0-9]+$"}.Validate())
+	assert.Error(t, Filter{Name: "re:("}.Validate())
+	assert.Error(t, Filter{Name: "exp-["}.Validate())
+	assert.Error(t, Filter{InstanceType: "ml.[p"}.Validate())
+}
+
+func TestMatcherMatches(t *testing.T) {
+	now := time.Now()
+	resource := ResourceInfo{
+		Name:         "exp-42",
+		Status:       "InService",
+		InstanceType: "ml.p3.2xlarge",
+		CreationTime: now.Add(-96 * time.Hour),
+		UserProfile:  "alice",
+		DomainID:     "d-123",
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{name: "zero filter", filter: Filter{}, expected: true},
+		{name: "name glob", filter: Filter{Name: "exp-*"}, expected: true},
+		{name: "name glob mismatch", filter: Filter{Name: "prod-*"}, expected: false},
+		{name: "name regex", filter: Filter{Name: "re:^exp-\\d+$"}, expected: true},
+		{name: "instance type glob", filter: Filter{InstanceType: "ml.p*"}, expected: true},
+		{name: "instance type mismatch", filter: Filter{InstanceType: "ml.g*"}, expected: false},
+		{name: "older than", filter: Filter{CreatedBefore: now.Add(-72 * time.Hour)}, expected: true},
+		{name: "newer than", filter: Filter{CreatedAfter: now.Add(-72 * time.Hour)}, expected: false},
+		{name: "user profile", filter: Filter{UserProfile: "alice"}, expected: true},
+		{name: "other user profile", filter: Filter{UserProfile: "bob"}, expected: false},
+		{name: "other domain", filter: Filter{DomainID: "d-456"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.filter.compile(appStatuses(), true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m.matches(resource))
+		})
+	}
+}
+
+func TestMatcherMatches_UnknownCreationTime(t *testing.T) {
+	now := time.Now()
+	resource := ResourceInfo{Name: "app", Status: "InService"}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{name: "no age bound", filter: Filter{}, expected: true},
+		{name: "older than", filter: Filter{CreatedBefore: now.Add(-72 * time.Hour)}, expected: false},
+		{name: "newer than", filter: Filter{CreatedAfter: now.Add(-72 * time.Hour)}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.filter.compile(appStatuses(), true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m.matches(resource))
+		})
+	}
+}
+
+func TestListEndpoints_ServerSideFilters(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	before := now.Add(-72 * time.Hour)
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListEndpoints", ctx, &sagemaker.ListEndpointsInput{
+		MaxResults:         aws.Int32(listPageSize),
+		StatusEquals:       types.EndpointStatusInService,
+		NameContains:       aws.String("exp-"),
+		CreationTimeBefore: aws.Time(before),
+	}, mock.Anything).
+		Return(&sagemaker.ListEndpointsOutput{
+			Endpoints: []types.EndpointSummary{
+				{EndpointName: aws.String("exp-1"), EndpointStatus: types.EndpointStatusInService, CreationTime: aws.Time(before.Add(-time.Hour))},
+				// NameContains is a substring match, so the glob is re-applied client-side
+				{EndpointName: aws.String("old-exp-2"), EndpointStatus: types.EndpointStatusInService, CreationTime: aws.Time(before.Add(-time.Hour))},
+			},
+			NextToken: aws.String("page2"),
+		}, nil)
+	mockClient.On("ListEndpoints", ctx, &sagemaker.ListEndpointsInput{
+		MaxResults:         aws.Int32(listPageSize),
+		StatusEquals:       types.EndpointStatusInService,
+		NameContains:       aws.String("exp-"),
+		CreationTimeBefore: aws.Time(before),
+		NextToken:          aws.String("page2"),
+	}, mock.Anything).
+		Return(&sagemaker.ListEndpointsOutput{
+			Endpoints: []types.EndpointSummary{
+				{EndpointName: aws.String("exp-3"), EndpointStatus: types.EndpointStatusInService, CreationTime: aws.Time(before.Add(-time.Hour))},
+			},
+		}, nil)
+
+	// Only endpoints matching the name are described
+	mockClient.onEndpointInstances("exp-1", "ml.m5.large", 1)
+	mockClient.onEndpointInstances("exp-3", "ml.m5.large", 1)
+
+	client := &clientImpl{client: mockClient}
+
+	resources, err := client.ListEndpoints(ctx, Filter{Name: "exp-*", CreatedBefore: before})
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+	assert.Equal(t, "exp-1", resources[0].Name)
+	assert.Equal(t, "exp-3", resources[1].Name)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListStudioApps_OwnerFilters(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListApps", ctx, &sagemaker.ListAppsInput{
+		MaxResults:            aws.Int32(listPageSize),
+		UserProfileNameEquals: aws.String("alice"),
+		DomainIdEquals:        aws.String("d-123"),
+	}, mock.Anything).
+		Return(&sagemaker.ListAppsOutput{
+			Apps: []types.AppDetails{
+				{AppName: aws.String("default"), Status: types.AppStatusInService, UserProfileName: aws.String("alice"), DomainId: aws.String("d-123")},
+			},
+		}, nil)
+
+	client := &clientImpl{client: mockClient}
+	filter := Filter{UserProfile: "alice", DomainID: "d-123"}
+
+	apps, err := client.ListStudioApps(ctx, filter)
+	assert.NoError(t, err)
+	assert.Len(t, apps, 1)
+
+	// Endpoints and notebooks are not owned by user profiles, so no calls are made
+	endpoints, err := client.ListEndpoints(ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, endpoints)
+	notebooks, err := client.ListNotebooks(ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, notebooks)
+
+	mockClient.AssertExpectations(t)
+}
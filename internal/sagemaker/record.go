@@ -0,0 +1,369 @@
+package sagemaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/smithy-go"
+)
+
+// recordedCall is one SDK request and its outcome, stored as <seq>-<Operation>.json
+type recordedCall struct {
+	Operation string          `json:"operation"`
+	Region    string          `json:"region"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     *recordedError  `json:"error,omitempty"`
+}
+
+// recordedError keeps what error classification needs: the API error code and HTTP status
+type recordedError struct {
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+	RetryAfter string `json:"retryAfter,omitempty"`
+}
+
+// recordingPattern matches the files written by recorder
+const recordingPattern = "*-*.json"
+
+// recorder wraps the SDK client and writes every request and response to a directory
+type recorder struct {
+	client     SageMakerClientInterface
+	dir        string
+	region     string
+	anonymizer *anonymizer
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecorder creates dir if needed; it refuses a directory that already holds a recording,
+// since replaying two mixed recordings gives confusing results
+func newRecorder(client SageMakerClientInterface, dir, region string, anonymize bool) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, recordingPattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("recording directory %s already contains a recording", dir)
+	}
+
+	r := &recorder{client: client, dir: dir, region: region}
+	if anonymize {
+		r.anonymizer = newAnonymizer()
+	}
+	return r, nil
+}
+
+func (r *recorder) ListApps(ctx context.Context, params *sagemaker.ListAppsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListAppsOutput, error) {
+	output, err := r.client.ListApps(ctx, params, optFns...)
+	return output, r.record(ctx, "ListApps", params, output, err)
+}
+
+func (r *recorder) ListEndpoints(ctx context.Context, params *sagemaker.ListEndpointsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListEndpointsOutput, error) {
+	output, err := r.client.ListEndpoints(ctx, params, optFns...)
+	return output, r.record(ctx, "ListEndpoints", params, output, err)
+}
+
+func (r *recorder) ListNotebookInstances(ctx context.Context, params *sagemaker.ListNotebookInstancesInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListNotebookInstancesOutput, error) {
+	output, err := r.client.ListNotebookInstances(ctx, params, optFns...)
+	return output, r.record(ctx, "ListNotebookInstances", params, output, err)
+}
+
+func (r *recorder) ListDomains(ctx context.Context, params *sagemaker.ListDomainsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListDomainsOutput, error) {
+	output, err := r.client.ListDomains(ctx, params, optFns...)
+	return output, r.record(ctx, "ListDomains", params, output, err)
+}
+
+func (r *recorder) ListTags(ctx context.Context, params *sagemaker.ListTagsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListTagsOutput, error) {
+	output, err := r.client.ListTags(ctx, params, optFns...)
+	return output, r.record(ctx, "ListTags", params, output, err)
+}
+
+func (r *recorder) StopNotebookInstance(ctx context.Context, params *sagemaker.StopNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.StopNotebookInstanceOutput, error) {
+	output, err := r.client.StopNotebookInstance(ctx, params, optFns...)
+	return output, r.record(ctx, "StopNotebookInstance", params, output, err)
+}
+
+func (r *recorder) DeleteNotebookInstance(ctx context.Context, params *sagemaker.DeleteNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteNotebookInstanceOutput, error) {
+	output, err := r.client.DeleteNotebookInstance(ctx, params, optFns...)
+	return output, r.record(ctx, "DeleteNotebookInstance", params, output, err)
+}
+
+func (r *recorder) DeleteEndpoint(ctx context.Context, params *sagemaker.DeleteEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteEndpointOutput, error) {
+	output, err := r.client.DeleteEndpoint(ctx, params, optFns...)
+	return output, r.record(ctx, "DeleteEndpoint", params, output, err)
+}
+
+func (r *recorder) DeleteApp(ctx context.Context, params *sagemaker.DeleteAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteAppOutput, error) {
+	output, err := r.client.DeleteApp(ctx, params, optFns...)
+	return output, r.record(ctx, "DeleteApp", params, output, err)
+}
+
+func (r *recorder) DescribeEndpoint(ctx context.Context, params *sagemaker.DescribeEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointOutput, error) {
+	output, err := r.client.DescribeEndpoint(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeEndpoint", params, output, err)
+}
+
+func (r *recorder) DescribeEndpointConfig(ctx context.Context, params *sagemaker.DescribeEndpointConfigInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointConfigOutput, error) {
+	output, err := r.client.DescribeEndpointConfig(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeEndpointConfig", params, output, err)
+}
+
+func (r *recorder) DescribeModel(ctx context.Context, params *sagemaker.DescribeModelInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeModelOutput, error) {
+	output, err := r.client.DescribeModel(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeModel", params, output, err)
+}
+
+func (r *recorder) DescribeNotebookInstance(ctx context.Context, params *sagemaker.DescribeNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error) {
+	output, err := r.client.DescribeNotebookInstance(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeNotebookInstance", params, output, err)
+}
+
+func (r *recorder) DescribeApp(ctx context.Context, params *sagemaker.DescribeAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeAppOutput, error) {
+	output, err := r.client.DescribeApp(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeApp", params, output, err)
+}
+
+func (r *recorder) DescribeDomain(ctx context.Context, params *sagemaker.DescribeDomainInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error) {
+	output, err := r.client.DescribeDomain(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeDomain", params, output, err)
+}
+
+func (r *recorder) DescribeUserProfile(ctx context.Context, params *sagemaker.DescribeUserProfileInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeUserProfileOutput, error) {
+	output, err := r.client.DescribeUserProfile(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeUserProfile", params, output, err)
+}
+
+func (r *recorder) DescribeSpace(ctx context.Context, params *sagemaker.DescribeSpaceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeSpaceOutput, error) {
+	output, err := r.client.DescribeSpace(ctx, params, optFns...)
+	return output, r.record(ctx, "DescribeSpace", params, output, err)
+}
+
+// record writes one call to disk and returns the call's own error. A call cut short by
+// the caller's context got no answer from the service, so there is nothing to record.
+func (r *recorder) record(ctx context.Context, operation string, input, output any, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	call := recordedCall{Operation: operation, Region: r.region}
+	var marshalErr error
+	if call.Request, marshalErr = json.Marshal(input); marshalErr != nil {
+		return fmt.Errorf("failed to record %s request: %w", operation, marshalErr)
+	}
+	if err != nil {
+		call.Error = newRecordedError(err)
+	} else if call.Response, marshalErr = json.Marshal(output); marshalErr != nil {
+		return fmt.Errorf("failed to record %s response: %w", operation, marshalErr)
+	}
+
+	if r.anonymizer != nil {
+		if marshalErr = r.anonymizer.anonymizeCall(&call); marshalErr != nil {
+			return fmt.Errorf("failed to anonymize %s: %w", operation, marshalErr)
+		}
+	}
+	data, marshalErr := json.MarshalIndent(call, "", "  ")
+	if marshalErr != nil {
+		return fmt.Errorf("failed to record %s: %w", operation, marshalErr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	path := filepath.Join(r.dir, fmt.Sprintf("%04d-%s.json", r.seq, operation))
+	if writeErr := os.WriteFile(path, append(data, '\n'), 0o644); writeErr != nil {
+		return fmt.Errorf("failed to record %s: %w", operation, writeErr)
+	}
+	return err
+}
+
+func newRecordedError(err error) *recordedError {
+	recorded := &recordedError{Message: err.Error()}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		recorded.Code = apiErr.ErrorCode()
+		recorded.Message = apiErr.ErrorMessage()
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		recorded.StatusCode = respErr.HTTPStatusCode()
+		recorded.RequestID = respErr.ServiceRequestID()
+		if respErr.Response != nil {
+			recorded.RetryAfter = respErr.Response.Header.Get("Retry-After")
+		}
+	}
+	return recorded
+}
+
+// arnPattern matches an ARN, capturing the part before the account ID, the account ID and
+// the resource, e.g. arn:aws:sagemaker:us-east-1:123456789012:endpoint/prod
+var arnPattern = regexp.MustCompile(`(arn:aws[a-z-]*:[a-z0-9-]*:[a-z0-9-]*:)(\d{12}):([A-Za-z0-9_.@+=/-]+)`)
+
+// anonymizedFields are the request and response fields holding names, mapped to the prefix
+// of their fake values
+var anonymizedFields = map[string]string{
+	"EndpointName":                        "endpoint",
+	"EndpointConfigName":                  "endpoint-config",
+	"ModelName":                           "model",
+	"NotebookInstanceName":                "notebook",
+	"NotebookInstanceLifecycleConfigName": "lifecycle-config",
+	"DefaultCodeRepository":               "repository",
+	"AppName":                             "app",
+	"UserProfileName":                     "user-profile",
+	"UserProfileNameEquals":               "user-profile",
+	"SpaceName":                           "space",
+	"SpaceNameEquals":                     "space",
+	"DomainName":                          "domain",
+	"Url":                                 "url",
+}
+
+// minAnonymizedLength is the length from which names are replaced in free text
+const minAnonymizedLength = 3
+
+// anonymizer replaces account IDs, resource names, URLs and tag values with stable fake
+// ones, so a recording still replays: a name gets the same fake wherever it appears,
+// including inside ARNs and error messages, and an account keeps one fake ID
+type anonymizer struct {
+	mu       sync.Mutex
+	accounts map[string]string
+	names    map[string]string
+}
+
+func newAnonymizer() *anonymizer {
+	return &anonymizer{accounts: make(map[string]string), names: make(map[string]string)}
+}
+
+// anonymizeCall rewrites the request, response and error message of a recorded call
+func (a *anonymizer) anonymizeCall(call *recordedCall) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err error
+	if call.Request, err = a.anonymizeJSON(call.Request); err != nil {
+		return err
+	}
+	if call.Response, err = a.anonymizeJSON(call.Response); err != nil {
+		return err
+	}
+	if call.Error != nil {
+		call.Error.Message = a.anonymizeText(call.Error.Message)
+	}
+	return nil
+}
+
+func (a *anonymizer) anonymizeJSON(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(a.anonymizeValue("", value))
+}
+
+// anonymizeValue rewrites a decoded JSON value found under the given field name
+func (a *anonymizer) anonymizeValue(field string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		_, hasKey := v["Key"]
+		// Sorted, so fake names are numbered the same way on every run
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			nested := v[key]
+			// Tag keys are kept for --group-by tag:<key>, their values are not
+			if key == "Value" && hasKey {
+				if text, ok := nested.(string); ok {
+					v[key] = a.fake("value", text)
+				}
+				continue
+			}
+			v[key] = a.anonymizeValue(key, nested)
+		}
+		return v
+	case []any:
+		for i, nested := range v {
+			v[i] = a.anonymizeValue(field, nested)
+		}
+		return v
+	case string:
+		if prefix, ok := anonymizedFields[field]; ok && v != "" {
+			return a.fake(prefix, v)
+		}
+		if field == "FailureReason" {
+			return a.anonymizeText(v)
+		}
+		return a.anonymizeARNs(v)
+	}
+	return value
+}
+
+// anonymizeText rewrites the ARNs and the names seen so far in free text such as error messages
+func (a *anonymizer) anonymizeText(text string) string {
+	text = a.anonymizeARNs(text)
+	originals := make([]string, 0, len(a.names))
+	for original := range a.names {
+		originals = append(originals, original)
+	}
+	// Longer names first, so a name containing another one is replaced whole
+	sort.Slice(originals, func(i, j int) bool { return len(originals[i]) > len(originals[j]) })
+	for _, original := range originals {
+		// Very short names would rewrite unrelated words
+		if len(original) >= minAnonymizedLength {
+			text = strings.ReplaceAll(text, original, a.names[original])
+		}
+	}
+	return text
+}
+
+// anonymizeARNs replaces the account ID and every part of the resource but its type, e.g.
+// arn:aws:sagemaker:us-east-1:123456789012:endpoint/prod becomes
+// arn:aws:sagemaker:us-east-1:000000000001:endpoint/endpoint-1
+func (a *anonymizer) anonymizeARNs(text string) string {
+	return arnPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := arnPattern.FindStringSubmatch(match)
+		account, ok := a.accounts[groups[2]]
+		if !ok {
+			account = fmt.Sprintf("%012d", len(a.accounts)+1)
+			a.accounts[groups[2]] = account
+		}
+		parts := strings.Split(groups[3], "/")
+		for i := 1; i < len(parts); i++ {
+			parts[i] = a.fake(parts[0], parts[i])
+		}
+		return groups[1] + account + ":" + strings.Join(parts, "/")
+	})
+}
+
+// fake returns the fake value of a name, creating one with the given prefix on first use
+func (a *anonymizer) fake(prefix, original string) string {
+	if fake, ok := a.names[original]; ok {
+		return fake
+	}
+	fake := fmt.Sprintf("%s-%d", prefix, len(a.names)+1)
+	a.names[original] = fake
+	return fake
+}
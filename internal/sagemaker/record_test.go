@@ -0,0 +1,218 @@
+package sagemaker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// throttlingError builds the error the SDK returns for a throttled request
+func throttlingError() error {
+	return &smithy.OperationError{
+		ServiceID:     "SageMaker",
+		OperationName: "ListEndpoints",
+		Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{"Retry-After": {"1"}}}},
+				Err:      &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"},
+			},
+			RequestID: "req-1",
+		},
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	mockClient := new(MockSageMakerClient)
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, throttlingError()).Once()
+	mockClient.On("ListEndpoints", mock.Anything, mock.Anything, mock.Anything).
+		Return(&sagemaker.ListEndpointsOutput{
+			Endpoints: []types.EndpointSummary{{
+				EndpointName:   aws.String("prod"),
+				EndpointArn:    aws.String("arn:aws:sagemaker:eu-west-1:987654321098:endpoint/prod"),
+				EndpointStatus: types.EndpointStatusInService,
+				CreationTime:   aws.Time(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}},
+		}, nil).Once()
+	mockClient.onEndpointInstances("prod", "ml.g5.xlarge", 2)
+	mockClient.On("ListTags", mock.Anything, mock.Anything, mock.Anything).
+		Return(&sagemaker.ListTagsOutput{Tags: []types.Tag{{Key: aws.String("team"), Value: aws.String("ml")}}}, nil)
+
+	recording, err := newRecorder(mockClient, dir, "eu-west-1", true)
+	assert.NoError(t, err)
+	client := &clientImpl{client: recording, region: "eu-west-1", clock: instantClock{}}
+
+	filter := Filter{CreatedBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	recorded, err := client.ListEndpoints(ctx, filter)
+	assert.NoError(t, err)
+	// The live run is not anonymized
+	assert.Equal(t, "arn:aws:sagemaker:eu-west-1:987654321098:endpoint/prod", recorded[0].Arn)
+	_, err = client.ListTags(ctx, recorded[0].Arn)
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0001-ListEndpoints.json"),
+		filepath.Join(dir, "0002-ListEndpoints.json"),
+		filepath.Join(dir, "0003-DescribeEndpoint.json"),
+		filepath.Join(dir, "0004-DescribeEndpointConfig.json"),
+		filepath.Join(dir, "0005-ListTags.json"),
+	}, files)
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		assert.NotContains(t, string(data), "987654321098")
+		assert.NotContains(t, string(data), "prod")
+		assert.NotContains(t, string(data), `"ml"`)
+	}
+
+	replay, err := newReplayer(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", replay.region)
+	client = &clientImpl{client: replay, region: replay.region, clock: instantClock{}}
+
+	// The throttled attempt replays as retryable, then the retry gets the recorded page.
+	// Relative time filters resolve differently on every run and are ignored when matching.
+	var retried []time.Duration
+	client.onRetry = func(operation string, attempt int, err error, nextBackoff time.Duration) {
+		retried = append(retried, nextBackoff)
+	}
+	filter.CreatedBefore = filter.CreatedBefore.Add(time.Hour)
+	replayed, err := client.ListEndpoints(ctx, filter)
+	assert.NoError(t, err)
+	// The recorded Retry-After header is honored too
+	if assert.Len(t, retried, 1) {
+		assert.GreaterOrEqual(t, retried[0], time.Second)
+	}
+	if assert.Len(t, replayed, 1) {
+		// Names are replaced consistently, so the endpoint is still described and its tags listed
+		assert.Equal(t, "endpoint-1", replayed[0].Name)
+		assert.Equal(t, "arn:aws:sagemaker:eu-west-1:000000000001:endpoint/endpoint-1", replayed[0].Arn)
+		assert.Equal(t, recorded[0].CreationTime, replayed[0].CreationTime)
+		assert.Equal(t, "ml.g5.xlarge", replayed[0].InstanceType)
+		assert.Equal(t, 2, replayed[0].InstanceCount)
+	}
+
+	tags, err := client.ListTags(ctx, replayed[0].Arn)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "value-3"}, tags)
+
+	// Requests that were never recorded fail without being retried
+	_, err = client.ListNotebooks(ctx, Filter{})
+	assert.ErrorContains(t, err, "no recorded ListNotebookInstances response")
+	var nonRetryable *NonRetryableError
+	assert.ErrorAs(t, err, &nonRetryable)
+}
+
+func TestNewRecorder_ExistingRecording(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001-ListDomains.json"), []byte("{}"), 0o644))
+
+	_, err := newRecorder(new(MockSageMakerClient), dir, "us-east-1", false)
+	assert.ErrorContains(t, err, "already contains a recording")
+
+	// A directory that doesn't exist yet is created
+	_, err = newRecorder(new(MockSageMakerClient), filepath.Join(dir, "new", "run"), "us-east-1", false)
+	assert.NoError(t, err)
+}
+
+func TestNewReplayer_Errors(t *testing.T) {
+	_, err := newReplayer(t.TempDir())
+	assert.ErrorContains(t, err, "no recordings found")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001-ListDomains.json"), []byte("not json"), 0o644))
+	_, err = newReplayer(dir)
+	assert.ErrorContains(t, err, "invalid recording")
+}
+
+func TestAnonymizer_ARNs(t *testing.T) {
+	a := newAnonymizer()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"arn:aws:sagemaker:us-east-1:111122223333:endpoint/a", "arn:aws:sagemaker:us-east-1:000000000001:endpoint/endpoint-1"},
+		{"arn:aws:iam::444455556666:role/service-role/x", "arn:aws:iam::000000000002:role/role-2/role-3"},
+		// The same account and names keep the same fake ones
+		{"arn:aws-cn:sagemaker:cn-north-1:111122223333:app/d-1/x/jupyterlab/default", "arn:aws-cn:sagemaker:cn-north-1:000000000001:app/app-4/role-3/app-5/app-6"},
+		{"role arn:aws:iam::444455556666:role/service-role/x: access denied", "role arn:aws:iam::000000000002:role/role-2/role-3: access denied"},
+		// Numbers outside ARNs are left alone
+		{"model-111122223333", "model-111122223333"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, a.anonymizeARNs(tt.input))
+	}
+}
+
+func TestAnonymizer_Call(t *testing.T) {
+	a := newAnonymizer()
+	call := recordedCall{
+		Operation: "DescribeNotebookInstance",
+		Request:   json.RawMessage(`{"NotebookInstanceName":"alice-notebook"}`),
+		Response: json.RawMessage(`{
+			"NotebookInstanceName": "alice-notebook",
+			"NotebookInstanceArn": "arn:aws:sagemaker:us-east-1:111122223333:notebook-instance/alice-notebook",
+			"Url": "alice-notebook.notebook.us-east-1.sagemaker.aws",
+			"FailureReason": "alice-notebook could not start",
+			"InstanceType": "ml.t3.medium",
+			"VolumeSizeInGB": 5,
+			"Tags": [{"Key": "owner", "Value": "alice@example.com"}]
+		}`),
+	}
+	assert.NoError(t, a.anonymizeCall(&call))
+	assert.JSONEq(t, `{"NotebookInstanceName":"notebook-1"}`, string(call.Request))
+	assert.JSONEq(t, `{
+		"NotebookInstanceName": "notebook-1",
+		"NotebookInstanceArn": "arn:aws:sagemaker:us-east-1:000000000001:notebook-instance/notebook-1",
+		"Url": "url-3",
+		"FailureReason": "notebook-1 could not start",
+		"InstanceType": "ml.t3.medium",
+		"VolumeSizeInGB": 5,
+		"Tags": [{"Key": "owner", "Value": "value-2"}]
+	}`, string(call.Response))
+
+	// Error messages mention the names of earlier calls
+	failed := recordedCall{
+		Operation: "DescribeEndpoint",
+		Request:   json.RawMessage(`{"EndpointName":"alice-notebook"}`),
+		Error:     &recordedError{Code: "ValidationException", Message: "Could not find endpoint alice-notebook."},
+	}
+	assert.NoError(t, a.anonymizeCall(&failed))
+	assert.Equal(t, "Could not find endpoint notebook-1.", failed.Error.Message)
+}
+
+func TestNewClientReplay(t *testing.T) {
+	dir := t.TempDir()
+	recording, err := newRecorder(nil, dir, "ap-northeast-1", false)
+	assert.NoError(t, err)
+	assert.NoError(t, recording.record(context.Background(), "ListDomains", &sagemaker.ListDomainsInput{MaxResults: aws.Int32(1)}, &sagemaker.ListDomainsOutput{}, nil))
+
+	client, err := newClient("", WithReplay(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-northeast-1", client.GetRegion())
+	hasResources, err := client.ValidateConfiguration(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, hasResources)
+
+	// An explicit region wins over the recorded one
+	client, err = newClient("us-west-2", WithReplay(dir))
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", client.GetRegion())
+}
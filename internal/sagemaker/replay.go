@@ -0,0 +1,223 @@
+package sagemaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// replayIgnoredFields are request fields left out when matching a request to a recording.
+// Relative filters such as --older-than resolve to a different time on every run.
+var replayIgnoredFields = []string{"CreationTimeBefore", "CreationTimeAfter"}
+
+// replayer implements SageMakerClientInterface by serving back the calls saved by recorder.
+// Identical requests are answered in the order they were recorded, so a throttled call
+// followed by its successful retry replays the same way.
+type replayer struct {
+	dir    string
+	region string
+
+	mu    sync.Mutex
+	calls map[string][]recordedCall
+}
+
+// newReplayer loads every recording in dir
+func newReplayer(dir string) (*replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, recordingPattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	r := &replayer{dir: dir, calls: make(map[string][]recordedCall)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		var call recordedCall
+		if err := json.Unmarshal(data, &call); err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+		}
+		key, err := replayKey(call.Operation, call.Request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+		}
+		r.calls[key] = append(r.calls[key], call)
+		if r.region == "" {
+			r.region = call.Region
+		}
+	}
+	return r, nil
+}
+
+func (r *replayer) ListApps(ctx context.Context, params *sagemaker.ListAppsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListAppsOutput, error) {
+	output := &sagemaker.ListAppsOutput{}
+	return output, r.replay("ListApps", params, output)
+}
+
+func (r *replayer) ListEndpoints(ctx context.Context, params *sagemaker.ListEndpointsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListEndpointsOutput, error) {
+	output := &sagemaker.ListEndpointsOutput{}
+	return output, r.replay("ListEndpoints", params, output)
+}
+
+func (r *replayer) ListNotebookInstances(ctx context.Context, params *sagemaker.ListNotebookInstancesInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListNotebookInstancesOutput, error) {
+	output := &sagemaker.ListNotebookInstancesOutput{}
+	return output, r.replay("ListNotebookInstances", params, output)
+}
+
+func (r *replayer) ListDomains(ctx context.Context, params *sagemaker.ListDomainsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListDomainsOutput, error) {
+	output := &sagemaker.ListDomainsOutput{}
+	return output, r.replay("ListDomains", params, output)
+}
+
+func (r *replayer) ListTags(ctx context.Context, params *sagemaker.ListTagsInput, optFns ...func(*sagemaker.Options)) (*sagemaker.ListTagsOutput, error) {
+	output := &sagemaker.ListTagsOutput{}
+	return output, r.replay("ListTags", params, output)
+}
+
+func (r *replayer) StopNotebookInstance(ctx context.Context, params *sagemaker.StopNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.StopNotebookInstanceOutput, error) {
+	output := &sagemaker.StopNotebookInstanceOutput{}
+	return output, r.replay("StopNotebookInstance", params, output)
+}
+
+func (r *replayer) DeleteNotebookInstance(ctx context.Context, params *sagemaker.DeleteNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteNotebookInstanceOutput, error) {
+	output := &sagemaker.DeleteNotebookInstanceOutput{}
+	return output, r.replay("DeleteNotebookInstance", params, output)
+}
+
+func (r *replayer) DeleteEndpoint(ctx context.Context, params *sagemaker.DeleteEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteEndpointOutput, error) {
+	output := &sagemaker.DeleteEndpointOutput{}
+	return output, r.replay("DeleteEndpoint", params, output)
+}
+
+func (r *replayer) DeleteApp(ctx context.Context, params *sagemaker.DeleteAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DeleteAppOutput, error) {
+	output := &sagemaker.DeleteAppOutput{}
+	return output, r.replay("DeleteApp", params, output)
+}
+
+func (r *replayer) DescribeEndpoint(ctx context.Context, params *sagemaker.DescribeEndpointInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointOutput, error) {
+	output := &sagemaker.DescribeEndpointOutput{}
+	return output, r.replay("DescribeEndpoint", params, output)
+}
+
+func (r *replayer) DescribeEndpointConfig(ctx context.Context, params *sagemaker.DescribeEndpointConfigInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeEndpointConfigOutput, error) {
+	output := &sagemaker.DescribeEndpointConfigOutput{}
+	return output, r.replay("DescribeEndpointConfig", params, output)
+}
+
+func (r *replayer) DescribeModel(ctx context.Context, params *sagemaker.DescribeModelInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeModelOutput, error) {
+	output := &sagemaker.DescribeModelOutput{}
+	return output, r.replay("DescribeModel", params, output)
+}
+
+func (r *replayer) DescribeNotebookInstance(ctx context.Context, params *sagemaker.DescribeNotebookInstanceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error) {
+	output := &sagemaker.DescribeNotebookInstanceOutput{}
+	return output, r.replay("DescribeNotebookInstance", params, output)
+}
+
+func (r *replayer) DescribeApp(ctx context.Context, params *sagemaker.DescribeAppInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeAppOutput, error) {
+	output := &sagemaker.DescribeAppOutput{}
+	return output, r.replay("DescribeApp", params, output)
+}
+
+func (r *replayer) DescribeDomain(ctx context.Context, params *sagemaker.DescribeDomainInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error) {
+	output := &sagemaker.DescribeDomainOutput{}
+	return output, r.replay("DescribeDomain", params, output)
+}
+
+func (r *replayer) DescribeUserProfile(ctx context.Context, params *sagemaker.DescribeUserProfileInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeUserProfileOutput, error) {
+	output := &sagemaker.DescribeUserProfileOutput{}
+	return output, r.replay("DescribeUserProfile", params, output)
+}
+
+func (r *replayer) DescribeSpace(ctx context.Context, params *sagemaker.DescribeSpaceInput, optFns ...func(*sagemaker.Options)) (*sagemaker.DescribeSpaceOutput, error) {
+	output := &sagemaker.DescribeSpaceOutput{}
+	return output, r.replay("DescribeSpace", params, output)
+}
+
+// replay decodes the next recorded response for the request into output, or returns the
+// recorded error
+func (r *replayer) replay(operation string, input, output any) error {
+	request, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	key, err := replayKey(operation, request)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	queued := r.calls[key]
+	if len(queued) == 0 {
+		r.mu.Unlock()
+		return fmt.Errorf("no recorded %s response in %s matches request %s; record again with the same flags", operation, r.dir, request)
+	}
+	call := queued[0]
+	r.calls[key] = queued[1:]
+	r.mu.Unlock()
+
+	if call.Error != nil {
+		return &smithy.OperationError{ServiceID: "SageMaker", OperationName: operation, Err: call.Error.err()}
+	}
+	if err := json.Unmarshal(call.Response, output); err != nil {
+		return fmt.Errorf("invalid recorded %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// replayKey identifies a request by operation and parameters, minus replayIgnoredFields.
+// Parameters are re-encoded from a map so field order doesn't matter.
+func replayKey(operation string, request json.RawMessage) (string, error) {
+	var params map[string]any
+	if err := json.Unmarshal(request, &params); err != nil {
+		return "", err
+	}
+	for _, field := range replayIgnoredFields {
+		delete(params, field)
+	}
+	normalized, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return operation + " " + string(normalized), nil
+}
+
+// err rebuilds the recorded error with the same code and HTTP status, so it is classified
+// as retryable or not exactly like the original
+func (e *recordedError) err() error {
+	if e.Code == "" && e.StatusCode == 0 {
+		return errors.New(e.Message)
+	}
+
+	apiErr := &smithy.GenericAPIError{Code: e.Code, Message: e.Message}
+	if e.StatusCode == 0 {
+		return apiErr
+	}
+	header := http.Header{}
+	if e.RetryAfter != "" {
+		header.Set("Retry-After", e.RetryAfter)
+	}
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: e.StatusCode, Header: header}},
+			Err:      apiErr,
+		},
+		RequestID: e.RequestID,
+	}
+}
@@ -0,0 +1,62 @@
+package sagemaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// traceMiddleware logs every SDK operation with its latency and request ID
+var traceMiddleware = middleware.InitializeMiddlewareFunc("MohuaTrace", func(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (middleware.InitializeOutput, middleware.Metadata, error) {
+	start := time.Now()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(metadata)
+	var respErr *awshttp.ResponseError
+	if requestID == "" && errors.As(err, &respErr) {
+		requestID = respErr.ServiceRequestID()
+	}
+	attrs := []any{
+		"operation", awsmiddleware.GetOperationName(ctx),
+		"latency", time.Since(start),
+		"requestId", requestID,
+	}
+	if err != nil {
+		slog.InfoContext(ctx, "sagemaker request failed", append(attrs, "error", err)...)
+	} else {
+		slog.InfoContext(ctx, "sagemaker request", attrs...)
+	}
+	return out, metadata, err
+})
+
+// addTraceMiddleware registers traceMiddleware after the operation name is known
+func addTraceMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(traceMiddleware, middleware.After)
+}
+
+// signedHeaders matches headers carrying credentials or signatures in SDK wire logs
+var signedHeaders = regexp.MustCompile(`(?im)^(Authorization|X-Amz-Security-Token|X-Amz-Signature|X-Amz-Credential):.*$`)
+
+// redactSignedHeaders replaces the values of credential-bearing headers
+func redactSignedHeaders(s string) string {
+	return signedHeaders.ReplaceAllString(s, "$1: [REDACTED]")
+}
+
+// sdkLogger forwards the SDK's ClientLogMode output to slog at debug level
+type sdkLogger struct{}
+
+var _ logging.Logger = sdkLogger{}
+
+func (sdkLogger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	slog.Debug("aws sdk", "classification", string(classification), "message", redactSignedHeaders(fmt.Sprintf(format, v...)))
+}
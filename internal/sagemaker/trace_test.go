@@ -0,0 +1,92 @@
+package sagemaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// cannedHTTPClient answers every request with the same JSON body and request ID
+type cannedHTTPClient struct {
+	body string
+}
+
+func (c cannedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-amz-json-1.1")
+	header.Set("X-Amzn-Requestid", "req-123")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    req,
+	}, nil
+}
+
+// captureLogs redirects the default slog logger to a JSON buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(original)
+	})
+	return &buf
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	logs := captureLogs(t)
+
+	client := sagemaker.New(sagemaker.Options{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "TOKEN"),
+		HTTPClient:  cannedHTTPClient{body: `{"Domains":[]}`},
+		APIOptions:  []func(*middleware.Stack) error{addTraceMiddleware},
+	})
+	_, err := client.ListDomains(context.Background(), &sagemaker.ListDomainsInput{MaxResults: aws.Int32(1)})
+	assert.NoError(t, err)
+
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "sagemaker request", record["msg"])
+	assert.Equal(t, "ListDomains", record["operation"])
+	assert.Equal(t, "req-123", record["requestId"])
+	assert.Contains(t, record, "latency")
+}
+
+func TestRedactSignedHeaders(t *testing.T) {
+	raw := "POST / HTTP/1.1\r\n" +
+		"Host: api.sagemaker.us-east-1.amazonaws.com\r\n" +
+		"Authorization: AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/sagemaker/aws4_request, Signature=abc\r\n" +
+		"X-Amz-Security-Token: TOKEN\r\n" +
+		"X-Amz-Target: SageMaker.ListDomains\r\n"
+
+	redacted := redactSignedHeaders(raw)
+	assert.NotContains(t, redacted, "AKID")
+	assert.NotContains(t, redacted, "Signature=abc")
+	assert.NotContains(t, redacted, "TOKEN")
+	assert.Contains(t, redacted, "Authorization: [REDACTED]")
+	assert.Contains(t, redacted, "X-Amz-Security-Token: [REDACTED]")
+	assert.Contains(t, redacted, "X-Amz-Target: SageMaker.ListDomains")
+}
+
+func TestSDKLogger(t *testing.T) {
+	logs := captureLogs(t)
+
+	sdkLogger{}.Logf("DEBUG", "Request\n%s", "Authorization: secret\r\n")
+
+	assert.Contains(t, logs.String(), `"msg":"aws sdk"`)
+	assert.Contains(t, logs.String(), "[REDACTED]")
+	assert.NotContains(t, logs.String(), "secret")
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// Key is a key press: one of the named keys below, or the typed character itself
+type Key string
+
+// Named keys
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdown"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyEscape    Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyDelete    Key = "delete"
+	KeyTab       Key = "tab"
+	KeyShiftTab  Key = "shift-tab"
+	KeyCtrlC     Key = "ctrl-c"
+)
+
+// escapeSequences maps the CSI and SS3 sequences sent by common terminals to keys
+var escapeSequences = map[string]Key{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[5~": KeyPageUp, "[6~": KeyPageDown,
+	"[3~": KeyDelete,
+	"[Z":  KeyShiftTab,
+}
+
+// ParseKeys decodes the bytes of one read from a terminal in raw mode. Terminals send an
+// escape sequence in a single write, so an escape at the end of the input is the Escape key.
+// Unknown sequences and control characters are dropped.
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n, key := parseEscape(b)
+			if key != "" {
+				keys = append(keys, key)
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, KeyEnter)
+		case c == '\t':
+			keys = append(keys, KeyTab)
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, KeyBackspace)
+		case c == 0x03:
+			keys = append(keys, KeyCtrlC)
+		case c < 0x20:
+			// Other control characters have no binding
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				keys = append(keys, Key(string(r)))
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// parseEscape decodes the escape sequence at the start of b, returning its length and key
+func parseEscape(b []byte) (int, Key) {
+	if len(b) == 1 || (b[1] != '[' && b[1] != 'O') {
+		return 1, KeyEscape
+	}
+	// CSI parameters end with a final byte in @ to ~; SS3 is always one more byte
+	end := 2
+	if b[1] == '[' {
+		for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+			end++
+		}
+	}
+	if end >= len(b) {
+		return len(b), ""
+	}
+	return end + 1, escapeSequences[string(b[1:end+1])]
+}
+
+// ReadKeys sends the keys read from r until reading fails, then closes keys
+func ReadKeys(r io.Reader, keys chan<- Key) {
+	defer close(keys)
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range ParseKeys(buf[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
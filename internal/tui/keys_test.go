@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{name: "characters", input: "jké/", want: []Key{"j", "k", "é", "/"}},
+		{name: "arrows", input: "\x1b[A\x1b[B\x1bOC\x1b[D", want: []Key{KeyUp, KeyDown, KeyRight, KeyLeft}},
+		{name: "paging", input: "\x1b[5~\x1b[6~\x1b[H\x1b[4~", want: []Key{KeyPageUp, KeyPageDown, KeyHome, KeyEnd}},
+		{name: "editing", input: "\r\t\x7f\x1b[3~\x1b[Z", want: []Key{KeyEnter, KeyTab, KeyBackspace, KeyDelete, KeyShiftTab}},
+		{name: "lone escape", input: "\x1b", want: []Key{KeyEscape}},
+		{name: "escape then character", input: "\x1bq", want: []Key{KeyEscape, "q"}},
+		{name: "ctrl-c", input: "\x03", want: []Key{KeyCtrlC}},
+		{name: "unknown sequences and controls are dropped", input: "\x1b[1;5A\x01x\x1b[", want: []Key{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseKeys([]byte(tt.input)))
+		})
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	keys := make(chan Key, 10)
+	ReadKeys(strings.NewReader("j\x1b[Bq"), keys)
+
+	var got []Key
+	for key := range keys {
+		got = append(got, key)
+	}
+	assert.Equal(t, []Key{"j", KeyDown, "q"}, got)
+}
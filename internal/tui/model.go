@@ -0,0 +1,422 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+)
+
+// Resource is a listed resource in its display form, with the listed fields needed to act on it
+type Resource struct {
+	display.ResourceInfo
+	Source sagemaker.ResourceInfo
+}
+
+// Key identifies the resource across refreshes; Studio display names are not unique
+func (r Resource) Key() string {
+	return strings.Join([]string{r.ResourceType, r.Region, r.Source.DomainID, r.Source.UserProfile, r.Source.SpaceName, r.Source.AppType, r.Source.Name, r.Name}, "\x00")
+}
+
+// typeTabs are the resource type tabs, "" showing every type
+var typeTabs = []string{"", "Endpoint", "Notebook", "Studio"}
+
+// ActionKind is what an action does to a resource
+type ActionKind string
+
+// Supported actions
+const (
+	ActionStop   ActionKind = "stop"
+	ActionDelete ActionKind = "delete"
+)
+
+// Action is a confirmed stop or delete of a resource
+type Action struct {
+	Kind     ActionKind
+	Resource Resource
+}
+
+// checkAction returns why the action is not possible on the resource, or nil
+func checkAction(kind ActionKind, r Resource) error {
+	switch {
+	case kind == ActionStop && r.ResourceType == "Notebook":
+		if r.Status != "InService" {
+			return fmt.Errorf("only InService notebooks can be stopped, %s is %s", r.Name, r.Status)
+		}
+	case kind == ActionStop && r.ResourceType == "Endpoint":
+		return fmt.Errorf("endpoints cannot be stopped, only deleted")
+	case kind == ActionStop && r.ResourceType == "Studio":
+		return fmt.Errorf("a Studio app is stopped by deleting it")
+	case kind == ActionDelete && r.ResourceType == "Notebook":
+		if r.Status != "Stopped" && r.Status != "Failed" {
+			return fmt.Errorf("stop notebook %s before deleting it", r.Name)
+		}
+	case kind == ActionDelete && (r.Status == "Deleting" || r.Status == "Deleted"):
+		return fmt.Errorf("%s is already %s", r.Name, strings.ToLower(r.Status))
+	}
+	return nil
+}
+
+// prompt asks for confirmation of the action
+func (a Action) prompt() string {
+	target := fmt.Sprintf("%s %s in %s", strings.ToLower(a.Resource.ResourceType), a.Resource.Name, a.Resource.Region)
+	if a.Kind == ActionDelete {
+		return fmt.Sprintf("Delete %s? This cannot be undone. [y/N]", target)
+	}
+	return fmt.Sprintf("Stop %s? [y/N]", target)
+}
+
+// Effect is what the event loop must do after a key press
+type Effect struct {
+	Quit    bool
+	Refresh bool
+	// Run is a confirmed action to perform
+	Run *Action
+}
+
+// Model is the state of the UI. It is only changed by the event loop, so it needs no locking.
+type Model struct {
+	resources []Resource
+	errors    []string
+	loadedAt  time.Time
+	loading   bool
+	regions   []string
+
+	typeTab   int
+	regionTab int
+	filter    string
+	filtering bool
+
+	// selected is the key of the selected resource, so that it stays selected when the list
+	// is refreshed; cursor is its position in the visible list
+	selected string
+	cursor   int
+	offset   int
+	height   int
+
+	pending  *Action
+	status   string
+	showHelp bool
+
+	// details are the descriptions fetched for the detail pane, which scrolls by detailOffset lines
+	details          map[string]display.Detail
+	detailErrors     map[string]string
+	detailsRequested map[string]bool
+	detailOffset     int
+}
+
+// NewModel creates the state for the given regions; more region tabs appear as resources
+// from other regions are listed
+func NewModel(regions []string) *Model {
+	return &Model{
+		regions:          append([]string{}, regions...),
+		loading:          true,
+		height:           10,
+		details:          make(map[string]display.Detail),
+		detailErrors:     make(map[string]string),
+		detailsRequested: make(map[string]bool),
+	}
+}
+
+// SetResources replaces the listed resources after a refresh, keeping the selection
+func (m *Model) SetResources(resources []Resource, errs []error, now time.Time) {
+	m.resources = append([]Resource{}, resources...)
+	sort.SliceStable(m.resources, func(i, j int) bool {
+		a, b := m.resources[i], m.resources[j]
+		if a.ResourceType != b.ResourceType {
+			return typeOrder(a.ResourceType) < typeOrder(b.ResourceType)
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Name < b.Name
+	})
+	m.errors = nil
+	for _, err := range errs {
+		m.errors = append(m.errors, err.Error())
+	}
+	for _, r := range m.resources {
+		if r.Region != "" && !contains(m.regions, r.Region) {
+			m.regions = append(m.regions, r.Region)
+		}
+	}
+	m.loadedAt = now
+	m.loading = false
+	// Descriptions are fetched again, showing the previous one until then
+	m.detailsRequested = make(map[string]bool)
+	m.sync()
+}
+
+// SetLoading marks a refresh as in progress
+func (m *Model) SetLoading() {
+	m.loading = true
+}
+
+// SetStatus shows a message on the status line
+func (m *Model) SetStatus(status string) {
+	m.status = status
+}
+
+// SetDetail records the description fetched for a resource, or why it could not be fetched
+func (m *Model) SetDetail(key string, detail display.Detail, err error) {
+	if err != nil {
+		m.detailErrors[key] = err.Error()
+		return
+	}
+	delete(m.detailErrors, key)
+	m.details[key] = detail
+}
+
+// DetailNeeded returns the selected resource when its description has not been requested
+// since the last refresh
+func (m *Model) DetailNeeded() (Resource, bool) {
+	r, ok := m.Selected()
+	if !ok || m.detailsRequested[r.Key()] {
+		return Resource{}, false
+	}
+	m.detailsRequested[r.Key()] = true
+	return r, true
+}
+
+// Selected returns the selected resource, if any is visible
+func (m *Model) Selected() (Resource, bool) {
+	visible := m.Visible()
+	if len(visible) == 0 {
+		return Resource{}, false
+	}
+	return visible[m.cursor], true
+}
+
+// Visible returns the resources shown by the current tabs and filter
+func (m *Model) Visible() []Resource {
+	resourceType := typeTabs[m.typeTab]
+	region := m.region()
+	filter := strings.ToLower(m.filter)
+
+	var visible []Resource
+	for _, r := range m.resources {
+		if resourceType != "" && r.ResourceType != resourceType {
+			continue
+		}
+		if region != "" && r.Region != region {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(searchText(r)), filter) {
+			continue
+		}
+		visible = append(visible, r)
+	}
+	return visible
+}
+
+// searchText is what the filter matches against
+func searchText(r Resource) string {
+	fields := []string{r.ResourceType, r.Name, r.Status, r.InstanceType, r.UserProfile, r.Region, r.Source.SpaceName}
+	for key, value := range r.Tags {
+		fields = append(fields, key+"="+value)
+	}
+	return strings.Join(fields, " ")
+}
+
+// region returns the region of the selected region tab, "" for all regions
+func (m *Model) region() string {
+	if m.regionTab == 0 || m.regionTab > len(m.regions) {
+		return ""
+	}
+	return m.regions[m.regionTab-1]
+}
+
+// sync moves the cursor to the selected resource, or keeps it in range when the resource
+// is no longer visible
+func (m *Model) sync() {
+	visible := m.Visible()
+	for i, r := range visible {
+		if r.Key() == m.selected {
+			m.cursor = i
+			m.scroll()
+			return
+		}
+	}
+	m.cursor = clamp(m.cursor, 0, len(visible)-1)
+	key := ""
+	if len(visible) > 0 {
+		key = visible[m.cursor].Key()
+	}
+	m.selectKey(key)
+	m.scroll()
+}
+
+// selectKey selects the resource with the key, scrolling the detail pane back to the top
+// when the selection changes
+func (m *Model) selectKey(key string) {
+	if key != m.selected {
+		m.detailOffset = 0
+	}
+	m.selected = key
+}
+
+// move moves the cursor by delta rows
+func (m *Model) move(delta int) {
+	visible := m.Visible()
+	if len(visible) == 0 {
+		return
+	}
+	m.cursor = clamp(m.cursor+delta, 0, len(visible)-1)
+	m.selectKey(visible[m.cursor].Key())
+	m.scroll()
+}
+
+// scroll keeps the cursor within the rows shown by the list
+func (m *Model) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+	m.offset = clamp(m.offset, 0, len(m.Visible())-m.height)
+}
+
+// setHeight sets the number of list rows shown, used for paging and scrolling
+func (m *Model) setHeight(height int) {
+	if height < 1 {
+		height = 1
+	}
+	m.height = height
+	m.scroll()
+}
+
+// Update applies a key press
+func (m *Model) Update(key Key) Effect {
+	if key == KeyCtrlC {
+		return Effect{Quit: true}
+	}
+	if m.pending != nil {
+		action := *m.pending
+		m.pending = nil
+		if key == "y" || key == "Y" {
+			return Effect{Run: &action}
+		}
+		m.status = "Cancelled"
+		return Effect{}
+	}
+	if m.filtering && m.updateFilter(key) {
+		return Effect{}
+	}
+
+	m.status = ""
+	switch key {
+	case "q":
+		return Effect{Quit: true}
+	case KeyUp, "k":
+		m.move(-1)
+	case KeyDown, "j":
+		m.move(1)
+	case KeyPageUp:
+		m.move(-m.height)
+	case KeyPageDown:
+		m.move(m.height)
+	case KeyHome, "g":
+		m.move(-len(m.resources))
+	case KeyEnd, "G":
+		m.move(len(m.resources))
+	case KeyTab, KeyRight, "l":
+		m.typeTab = (m.typeTab + 1) % len(typeTabs)
+		m.sync()
+	case KeyShiftTab, KeyLeft, "h":
+		m.typeTab = (m.typeTab + len(typeTabs) - 1) % len(typeTabs)
+		m.sync()
+	case "]":
+		m.regionTab = (m.regionTab + 1) % (len(m.regions) + 1)
+		m.sync()
+	case "[":
+		m.regionTab = (m.regionTab + len(m.regions)) % (len(m.regions) + 1)
+		m.sync()
+	case "/":
+		m.filtering = true
+	case KeyEscape:
+		m.filter = ""
+		m.sync()
+	case "r":
+		return Effect{Refresh: true}
+	case "s":
+		m.confirm(ActionStop)
+	case "x", KeyDelete:
+		m.confirm(ActionDelete)
+	case "J":
+		m.detailOffset++
+	case "K":
+		m.detailOffset = max(m.detailOffset-1, 0)
+	case "?":
+		m.showHelp = !m.showHelp
+	}
+	return Effect{}
+}
+
+// updateFilter edits the filter while typing it, returning false for keys it doesn't handle
+func (m *Model) updateFilter(key Key) bool {
+	switch key {
+	case KeyEnter:
+		m.filtering = false
+	case KeyEscape:
+		m.filtering = false
+		m.filter = ""
+	case KeyBackspace:
+		if runes := []rune(m.filter); len(runes) > 0 {
+			m.filter = string(runes[:len(runes)-1])
+		}
+	default:
+		if len([]rune(string(key))) != 1 {
+			return false
+		}
+		m.filter += string(key)
+	}
+	m.sync()
+	return true
+}
+
+// confirm asks for confirmation of an action on the selected resource
+func (m *Model) confirm(kind ActionKind) {
+	r, ok := m.Selected()
+	if !ok {
+		m.status = "No resource selected"
+		return
+	}
+	if err := checkAction(kind, r); err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.pending = &Action{Kind: kind, Resource: r}
+}
+
+// typeOrder sorts resources in the order of the type tabs
+func typeOrder(resourceType string) int {
+	for i, t := range typeTabs {
+		if t == resourceType {
+			return i
+		}
+	}
+	return len(typeTabs)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
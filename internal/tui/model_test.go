@@ -0,0 +1,337 @@
+package tui
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+	"mohua/internal/sagemaker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testResources lists one resource of each type in us-east-1 and a notebook in eu-west-1
+func testResources() []Resource {
+	return []Resource{
+		{
+			ResourceInfo: display.ResourceInfo{ResourceType: "Studio", Name: "alice/JupyterLab", Status: "InService", InstanceType: "ml.g5.xlarge", InstanceCount: 1, UserProfile: "alice", Region: "us-east-1", HourlyCost: 1.41, RunningSeconds: 7200},
+			Source:       sagemaker.ResourceInfo{Name: "default", AppType: "JupyterLab", UserProfile: "alice", SpaceName: "alice-space", DomainID: "d-1", StudioType: "New Studio (JupyterLab)"},
+		},
+		{
+			ResourceInfo: display.ResourceInfo{ResourceType: "Notebook", Name: "dev", Arn: "arn:aws:sagemaker:us-east-1:123456789012:notebook-instance/dev", Status: "InService", InstanceType: "ml.t3.medium", InstanceCount: 1, Region: "us-east-1", HourlyCost: 0.05, CreationTime: time.Now().Add(-10 * time.Hour), RunningSeconds: 36000, Tags: map[string]string{"team": "research"}},
+			Source:       sagemaker.ResourceInfo{Name: "dev", VolumeSize: 5},
+		},
+		{
+			ResourceInfo: display.ResourceInfo{ResourceType: "Endpoint", Name: "prod", Arn: "arn:aws:sagemaker:us-east-1:123456789012:endpoint/prod", Status: "InService", InstanceType: "ml.m5.large", InstanceCount: 2, Region: "us-east-1", HourlyCost: 0.23},
+			Source:       sagemaker.ResourceInfo{Name: "prod"},
+		},
+		{
+			ResourceInfo: display.ResourceInfo{ResourceType: "Notebook", Name: "old", Arn: "arn:aws:sagemaker:eu-west-1:123456789012:notebook-instance/old", Status: "Stopped", InstanceType: "ml.m5.xlarge", InstanceCount: 1, Region: "eu-west-1"},
+			Source:       sagemaker.ResourceInfo{Name: "old"},
+		},
+	}
+}
+
+func newTestModel() *Model {
+	m := NewModel([]string{"us-east-1"})
+	m.SetResources(testResources(), nil, time.Now())
+	return m
+}
+
+func names(resources []Resource) []string {
+	var result []string
+	for _, r := range resources {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func selectedName(m *Model) string {
+	r, _ := m.Selected()
+	return r.Name
+}
+
+func TestModelNavigation(t *testing.T) {
+	m := newTestModel()
+
+	// Sorted by type, region and name; regions seen in the results get a tab
+	assert.Equal(t, []string{"prod", "old", "dev", "alice/JupyterLab"}, names(m.Visible()))
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, m.regions)
+	assert.Equal(t, "prod", selectedName(m))
+
+	m.Update(KeyDown)
+	m.Update("j")
+	assert.Equal(t, "dev", selectedName(m))
+	m.Update(KeyEnd)
+	assert.Equal(t, "alice/JupyterLab", selectedName(m))
+	m.Update("k")
+	m.Update(KeyHome)
+	assert.Equal(t, "prod", selectedName(m))
+
+	// Type tabs wrap around
+	m.Update(KeyTab)
+	assert.Equal(t, []string{"prod"}, names(m.Visible()))
+	m.Update(KeyTab)
+	assert.Equal(t, []string{"old", "dev"}, names(m.Visible()))
+	m.Update(KeyShiftTab)
+	m.Update(KeyShiftTab)
+	m.Update(KeyShiftTab)
+	assert.Equal(t, []string{"alice/JupyterLab"}, names(m.Visible()))
+	m.Update(KeyRight)
+
+	// Region tabs combine with the type tab
+	m.Update("]")
+	m.Update("]")
+	assert.Equal(t, []string{"old"}, names(m.Visible()))
+	m.Update(KeyTab)
+	assert.Empty(t, m.Visible())
+	_, ok := m.Selected()
+	assert.False(t, ok)
+	m.Update(KeyShiftTab)
+	m.Update("[")
+	assert.Equal(t, []string{"prod", "dev", "alice/JupyterLab"}, names(m.Visible()))
+
+	assert.True(t, m.Update("q").Quit)
+	assert.True(t, m.Update(KeyCtrlC).Quit)
+	assert.True(t, m.Update("r").Refresh)
+}
+
+func TestModelFilter(t *testing.T) {
+	m := newTestModel()
+	m.Update(KeyEnd)
+
+	for _, key := range []Key{"/", "D", "e", "x", KeyBackspace} {
+		m.Update(key)
+	}
+	assert.Equal(t, "De", m.filter)
+	assert.Equal(t, []string{"dev"}, names(m.Visible()))
+	assert.Equal(t, "dev", selectedName(m))
+
+	// q is typed into the filter rather than quitting, and navigation keys still work
+	assert.False(t, m.Update("q").Quit)
+	assert.Empty(t, m.Visible())
+	m.Update(KeyBackspace)
+	m.Update(KeyEnter)
+	assert.False(t, m.filtering)
+	assert.Equal(t, []string{"dev"}, names(m.Visible()))
+
+	// Tags and Studio spaces are matched too
+	m.Update("/")
+	m.Update(KeyEscape)
+	assert.Empty(t, m.filter)
+	m.filter = "team=research"
+	assert.Equal(t, []string{"dev"}, names(m.Visible()))
+	m.filter = "alice-space"
+	assert.Equal(t, []string{"alice/JupyterLab"}, names(m.Visible()))
+	m.Update(KeyEscape)
+	assert.Len(t, m.Visible(), 4)
+}
+
+func TestModelRefreshKeepsSelection(t *testing.T) {
+	m := newTestModel()
+	m.Update(KeyDown)
+	m.Update(KeyDown)
+	assert.Equal(t, "dev", selectedName(m))
+
+	// The selected resource moves after a refresh
+	resources := testResources()
+	resources = append(resources, Resource{ResourceInfo: display.ResourceInfo{ResourceType: "Endpoint", Name: "a-new", Region: "us-east-1"}})
+	m.SetResources(resources, []error{errors.New("eu-west-1: failed to list endpoints")}, time.Now())
+	assert.Equal(t, "dev", selectedName(m))
+	assert.Equal(t, []string{"eu-west-1: failed to list endpoints"}, m.errors)
+
+	// When it disappears, the cursor stays in place
+	m.SetResources(testResources()[:1], nil, time.Now())
+	assert.Equal(t, "alice/JupyterLab", selectedName(m))
+	assert.Empty(t, m.errors)
+}
+
+func TestCheckAction(t *testing.T) {
+	resources := testResources()
+	studio, running, endpoint, stopped := resources[0], resources[1], resources[2], resources[3]
+
+	tests := []struct {
+		name    string
+		kind    ActionKind
+		r       Resource
+		wantErr string
+	}{
+		{name: "stop running notebook", kind: ActionStop, r: running},
+		{name: "stop stopped notebook", kind: ActionStop, r: stopped, wantErr: "only InService notebooks can be stopped"},
+		{name: "stop endpoint", kind: ActionStop, r: endpoint, wantErr: "endpoints cannot be stopped"},
+		{name: "stop Studio app", kind: ActionStop, r: studio, wantErr: "stopped by deleting it"},
+		{name: "delete running notebook", kind: ActionDelete, r: running, wantErr: "stop notebook dev before deleting it"},
+		{name: "delete stopped notebook", kind: ActionDelete, r: stopped},
+		{name: "delete endpoint", kind: ActionDelete, r: endpoint},
+		{name: "delete Studio app", kind: ActionDelete, r: studio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAction(tt.kind, tt.r)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	endpoint.Status = "Deleting"
+	assert.ErrorContains(t, checkAction(ActionDelete, endpoint), "prod is already deleting")
+}
+
+func TestModelConfirmation(t *testing.T) {
+	m := newTestModel()
+
+	// Endpoints cannot be stopped
+	assert.Equal(t, Effect{}, m.Update("s"))
+	assert.Nil(t, m.pending)
+	assert.Contains(t, m.status, "endpoints cannot be stopped")
+
+	// Anything but y cancels
+	m.Update("x")
+	assert.Equal(t, "Delete endpoint prod in us-east-1? This cannot be undone. [y/N]", m.pending.prompt())
+	assert.Equal(t, Effect{}, m.Update("q"))
+	assert.Nil(t, m.pending)
+	assert.Equal(t, "Cancelled", m.status)
+
+	m.Update(KeyDown)
+	m.Update(KeyDown)
+	m.Update("s")
+	assert.Equal(t, "Stop notebook dev in us-east-1? [y/N]", m.pending.prompt())
+	effect := m.Update("y")
+	if assert.NotNil(t, effect.Run) {
+		assert.Equal(t, ActionStop, effect.Run.Kind)
+		assert.Equal(t, "dev", effect.Run.Resource.Source.Name)
+	}
+	assert.Nil(t, m.pending)
+
+	m.Update(KeyTab)
+	m.Update(KeyTab)
+	m.filter = "nothing"
+	m.sync()
+	m.Update(KeyDelete)
+	assert.Equal(t, "No resource selected", m.status)
+}
+
+func TestModelDetail(t *testing.T) {
+	m := newTestModel()
+	view := func() string {
+		return ansi.ReplaceAllString(strings.Join(m.detailLines(100), "\n"), "")
+	}
+
+	r, ok := m.DetailNeeded()
+	assert.True(t, ok)
+	assert.Equal(t, "prod", r.Name)
+	// Requested once only until the next refresh
+	_, ok = m.DetailNeeded()
+	assert.False(t, ok)
+	assert.Regexp(t, `Details\s+loading\.\.\.`, view())
+
+	m.SetDetail(r.Key(), display.Detail{}, errors.New("AccessDenied"))
+	assert.Regexp(t, `Details\s+failed to describe: AccessDenied`, view())
+
+	// The description replaces the listed fields
+	detail := display.Detail{
+		ResourceType: "Endpoint", Name: "prod", Region: "us-east-1", Status: "InService",
+		EndpointConfigName: "prod-config",
+		Variants:           []display.Variant{{Name: "primary", ModelName: "model-v1", InstanceType: "ml.m5.large", CurrentInstanceCount: 2, DesiredInstanceCount: 2, Weight: 1}},
+		Tags:               map[string]string{"b": "2", "a": "1"},
+		Warnings:           []string{"failed to describe model model-v1"},
+	}
+	m.SetDetail(r.Key(), detail, nil)
+	screen := view()
+	assert.Contains(t, screen, "Endpoint prod (us-east-1)")
+	assert.Regexp(t, `Endpoint config\s+prod-config`, screen)
+	assert.Regexp(t, `primary\s+model-v1`, screen)
+	assert.NotContains(t, screen, "loading...")
+	assert.NotContains(t, screen, "failed to describe model")
+
+	// J and K scroll the pane, which stops at the last line
+	for i := 0; i < 50; i++ {
+		m.Update("J")
+	}
+	screen = view()
+	assert.NotContains(t, screen, "Endpoint prod (us-east-1)")
+	assert.Contains(t, screen, "failed to describe model model-v1")
+	m.Update("K")
+	assert.Greater(t, m.detailOffset, 0)
+	// Selecting another resource scrolls back to the top
+	m.Update(KeyDown)
+	assert.Equal(t, 0, m.detailOffset)
+
+	// A refresh describes the selected resource again
+	m.Update(KeyUp)
+	m.SetResources(testResources(), nil, time.Now())
+	_, ok = m.DetailNeeded()
+	assert.True(t, ok)
+	assert.Contains(t, view(), "Endpoint prod (us-east-1)")
+}
+
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+func TestModelView(t *testing.T) {
+	m := newTestModel()
+	m.Update(KeyDown)
+	m.Update(KeyDown)
+
+	lines := m.View(100, 40)
+	assert.Len(t, lines, 40)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len([]rune(ansi.ReplaceAllString(line, ""))), 100, line)
+	}
+	screen := ansi.ReplaceAllString(strings.Join(lines, "\n"), "")
+
+	assert.Contains(t, screen, "4 resource(s)  $1.69/hour  $1233.70/month  updated ")
+	assert.Contains(t, screen, " All (4)   Endpoint (1)   Notebook (2)   Studio (1) ")
+	assert.Contains(t, screen, " All regions   us-east-1   eu-west-1 ")
+	assert.Regexp(t, `TYPE\s+NAME\s+STATUS\s+INSTANCE\s+COUNT\s+RUNNING\s+\$/HOUR\s+REGION`, screen)
+	assert.Regexp(t, `Endpoint\s+prod\s+InService\s+ml\.m5\.large\s+2\s+0\.23\s+us-east-1`, screen)
+	// The selected row is highlighted
+	assert.Contains(t, strings.Join(lines, "\n"), styleReverse+"Notebook  dev ")
+
+	assert.Contains(t, screen, "── Details ──")
+	assert.Regexp(t, `Volume\s+5 GB`, screen)
+	assert.Regexp(t, `Created\s+.*\(running 10h 0m\)`, screen)
+	assert.Regexp(t, `Cost\s+\$0\.05/hour, \$1\.20/day, \$36\.50/month, about \$0\.50 since created`, screen)
+	assert.Regexp(t, `Details\s+loading\.\.\.`, screen)
+	assert.Contains(t, screen, "? help  q quit")
+
+	m.Update("?")
+	m.Update("/")
+	m.Update("z")
+	screen = ansi.ReplaceAllString(strings.Join(m.View(100, 40), "\n"), "")
+	assert.Contains(t, screen, "Filter: z_")
+	assert.Contains(t, screen, "No resources match")
+	assert.Contains(t, screen, "s stop  x delete")
+
+	// Studio details and errors
+	m.Update(KeyEscape)
+	m.Update(KeyEnd)
+	m.SetResources(testResources(), []error{errors.New("eu-west-1: failed to list studio apps")}, time.Now())
+	screen = ansi.ReplaceAllString(strings.Join(m.View(100, 40), "\n"), "")
+	assert.Regexp(t, `Studio app\s+default \(New Studio \(JupyterLab\)\)`, screen)
+	assert.Regexp(t, `Space\s+alice-space`, screen)
+	assert.Regexp(t, `Domain\s+d-1`, screen)
+	assert.Contains(t, screen, "eu-west-1: failed to list studio apps")
+
+	// Scrolling keeps the cursor on a small screen
+	m.Update(KeyHome)
+	m.View(100, 22)
+	assert.Equal(t, 2, m.height)
+	m.Update(KeyEnd)
+	assert.Equal(t, 2, m.offset)
+	screen = ansi.ReplaceAllString(strings.Join(m.View(100, 22), "\n"), "")
+	assert.NotContains(t, screen, "prod ")
+}
+
+func TestFit(t *testing.T) {
+	assert.Equal(t, "abc  ", fit("abc", 5))
+	assert.Equal(t, "abcd…", fit("abcdefgh", 5))
+	assert.Equal(t, "", fit("abc", 0))
+	assert.Equal(t, styleBold+"ab"+styleReset, clip(styleBold+"abc"+styleReset, 2))
+}
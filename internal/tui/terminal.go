@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Terminal is a Screen on a terminal switched to raw mode and the alternate screen
+type Terminal struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+}
+
+// OpenTerminal takes over the terminal; call Close to restore it
+func OpenTerminal(in, out *os.File) (*Terminal, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, fmt.Errorf("stdin and stdout must be a terminal")
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch the terminal to raw mode: %w", err)
+	}
+	// Alternate screen, hidden cursor
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	return &Terminal{in: in, out: out, state: state}, nil
+}
+
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+// Size returns the terminal size, or 80x24 when it is unknown
+func (t *Terminal) Size() (int, int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Keys starts reading key presses from the terminal
+func (t *Terminal) Keys() <-chan Key {
+	keys := make(chan Key, 16)
+	go ReadKeys(t.in, keys)
+	return keys
+}
+
+// Close restores the screen and the terminal mode
+func (t *Terminal) Close() error {
+	fmt.Fprint(t.out, "\x1b[0m\x1b[?25h\x1b[?1049l")
+	return term.Restore(int(t.in.Fd()), t.state)
+}
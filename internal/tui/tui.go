@@ -0,0 +1,167 @@
+// Package tui implements the interactive full-screen view of mohua tui: a periodically
+// refreshed list of resources with type and region tabs, filtering, a detail pane, and
+// confirmed stop and delete actions.
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mohua/internal/display"
+)
+
+// Backend lists and acts on the resources shown by the UI
+type Backend interface {
+	// List lists the resources of every region; errors of single regions or resource types
+	// are shown alongside the resources that could be listed
+	List(ctx context.Context) ([]Resource, []error)
+	// Describe returns everything known about a resource, including its tags and cost
+	Describe(ctx context.Context, r Resource) (display.Detail, error)
+	Run(ctx context.Context, action Action) error
+}
+
+// Screen is the terminal the UI draws on
+type Screen interface {
+	io.Writer
+	Size() (width, height int)
+}
+
+// Options configures Run
+type Options struct {
+	// Regions are shown as tabs before any resource is listed
+	Regions []string
+	// Refresh is how often the resources are listed again; 0 disables refreshing
+	Refresh time.Duration
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+}
+
+// resizePoll is how often the screen size is checked, since not every platform signals changes
+const resizePoll = time.Second
+
+// Run shows the UI until q or Ctrl-C is pressed, keys ends or ctx is done
+func Run(ctx context.Context, backend Backend, screen Screen, keys <-chan Key, options Options) error {
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m := NewModel(options.Regions)
+	// Backend calls run in the background and report back through updates, so that the
+	// model is only changed by this goroutine
+	updates := make(chan func(), 16)
+	post := func(update func()) {
+		select {
+		case updates <- update:
+		case <-ctx.Done():
+		}
+	}
+
+	loading := false
+	refresh := func() {
+		if loading {
+			return
+		}
+		loading = true
+		m.SetLoading()
+		go func() {
+			resources, errs := backend.List(ctx)
+			post(func() {
+				loading = false
+				m.SetResources(resources, errs, now())
+			})
+		}()
+	}
+	run := func(action Action) {
+		m.SetStatus(fmt.Sprintf("%s %s...", progressVerb(action.Kind), action.Resource.Name))
+		go func() {
+			err := backend.Run(ctx, action)
+			post(func() {
+				if err != nil {
+					m.SetStatus(fmt.Sprintf("Failed to %s %s: %v", action.Kind, action.Resource.Name, err))
+					return
+				}
+				m.SetStatus(fmt.Sprintf("Requested %s of %s; it may take a few minutes", action.Kind, action.Resource.Name))
+				refresh()
+			})
+		}()
+	}
+	describe := func() {
+		r, ok := m.DetailNeeded()
+		if !ok {
+			return
+		}
+		go func() {
+			detail, err := backend.Describe(ctx, r)
+			post(func() { m.SetDetail(r.Key(), detail, err) })
+		}()
+	}
+
+	var refreshTicks <-chan time.Time
+	if options.Refresh > 0 {
+		ticker := time.NewTicker(options.Refresh)
+		defer ticker.Stop()
+		refreshTicks = ticker.C
+	}
+	resizeTicker := time.NewTicker(resizePoll)
+	defer resizeTicker.Stop()
+
+	refresh()
+	var last string
+	for {
+		describe()
+		width, height := screen.Size()
+		if frame := render(m, width, height); frame != last {
+			if _, err := io.WriteString(screen, frame); err != nil {
+				return err
+			}
+			last = frame
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			effect := m.Update(key)
+			switch {
+			case effect.Quit:
+				return nil
+			case effect.Refresh:
+				refresh()
+			case effect.Run != nil:
+				run(*effect.Run)
+			}
+		case update := <-updates:
+			update()
+		case <-refreshTicks:
+			refresh()
+		case <-resizeTicker.C:
+		}
+	}
+}
+
+// render draws the whole screen from the top left corner, clearing what is left of the
+// previous frame
+func render(m *Model, width, height int) string {
+	var b bytes.Buffer
+	b.WriteString("\x1b[H")
+	b.WriteString(strings.Join(m.View(width, height), "\x1b[K\r\n"))
+	b.WriteString("\x1b[K\x1b[J")
+	return b.String()
+}
+
+func progressVerb(kind ActionKind) string {
+	if kind == ActionStop {
+		return "Stopping"
+	}
+	return "Deleting"
+}
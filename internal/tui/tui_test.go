@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mohua/internal/display"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend serves testResources and records the actions it runs
+type fakeBackend struct {
+	mu      sync.Mutex
+	lists   int
+	actions []Action
+	runErr  error
+}
+
+func (b *fakeBackend) List(ctx context.Context) ([]Resource, []error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lists++
+	return testResources(), nil
+}
+
+func (b *fakeBackend) Describe(ctx context.Context, r Resource) (display.Detail, error) {
+	return display.Detail{ResourceType: r.ResourceType, Name: r.Name, Region: r.Region, Status: r.Status, Tags: map[string]string{"owner": "alice"}}, nil
+}
+
+func (b *fakeBackend) Run(ctx context.Context, action Action) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.actions = append(b.actions, action)
+	return b.runErr
+}
+
+func (b *fakeBackend) counts() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lists, len(b.actions)
+}
+
+// fakeScreen is a fixed-size screen keeping everything written to it
+type fakeScreen struct {
+	mu  sync.Mutex
+	out bytes.Buffer
+}
+
+func (s *fakeScreen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Write(p)
+}
+
+func (s *fakeScreen) Size() (int, int) {
+	return 100, 40
+}
+
+func (s *fakeScreen) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ansi.ReplaceAllString(s.out.String(), "")
+}
+
+func startRun(t *testing.T, backend Backend, screen Screen, options Options) (chan Key, chan error) {
+	keys := make(chan Key)
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(context.Background(), backend, screen, keys, options)
+	}()
+	t.Cleanup(func() { close(keys) })
+	return keys, done
+}
+
+func TestRun(t *testing.T) {
+	backend := &fakeBackend{}
+	screen := &fakeScreen{}
+	keys, done := startRun(t, backend, screen, Options{Regions: []string{"us-east-1"}})
+
+	assert.Eventually(t, func() bool {
+		return regexp.MustCompile(`owner\s+alice`).MatchString(screen.String())
+	}, time.Second, 10*time.Millisecond)
+
+	// Stop the selected notebook, which lists the resources again
+	keys <- KeyDown
+	keys <- KeyDown
+	keys <- "s"
+	keys <- "y"
+	assert.Eventually(t, func() bool {
+		lists, actions := backend.counts()
+		return lists == 2 && actions == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ActionStop, backend.actions[0].Kind)
+	assert.Equal(t, "dev", backend.actions[0].Resource.Name)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(screen.String(), "Requested stop of dev; it may take a few minutes")
+	}, time.Second, 10*time.Millisecond)
+
+	keys <- "q"
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after q")
+	}
+}
+
+func TestRun_FailedAction(t *testing.T) {
+	backend := &fakeBackend{runErr: errors.New("ValidationException: endpoint is updating")}
+	screen := &fakeScreen{}
+	keys, _ := startRun(t, backend, screen, Options{})
+
+	assert.Eventually(t, func() bool {
+		lists, _ := backend.counts()
+		return lists == 1
+	}, time.Second, 10*time.Millisecond)
+	keys <- "x"
+	keys <- "y"
+	assert.Eventually(t, func() bool {
+		return strings.Contains(screen.String(), "Failed to delete prod: ValidationException: endpoint is updating")
+	}, time.Second, 10*time.Millisecond)
+	lists, _ := backend.counts()
+	assert.Equal(t, 1, lists)
+}
+
+func TestRun_Refresh(t *testing.T) {
+	backend := &fakeBackend{}
+	_, _ = startRun(t, backend, &fakeScreen{}, Options{Refresh: 10 * time.Millisecond})
+
+	assert.Eventually(t, func() bool {
+		lists, _ := backend.counts()
+		return lists >= 3
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,335 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mohua/internal/display"
+	"mohua/internal/pricing"
+)
+
+// ANSI styles; the UI only runs on terminals, so colors are not configurable
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleYellow  = "\x1b[33m"
+)
+
+// detailHeight is the number of lines of the detail pane, including its title
+const detailHeight = 14
+
+// listColumn is a column of the resource list; the name column takes the remaining width
+type listColumn struct {
+	header string
+	width  int
+	value  func(r Resource) string
+}
+
+var listColumns = []listColumn{
+	{header: "TYPE", width: 9, value: func(r Resource) string { return r.ResourceType }},
+	{header: "NAME"},
+	{header: "STATUS", width: 11, value: func(r Resource) string { return r.Status }},
+	{header: "INSTANCE", width: 17, value: func(r Resource) string { return r.InstanceType }},
+	{header: "COUNT", width: 6, value: func(r Resource) string { return strconv.Itoa(r.InstanceCount) }},
+	{header: "RUNNING", width: 14, value: func(r Resource) string { return r.RunningTime }},
+	{header: "$/HOUR", width: 9, value: func(r Resource) string { return fmt.Sprintf("%.2f", r.HourlyCost) }},
+	{header: "REGION", width: 15, value: func(r Resource) string { return r.Region }},
+}
+
+// helpText lists the key bindings
+const helpText = "↑/↓ select  ←/→ type  [/] region  / filter  J/K scroll details  s stop  x delete  r refresh  ? help  q quit"
+
+// View renders the screen as lines of at most width columns, height lines in total
+func (m *Model) View(width, height int) []string {
+	var lines []string
+	lines = append(lines, m.titleLine())
+	lines = append(lines, m.typeTabsLine())
+	if len(m.regions) > 1 {
+		lines = append(lines, m.regionTabsLine())
+	}
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "_"
+		}
+		lines = append(lines, "Filter: "+m.filter+cursor)
+	}
+
+	// The list gets what the detail pane, errors and status line leave
+	footer := m.footerLines(width)
+	listHeight := height - len(lines) - 1 - detailHeight - len(footer)
+	m.setHeight(listHeight)
+
+	nameWidth := width
+	for _, c := range listColumns {
+		nameWidth -= c.width + 1
+	}
+	if nameWidth < 12 {
+		nameWidth = 12
+	}
+	lines = append(lines, styleBold+fit(m.row(nil, nameWidth), width)+styleReset)
+
+	visible := m.Visible()
+	for i := m.offset; i < m.offset+m.height; i++ {
+		switch {
+		case i < len(visible) && i == m.cursor:
+			lines = append(lines, styleReverse+fit(m.row(&visible[i], nameWidth), width)+styleReset)
+		case i < len(visible):
+			lines = append(lines, fit(m.row(&visible[i], nameWidth), width))
+		case i == 0 && !m.loading:
+			lines = append(lines, styleDim+"No resources match"+styleReset)
+		default:
+			lines = append(lines, "")
+		}
+	}
+
+	lines = append(lines, m.detailLines(width)...)
+	lines = append(lines, footer...)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i := range lines {
+		lines[i] = clip(lines[i], width)
+	}
+	return lines
+}
+
+// titleLine shows the totals of the visible resources and when they were listed
+func (m *Model) titleLine() string {
+	var hourly float64
+	visible := m.Visible()
+	for _, r := range visible {
+		hourly += r.HourlyCost
+	}
+	updated := "loading..."
+	if !m.loadedAt.IsZero() {
+		updated = "updated " + m.loadedAt.Local().Format("15:04:05")
+		if m.loading {
+			updated += ", refreshing..."
+		}
+	}
+	return fmt.Sprintf("%smohua%s  %d resource(s)  $%.2f/hour  $%.2f/month  %s",
+		styleBold, styleReset, len(visible), hourly, hourly*pricing.HoursPerMonth, updated)
+}
+
+func (m *Model) typeTabsLine() string {
+	counts := make(map[string]int)
+	for _, r := range m.resources {
+		if region := m.region(); region == "" || r.Region == region {
+			counts[r.ResourceType]++
+			counts[""]++
+		}
+	}
+	var tabs []string
+	for i, t := range typeTabs {
+		label := t
+		if label == "" {
+			label = "All"
+		}
+		tabs = append(tabs, tab(fmt.Sprintf("%s (%d)", label, counts[t]), i == m.typeTab))
+	}
+	return strings.Join(tabs, " ")
+}
+
+func (m *Model) regionTabsLine() string {
+	tabs := []string{tab("All regions", m.regionTab == 0)}
+	for i, region := range m.regions {
+		tabs = append(tabs, tab(region, m.regionTab == i+1))
+	}
+	return strings.Join(tabs, " ")
+}
+
+func tab(label string, active bool) string {
+	if active {
+		return styleReverse + " " + label + " " + styleReset
+	}
+	return " " + label + " "
+}
+
+// row renders a resource, or the header when r is nil
+func (m *Model) row(r *Resource, nameWidth int) string {
+	var cells []string
+	for _, c := range listColumns {
+		width := c.width
+		if width == 0 {
+			width = nameWidth
+		}
+		text := c.header
+		if r != nil {
+			text = r.Name
+			if c.value != nil {
+				text = c.value(*r)
+			}
+		}
+		cells = append(cells, fit(text, width))
+	}
+	return strings.Join(cells, " ")
+}
+
+// detailLines renders the detail pane of the selected resource: its description like mohua
+// describe prints it once fetched, and the listed fields until then
+func (m *Model) detailLines(width int) []string {
+	title := "── Details "
+	lines := []string{styleBold + title + strings.Repeat("─", max(width-utf8.RuneCountInString(title), 0)) + styleReset}
+	r, ok := m.Selected()
+	if !ok {
+		return padLines(lines, detailHeight)
+	}
+
+	body := m.detailBody(r)
+	m.detailOffset = clamp(m.detailOffset, 0, len(body)-(detailHeight-1))
+	for _, line := range body[m.detailOffset:] {
+		lines = append(lines, fit(line, width))
+	}
+	if len(lines) > detailHeight {
+		lines = lines[:detailHeight]
+	}
+	return padLines(lines, detailHeight)
+}
+
+// detailBody returns the lines of the detail pane, before scrolling
+func (m *Model) detailBody(r Resource) []string {
+	key := r.Key()
+	if detail, ok := m.details[key]; ok {
+		var buf bytes.Buffer
+		printer := display.NewPrinter(false)
+		printer.SetOutput(&buf)
+		printer.SetColorMode(display.ColorNever)
+		printer.PrintDetail(detail)
+		// Blank lines between sections would waste the small pane
+		var lines []string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+
+	var lines []string
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%-14s %s", label, value))
+		}
+	}
+	field("Name", r.Name)
+	field("Type", r.ResourceType)
+	field("Status", r.Status)
+	field("Region", r.Region)
+	field("ARN", r.Arn)
+	field("Instance", fmt.Sprintf("%s x %d", r.InstanceType, r.InstanceCount))
+	if r.Source.VolumeSize > 0 {
+		field("Volume", fmt.Sprintf("%d GB", r.Source.VolumeSize))
+	}
+	if !r.CreationTime.IsZero() {
+		field("Created", fmt.Sprintf("%s (running %s)", r.CreationTime.Local().Format("2006-01-02 15:04:05"), display.FormatDuration(time.Duration(r.RunningSeconds)*time.Second)))
+	}
+	if r.ResourceType == "Studio" {
+		field("Studio app", strings.TrimSpace(fmt.Sprintf("%s %s", r.Source.Name, parenthesize(r.Source.StudioType))))
+		field("User profile", r.UserProfile)
+		field("Space", r.Source.SpaceName)
+		field("Domain", r.Source.DomainID)
+	}
+	field("Cost", costText(r))
+	if err, ok := m.detailErrors[key]; ok {
+		field("Details", "failed to describe: "+err)
+	} else {
+		field("Details", "loading...")
+	}
+	return lines
+}
+
+// costText projects the hourly cost and estimates what the resource cost since it was created
+func costText(r Resource) string {
+	if !pricing.IsBilled(r.Status) {
+		return "not billed while " + r.Status
+	}
+	if r.HourlyCost == 0 {
+		return fmt.Sprintf("no price known for %s", r.InstanceType)
+	}
+	text := fmt.Sprintf("$%.2f/hour, $%.2f/day, $%.2f/month", r.HourlyCost, r.HourlyCost*24, r.HourlyCost*pricing.HoursPerMonth)
+	if r.RunningSeconds > 0 {
+		text += fmt.Sprintf(", about $%.2f since created", r.HourlyCost*float64(r.RunningSeconds)/3600)
+	}
+	return text
+}
+
+// footerLines renders the listing errors and the status line
+func (m *Model) footerLines(width int) []string {
+	var lines []string
+	for _, err := range m.errors {
+		lines = append(lines, styleRed+fit(err, width)+styleReset)
+	}
+	switch {
+	case m.pending != nil:
+		lines = append(lines, styleYellow+styleBold+fit(m.pending.prompt(), width)+styleReset)
+	case m.status != "":
+		lines = append(lines, styleYellow+fit(m.status, width)+styleReset)
+	case m.showHelp:
+		lines = append(lines, fit(helpText, width))
+	default:
+		lines = append(lines, styleDim+fit("? help  q quit", width)+styleReset)
+	}
+	return lines
+}
+
+func parenthesize(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "(" + s + ")"
+}
+
+func padLines(lines []string, n int) []string {
+	for len(lines) < n {
+		lines = append(lines, "")
+	}
+	return lines[:n]
+}
+
+// fit truncates or pads s to exactly width columns, assuming one column per rune
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		if width == 1 {
+			return string(runes[:1])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// clip truncates a line with ANSI styles to width columns, not counting the escape sequences
+func clip(s string, width int) string {
+	var b strings.Builder
+	columns := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			end := strings.IndexByte(s[i:], 'm')
+			if end < 0 {
+				break
+			}
+			b.WriteString(s[i : i+end+1])
+			i += end + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if columns < width {
+			b.WriteRune(r)
+			columns++
+		}
+		i += size
+	}
+	return b.String()
+}